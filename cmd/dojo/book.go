@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/juan-carvajal/go-dojo/internal/book"
+)
+
+var bookFlags struct {
+	out string
+}
+
+func bookFlagSet(fs *flag.FlagSet) {
+	fs.StringVar(&bookFlags.out, "o", "book", "directory to write the site and book.md into")
+}
+
+func runBook(_ context.Context, e *env, _ []string) error {
+	readme, err := book.ParseREADME(filepath.Join(e.root, "README.md"))
+	if err != nil {
+		return err
+	}
+	b, err := book.New(e.index, readme)
+	if err != nil {
+		return err
+	}
+	if err := b.WriteHTML(bookFlags.out); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(bookFlags.out, "book.md"))
+	if err != nil {
+		return err
+	}
+	if err := b.WriteMarkdown(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	lessons := 0
+	for _, c := range b.Chapters {
+		lessons += len(c.Lessons)
+	}
+	fmt.Fprintf(e.stdout, "Wrote %d chapters and %d lessons to %s (index.html, book.md)\n", len(b.Chapters), lessons, bookFlags.out)
+	return nil
+}
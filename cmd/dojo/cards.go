@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/juan-carvajal/go-dojo/internal/flashcard"
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+var cardsFlags struct {
+	out string
+}
+
+func cardsFlagSet(fs *flag.FlagSet) {
+	fs.StringVar(&cardsFlags.out, "o", ".", "directory to write go-dojo.tsv and go-dojo.csv into")
+}
+
+func runCards(_ context.Context, e *env, args []string) error {
+	var lessons []*lesson.Lesson
+	for _, l := range e.index.Lessons {
+		if len(args) == 0 || l.Short() == args[0] || l.Package == args[0] {
+			lessons = append(lessons, l)
+		}
+	}
+	cards, err := flashcard.Cards(lessons)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		if len(args) == 0 {
+			return errors.New("no Example with an output")
+		}
+		return fmt.Errorf("no Example with an output in %q", args[0])
+	}
+	if err := os.MkdirAll(cardsFlags.out, 0o755); err != nil {
+		return err
+	}
+	for _, w := range []struct {
+		name  string
+		write func(*os.File) error
+	}{
+		{"go-dojo.tsv", func(f *os.File) error { return flashcard.WriteTSV(f, cards) }},
+		{"go-dojo.csv", func(f *os.File) error { return flashcard.WriteCSV(f, cards) }},
+	} {
+		path := filepath.Join(cardsFlags.out, w.name)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := w.write(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Wrote %d cards to %s\n", len(cards), path)
+	}
+	return nil
+}
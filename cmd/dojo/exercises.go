@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+func runExercises(_ context.Context, e *env, args []string) error {
+	tracks, err := kata.DiscoverTracks(e.root)
+	if err != nil {
+		return err
+	}
+	printed := false
+	for _, t := range tracks {
+		if len(args) > 0 && args[0] != t.Name {
+			continue
+		}
+		if printed {
+			fmt.Fprintln(e.stdout)
+		}
+		printed = true
+		fmt.Fprintln(e.stdout, bold(t.Name), "("+t.Package+")")
+		for _, ex := range t.Exercises {
+			summary := strings.TrimPrefix(lesson.FirstSentence(ex.Doc), ex.Name+" ")
+			fmt.Fprintf(e.stdout, "  %-16s %s\n", ex.Name, truncate(summary, e.width-20))
+		}
+	}
+	if !printed {
+		return fmt.Errorf("%w for %s", kata.ErrNoTrack, args[0])
+	}
+	return nil
+}
+
+var checkFlags struct {
+	race     bool
+	solution bool
+}
+
+func checkFlagSet(fs *flag.FlagSet) {
+	fs.BoolVar(&checkFlags.race, "race", true, "run the grading tests with the race detector")
+	fs.BoolVar(&checkFlags.solution, "solution", false, "grade the reference solutions instead of your code")
+}
+
+func runCheck(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one track, see dojo exercises")
+	}
+	t, err := kata.FindTrack(e.root, args[0])
+	if err != nil {
+		return err
+	}
+	g, err := t.Check(ctx, e.root, kata.CheckOptions{Race: checkFlags.race, Solution: checkFlags.solution})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, ex := range t.Exercises {
+		status := g.Exercise(ex)
+		label := map[kata.Status]string{kata.Passed: green("PASS"), kata.Failed: red("FAIL"), kata.Todo: "TODO"}[status]
+		fmt.Fprintf(e.stdout, "%s  %s\n", label, ex.Name)
+		if status != kata.Todo && !checkFlags.solution {
+			e.record(progress.Event{
+				Kind:    progress.Exercise,
+				Lesson:  progress.ExerciseID(t, ex),
+				Chapter: t.Name,
+				Passed:  status == kata.Passed,
+			})
+		}
+		if status == kata.Failed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintln(e.stdout)
+		fmt.Fprint(e.stdout, g.Log)
+		return fmt.Errorf("%d of %d exercises failed", failed, len(t.Exercises))
+	}
+	return nil
+}
+
+func runSolution(_ context.Context, e *env, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("expected a track and optionally an exercise")
+	}
+	t, err := kata.FindTrack(e.root, args[0])
+	if err != nil {
+		return err
+	}
+	exercises := t.Exercises
+	if len(args) == 2 {
+		ex, err := t.Lookup(args[1])
+		if err != nil {
+			return err
+		}
+		exercises = []*kata.Exercise{ex}
+	}
+	for i, ex := range exercises {
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		fmt.Fprintln(e.stdout, strings.ReplaceAll(ex.Solution, "\t", "    "))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/layout"
+)
+
+var layoutFlags struct {
+	arches  string
+	rewrite bool
+}
+
+func layoutFlagSet(fs *flag.FlagSet) {
+	fs.StringVar(&layoutFlags.arches, "arch", strings.Join(layout.Arches, ","), "comma separated GOARCH values to lay the struct out for")
+	fs.BoolVar(&layoutFlags.rewrite, "rewrite", false, "print the declaration of the struct with its fields reordered")
+}
+
+// packageDir resolves a directory, relative to the working directory or to the root, or an import path.
+func (e *env) packageDir(ctx context.Context, pkg string) (string, error) {
+	for _, dir := range []string{pkg, filepath.Join(e.root, pkg)} {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+	}
+	cmd := exec.CommandContext(ctx, "go", "list", "-f", "{{.Dir}}", pkg)
+	cmd.Dir = e.root
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("package %s not found", pkg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func runLayout(ctx context.Context, e *env, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a package and a type, e.g. go-features/structs Example_structMemoryAlignment.t1")
+	}
+	dir, err := e.packageDir(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Architectures sharing a layout are shown together.
+	type group struct {
+		arches    []string
+		word      int64
+		s, opt    layout.Struct
+		signature string
+	}
+	var groups []*group
+	for _, arch := range strings.Split(layoutFlags.arches, ",") {
+		sizes, err := layout.Sizes(arch)
+		if err != nil {
+			return err
+		}
+		structs, err := layout.Package(dir, arch)
+		if err != nil {
+			return err
+		}
+		s, ok := layout.Find(structs, args[1])
+		if !ok {
+			var names []string
+			for _, s := range structs {
+				names = append(names, s.Name)
+			}
+			return fmt.Errorf("no struct %s in %s, found: %s", args[1], args[0], strings.Join(names, ", "))
+		}
+		word := sizes.Sizeof(types.Typ[types.Uintptr])
+		bytes, legend := layout.Map(s, word)
+		g := &group{arches: []string{arch}, word: word, s: s, opt: layout.Optimize(s, sizes), signature: bytes + legend}
+		merged := false
+		for _, other := range groups {
+			if other.signature == g.signature {
+				other.arches = append(other.arches, arch)
+				merged = true
+			}
+		}
+		if !merged {
+			groups = append(groups, g)
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "%s %s\n", bold(args[1]), args[0])
+	for _, g := range groups {
+		bytes, legend := layout.Map(g.s, g.word)
+		fmt.Fprintf(e.stdout, "\n%s: size %d, align %d, padding %d (%s)\n\n%s\n%s",
+			bold(strings.Join(g.arches, ", ")), g.s.Size, g.s.Align, g.s.Padding(), g.s.Sum(), bytes, legend)
+	}
+
+	// Architectures sharing the best order get one suggestion: alignments differ, so the order may too.
+	type suggestion struct {
+		order string
+		opt   layout.Struct
+		saved []string
+	}
+	var suggestions []*suggestion
+	for _, g := range groups {
+		if g.opt.Size >= g.s.Size {
+			continue
+		}
+		names := make([]string, len(g.opt.Fields))
+		for i, f := range g.opt.Fields {
+			names[i] = f.Name
+		}
+		order := strings.Join(names, ", ")
+		i := slices.IndexFunc(suggestions, func(s *suggestion) bool { return s.order == order })
+		if i < 0 {
+			suggestions = append(suggestions, &suggestion{order: order, opt: g.opt})
+			i = len(suggestions) - 1
+		}
+		suggestions[i].saved = append(suggestions[i].saved,
+			fmt.Sprintf("%d instead of %d bytes on %s", g.opt.Size, g.s.Size, strings.Join(g.arches, ", ")))
+	}
+	fmt.Fprintln(e.stdout)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(e.stdout, green("The field order is already optimal."))
+		return nil
+	}
+	for _, s := range suggestions {
+		fmt.Fprintf(e.stdout, "%s %s: %s\n", bold("Suggested order"), s.order, strings.Join(s.saved, "; "))
+		if layoutFlags.rewrite {
+			fmt.Fprintf(e.stdout, "\n%s\n", layout.Declaration(s.opt))
+		}
+	}
+	return nil
+}
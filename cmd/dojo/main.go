@@ -0,0 +1,305 @@
+// Command dojo lists, shows and runs the lessons of the dojo from the terminal.
+//
+// Usage:
+//
+//	dojo list [package]
+//	dojo show <lesson>
+//	dojo run <lesson>
+//	dojo quiz <lesson>
+//	dojo exercises [track]
+//	dojo check <track>
+//	dojo solution <track> [exercise]
+//	dojo progress
+//	dojo next
+//	dojo path
+//	dojo book [-o dir]
+//	dojo cards [-o dir] [package]
+//	dojo review [-n max] [-new count]
+//	dojo layout [-arch list] [-rewrite] <package> <type>
+//
+// Lesson runs, quiz answers and exercise grades are recorded per learner in $XDG_STATE_HOME/go-dojo/progress.jsonl.
+//
+// Lessons are the `Example*` and `Test_*` functions of the packages under `go-features` and `protocols`. They can be
+// referred to by their function name (`Example_panicChain2`) or, when the name is ambiguous, by their ID
+// (`panic.Example_panicChain2`).
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+// env is the state shared by every command.
+type env struct {
+	root    string
+	index   *lesson.Index
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	width   int
+	learner string
+	store   *progress.Store
+
+	input *bufio.Scanner // lines of stdin, shared by the prompts of a session
+	eof   bool           // stdin is exhausted
+}
+
+type command struct {
+	name  string
+	args  string
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+	flags func(fs *flag.FlagSet) // optional command specific flags
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "list", args: "[package]", help: "list the lessons, optionally only those of a package", run: runList, flags: listFlagSet},
+		{name: "show", args: "<lesson>", help: "show the doc, source and expected output of a lesson", run: runShow},
+		{name: "run", args: "<lesson>", help: "run a lesson and show its actual output next to its source", run: runRun},
+		{name: "quiz", args: "<lesson>", help: "predict the output of an Example before seeing it", run: runQuiz},
+		{name: "exercises", args: "[track]", help: "list the exercises to implement", run: runExercises},
+		{name: "check", args: "<track>", help: "grade your implementation of a track", run: runCheck, flags: checkFlagSet},
+		{name: "solution", args: "<track> [exercise]", help: "reveal the reference solutions of a track", run: runSolution},
+		{name: "progress", help: "show your completion of every package", run: runProgress},
+		{name: "next", help: "recommend the next lesson or exercise to work on", run: runNext},
+		{name: "path", help: "show the learning path, lessons ordered by prerequisites and difficulty", run: runPath},
+		{name: "book", help: "generate a static HTML site and a Markdown book of every lesson", run: runBook, flags: bookFlagSet},
+		{name: "cards", args: "[package]", help: "export the Examples as Anki flashcards (TSV and CSV)", run: runCards, flags: cardsFlagSet},
+		{name: "review", help: "quiz the Examples that are due for review, spaced repetition style", run: runReview, flags: reviewFlagSet},
+		{name: "layout", args: "<package> <type>", help: "show the memory layout of a struct per GOARCH and a smaller field order", run: runLayout, flags: layoutFlagSet},
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "dojo:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == args[0] {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		usage(stdout)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	root := fs.String("root", "", "module root of the dojo (defaults to the go.mod found from the working directory)")
+	width := fs.Int("width", terminalWidth(), "terminal width used for side by side rendering")
+	learner := fs.String("learner", defaultLearner(), "whose progress to record and show ($DOJO_LEARNER, then $USER)")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stdout, "usage: dojo %s [flags] %s\n\n%s\n\n", cmd.name, cmd.args, cmd.help)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	path, err := progress.DefaultPath()
+	if err != nil {
+		return err
+	}
+	e := &env{
+		root:    *root,
+		stdin:   stdin,
+		input:   bufio.NewScanner(stdin),
+		stdout:  stdout,
+		stderr:  stderr,
+		width:   *width,
+		learner: *learner,
+		store:   progress.Open(path),
+	}
+	if e.root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if e.root, err = lesson.FindRoot(wd); err != nil {
+			return err
+		}
+	}
+	idx, err := lesson.Discover(e.root)
+	if err != nil {
+		return err
+	}
+	e.index = idx
+	return cmd.run(ctx, e, fs.Args())
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "dojo is a runner for the lessons of go-dojo.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  dojo %-28s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+}
+
+// lookup resolves the single lesson argument of a command.
+func (e *env) lookup(args []string) (*lesson.Lesson, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected exactly one lesson")
+	}
+	return e.index.Lookup(args[0])
+}
+
+var listFlags struct {
+	tag string
+}
+
+func listFlagSet(fs *flag.FlagSet) {
+	fs.StringVar(&listFlags.tag, "tag", "", "only list the lessons with this tag: "+strings.Join(lesson.Tags, ", "))
+}
+
+func runList(_ context.Context, e *env, args []string) error {
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	var lessons []*lesson.Lesson
+	idWidth := 0
+	for _, l := range e.index.Lessons {
+		if listFlags.tag != "" && !l.Meta.HasTag(listFlags.tag) {
+			continue
+		}
+		if filter == "" || l.Short() == filter || l.Package == filter {
+			lessons = append(lessons, l)
+			idWidth = max(idWidth, len(l.ID()))
+		}
+	}
+	pkg := ""
+	for _, l := range lessons {
+		if l.Package != pkg {
+			if pkg != "" {
+				fmt.Fprintln(e.stdout)
+			}
+			pkg = l.Package
+			fmt.Fprintf(e.stdout, "%s\n", bold(pkg))
+		}
+		summary := strings.TrimPrefix(l.Summary(), l.Name+" ")
+		fmt.Fprintf(e.stdout, "  %-*s  %s\n", idWidth, l.ID(), truncate(summary, e.width-idWidth-4))
+	}
+	if pkg == "" {
+		return fmt.Errorf("no lessons in %q with tag %q", filter, listFlags.tag)
+	}
+	return nil
+}
+
+func runShow(_ context.Context, e *env, args []string) error {
+	l, err := e.lookup(args)
+	if err != nil {
+		return err
+	}
+	header(e, l, "")
+	right := "(no output comment, go test compiles but never runs this example)"
+	switch {
+	case l.Kind == lesson.Test:
+		right = "(test, run it to see its log)"
+	case l.HasOutput:
+		right = l.Output
+	}
+	sideBySide(e.stdout, e.width, "Source", l.Source, "Expected output", right)
+	return nil
+}
+
+func runRun(ctx context.Context, e *env, args []string) error {
+	l, err := e.lookup(args)
+	if err != nil {
+		return err
+	}
+	res, err := l.Run(ctx, e.root, lesson.RunOptions{})
+	if err != nil {
+		return err
+	}
+	e.record(progress.Event{Kind: progress.Run, Lesson: l.ID(), Chapter: l.Chapter(), Passed: res.Passed})
+	status := green("PASS")
+	if !res.Passed {
+		status = red("FAIL")
+	}
+	header(e, l, status)
+	sideBySide(e.stdout, e.width, "Source", l.Source, "Actual output", res.Output)
+	if !res.Passed {
+		if l.Kind == lesson.Example && l.HasOutput {
+			fmt.Fprintf(e.stdout, "\n%s\n%s\n", bold("Expected output"), l.Output)
+		}
+		return fmt.Errorf("%s failed", l.ID())
+	}
+	return nil
+}
+
+// header prints the lesson ID, its location and its doc comment.
+func header(e *env, l *lesson.Lesson, status string) {
+	title := bold(l.ID())
+	if status != "" {
+		title += " " + status
+	}
+	fmt.Fprintln(e.stdout, title)
+	file, err := filepath.Rel(e.root, l.File)
+	if err != nil {
+		file = l.File
+	}
+	fmt.Fprintf(e.stdout, "%s:%d (%s)\n", filepath.ToSlash(file), l.Line, l.Kind)
+	if m := metaLine(e, l); m != "" {
+		fmt.Fprintln(e.stdout, m)
+	}
+	fmt.Fprintln(e.stdout)
+	if l.Doc != "" {
+		fmt.Fprintln(e.stdout, strings.TrimRight(l.Doc, "\n"))
+		fmt.Fprintln(e.stdout)
+	}
+}
+
+// metaLine summarizes the metadata of a lesson: `intermediate · 5 min · pitfall, runtime · requires panic.Example_x`.
+func metaLine(e *env, l *lesson.Lesson) string {
+	var parts []string
+	if l.Meta.Difficulty != lesson.Unrated {
+		parts = append(parts, l.Meta.Difficulty.String())
+	}
+	if l.Meta.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", l.Meta.Minutes))
+	}
+	if len(l.Meta.Tags) > 0 {
+		parts = append(parts, strings.Join(l.Meta.Tags, ", "))
+	}
+	if reqs, err := e.index.Prerequisites(l); err == nil && len(reqs) > 0 {
+		ids := make([]string, len(reqs))
+		for i, r := range reqs {
+			ids[i] = r.ID()
+		}
+		parts = append(parts, "requires "+strings.Join(ids, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
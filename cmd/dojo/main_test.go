@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+func runDojo(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var out bytes.Buffer
+	err := run(context.Background(), args, strings.NewReader(""), &out, io.Discard)
+	return out.String(), err
+}
+
+func Test_list(t *testing.T) {
+	out, err := runDojo(t, "list", "consts")
+	require.NoError(t, err)
+	require.Contains(t, out, "go-features/consts\n")
+	require.Contains(t, out, "consts.Example_iotaSkip")
+	require.NotContains(t, out, "panic.")
+
+	_, err = runDojo(t, "list", "nope")
+	require.Error(t, err)
+}
+
+func Test_show(t *testing.T) {
+	out, err := runDojo(t, "show", "-width", "100", "Example_iotaSkip")
+	require.NoError(t, err)
+	require.Contains(t, out, "go-features/consts/iota_test.go:")
+	require.Contains(t, out, "Expected output")
+	require.Contains(t, out, "func Example_iotaSkip() {")
+}
+
+func Test_unknownCommand(t *testing.T) {
+	_, err := runDojo(t, "nope")
+	require.EqualError(t, err, `unknown command "nope"`)
+}
+
+func Test_sideBySide(t *testing.T) {
+	var out bytes.Buffer
+	sideBySide(&out, 23, "L", "a\tb\nc", "R", "1")
+	require.Equal(t, ""+
+		"L            | R\n"+
+		"─            | ─\n"+
+		"a    b       | 1\n"+
+		"c            |\n", out.String())
+}
+
+func Test_cardsWithoutExamples(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module empty\n"), 0o644))
+	for _, dir := range []string{"go-features", "protocols"} {
+		require.NoError(t, os.Mkdir(filepath.Join(root, dir), 0o755))
+	}
+	_, err := runDojo(t, "cards", "-root", root, "-o", t.TempDir())
+	require.EqualError(t, err, "no Example with an output")
+}
+
+func Test_quiz(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"quiz", "Example_iotaSkip"}, strings.NewReader("1\n2\n4\n8\n.\n"), &out, io.Discard)
+	require.NoError(t, err)
+	require.NotContains(t, out.String(), "// Output:")
+	require.Contains(t, out.String(), "Diff (3/4 lines")
+	require.Contains(t, out.String(), "- 3\n+ 4\n")
+	require.Contains(t, out.String(), "c = 3 // c == 3  (iota == 2, unused)")
+}
+
+func Test_quizCutShort(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("DOJO_LEARNER", "ana")
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"quiz", "Example_iotaSkip"}, strings.NewReader("1\n2\n"), &out, io.Discard)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `No ".", the answer is left ungraded.`)
+	require.NotContains(t, out.String(), "Diff")
+
+	path, err := progress.DefaultPath()
+	require.NoError(t, err)
+	events, err := progress.Open(path).Events("ana")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func Test_progressAndNext(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("DOJO_LEARNER", "ana")
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"next"}, strings.NewReader(""), &out, io.Discard))
+	require.Contains(t, out.String(), "concurrency.Example_readingFromClosedChannel")
+
+	path, err := progress.DefaultPath()
+	require.NoError(t, err)
+	require.NoError(t, progress.Open(path).Record(progress.Event{
+		Learner: "ana",
+		Kind:    progress.Run,
+		Lesson:  "concurrency.Example_readingFromClosedChannel",
+		Chapter: "concurrency",
+		Passed:  true,
+	}))
+
+	out.Reset()
+	require.NoError(t, run(context.Background(), []string{"next"}, strings.NewReader(""), &out, io.Discard))
+	require.Contains(t, out.String(), "concurrency.Example_readingFromChannelInForLoop")
+
+	out.Reset()
+	require.NoError(t, run(context.Background(), []string{"progress"}, strings.NewReader(""), &out, io.Discard))
+	require.Contains(t, out.String(), "Progress of ana")
+	require.Regexp(t, `concurrency +\[--------\] 1/15 +0/3`, out.String())
+}
+
+func Test_suggest(t *testing.T) {
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+	idx, err := lesson.Discover(root)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	e := &env{root: root, index: idx, stdout: &out}
+	for _, c := range []struct {
+		item progress.Item
+		want string
+	}{
+		{progress.Item{ID: "concurrency/exercises.SendNTimes", Kind: progress.Exercise},
+			"Implement it in go-features/concurrency/exercises, then run: dojo check concurrency\n"},
+		{progress.Item{ID: "consts.Example_iotaSkip", Kind: progress.Run},
+			"Try: dojo quiz consts.Example_iotaSkip  or  dojo run consts.Example_iotaSkip\n"},
+		{progress.Item{ID: "concurrency.Test_leakTickerStop", Kind: progress.Run},
+			"Try: dojo run concurrency.Test_leakTickerStop\n"},
+	} {
+		out.Reset()
+		require.NoError(t, e.suggest(c.item))
+		require.Equal(t, "\n"+c.want, out.String())
+	}
+}
+
+func Test_listByTag(t *testing.T) {
+	out, err := runDojo(t, "list", "-tag", "memory")
+	require.NoError(t, err)
+	require.Contains(t, out, "structs.Example_structMemoryAlignment")
+	require.NotContains(t, out, "consts.")
+}
+
+func Test_review(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("DOJO_LEARNER", "ana")
+	path, err := progress.DefaultPath()
+	require.NoError(t, err)
+	require.NoError(t, progress.Open(path).Record(progress.Event{
+		Time:    time.Now().Add(-48 * time.Hour),
+		Learner: "ana",
+		Kind:    progress.Quiz,
+		Lesson:  "consts.Example_iotaSkip",
+		Chapter: "consts",
+		Score:   0.5,
+	}))
+
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"review"}, strings.NewReader("0\n.\n"), &out, io.Discard))
+	require.Contains(t, out.String(), "1 due, 0 new")
+	require.Contains(t, out.String(), "consts.Example_iotaSkip")
+	require.Contains(t, out.String(), "Next review in 1 day(s)")
+
+	out.Reset()
+	require.NoError(t, run(context.Background(), []string{"review"}, strings.NewReader(""), &out, io.Discard))
+	require.Contains(t, out.String(), "Nothing to review.\nNext review: consts.Example_iotaSkip on ")
+}
+
+func Test_layout(t *testing.T) {
+	out, err := runDojo(t, "layout", "-arch", "amd64,arm64", "-rewrite", "go-features/structs", "Example_structMemoryAlignment.t1")
+	require.NoError(t, err)
+	require.Contains(t, out, "amd64, arm64: size 24, align 8, padding 13 (1 + 7 + 8 + 2 + 6)")
+	require.Contains(t, out, "16  c  c  .  .  .  .  .  .\n")
+	require.Contains(t, out, "Suggested order b, c, a: 16 instead of 24 bytes on amd64, arm64")
+	require.Contains(t, out, "type t1 struct {\n\tb int64\n\tc int16\n\ta int8\n}")
+
+	_, err = runDojo(t, "layout", "go-features/structs", "nope")
+	require.ErrorContains(t, err, "no struct nope in go-features/structs")
+
+	// int64 is 4-byte aligned on 386, like int32: the best orders differ between 386 and amd64.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "t.go"), []byte("package p\n\n"+
+		"type T struct {\n\tc int8\n\ta int32\n\tb int64\n\td int8\n}\n"), 0o644))
+	out, err = runDojo(t, "layout", "-arch", "386,amd64", "-rewrite", dir, "T")
+	require.NoError(t, err)
+	require.Contains(t, out, "Suggested order a, b, c, d: 16 instead of 20 bytes on 386\n\n"+
+		"type T struct {\n\ta int32\n\tb int64\n\tc int8\n\td int8\n}\n")
+	require.Contains(t, out, "Suggested order b, a, c, d: 16 instead of 24 bytes on amd64\n")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+// defaultLearner is $DOJO_LEARNER, then $USER.
+func defaultLearner() string {
+	if l := os.Getenv("DOJO_LEARNER"); l != "" {
+		return l
+	}
+	if u := os.Getenv("USER"); u != "" {
+		return u
+	}
+	return "anonymous"
+}
+
+// record stores a progress event of the learner. Failing to do so is only worth a warning, the lesson itself worked.
+func (e *env) record(ev progress.Event) {
+	ev.Learner = e.learner
+	if err := e.store.Record(ev); err != nil {
+		fmt.Fprintln(e.stderr, "dojo: recording progress:", err)
+	}
+}
+
+// items lists the lessons and exercises of the dojo along with the events of the learner.
+func (e *env) items() ([]progress.Item, []progress.Event, error) {
+	tracks, err := kata.DiscoverTracks(e.root)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := e.store.Events(e.learner)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := progress.Items(e.index, tracks)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, events, nil
+}
+
+func runProgress(_ context.Context, e *env, _ []string) error {
+	items, events, err := e.items()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Progress of %s (%s)\n\n", bold(e.learner), e.store.Path)
+	fmt.Fprintf(e.stdout, "%-16s %-18s %-12s %s\n", "package", "lessons", "exercises", "last activity")
+	for _, c := range progress.Report(items, events) {
+		exercises := "-"
+		if c.Exercises > 0 {
+			exercises = fmt.Sprintf("%d/%d", c.ExercisesDone, c.Exercises)
+		}
+		last := "never"
+		if !c.LastActivity.IsZero() {
+			last = c.LastActivity.Local().Format("2006-01-02 15:04")
+		}
+		name := fmt.Sprintf("%-16s", c.Name)
+		if c.Done() {
+			name = green(name)
+		}
+		fmt.Fprintf(e.stdout, "%s %-18s %-12s %s\n", name, bar(c.LessonsDone, c.Lessons), exercises, last)
+	}
+	return nil
+}
+
+// bar renders a completion like `[####------] 4/10`.
+func bar(done, total int) string {
+	const width = 8
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat("-", width-filled), done, total)
+}
+
+func runNext(_ context.Context, e *env, _ []string) error {
+	items, events, err := e.items()
+	if err != nil {
+		return err
+	}
+	it, ok := progress.Next(items, events)
+	if !ok {
+		fmt.Fprintln(e.stdout, green("Everything is completed, well done!"))
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "%s %s\n", bold(it.ID), it.Summary)
+	return e.suggest(it)
+}
+
+// suggest prints the commands completing it.
+func (e *env) suggest(it progress.Item) error {
+	switch it.Kind {
+	case progress.Exercise:
+		name, _, _ := strings.Cut(it.ID, "/exercises.")
+		t, err := kata.FindTrack(e.root, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "\nImplement it in %s, then run: dojo check %s\n", t.Package, t.Name)
+	default:
+		l, err := e.index.Lookup(it.ID)
+		if err != nil {
+			return err
+		}
+		if l.Kind != lesson.Example || !l.HasOutput { // nothing to predict, see quiz.ErrNoOutput
+			fmt.Fprintf(e.stdout, "\nTry: dojo run %s\n", it.ID)
+			return nil
+		}
+		fmt.Fprintf(e.stdout, "\nTry: dojo quiz %s  or  dojo run %s\n", it.ID, it.ID)
+	}
+	return nil
+}
+
+func runPath(_ context.Context, e *env, _ []string) error {
+	path, err := e.index.Path()
+	if err != nil {
+		return err
+	}
+	events, err := e.store.Events(e.learner)
+	if err != nil {
+		return err
+	}
+	done := progress.Completed(events)
+	idWidth := 0
+	for _, l := range path {
+		idWidth = max(idWidth, len(l.ID()))
+	}
+	for i, l := range path {
+		mark := " "
+		if done[l.ID()] {
+			mark = green("✓")
+		}
+		fmt.Fprintf(e.stdout, "%3d %s %-*s  %-12s %3d min  %s\n",
+			i+1, mark, idWidth, l.ID(), l.Meta.Difficulty, l.Meta.Minutes, strings.Join(l.Meta.Tags, ","))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+	"github.com/juan-carvajal/go-dojo/internal/quiz"
+)
+
+// endOfAnswer terminates a prediction typed in the terminal: an answer cut short by EOF isn't graded.
+const endOfAnswer = "."
+
+func runQuiz(ctx context.Context, e *env, args []string) error {
+	l, err := e.lookup(args)
+	if err != nil {
+		return err
+	}
+	_, err = e.quiz(ctx, l)
+	return err
+}
+
+// quiz asks the learner to predict the output of l, runs it and grades the prediction. It returns a nil Answer, with
+// nothing run nor recorded, when the input ends before endOfAnswer.
+func (e *env) quiz(ctx context.Context, l *lesson.Lesson) (*quiz.Answer, error) {
+	q, err := quiz.New(l)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", l.ID(), err)
+	}
+	fmt.Fprintln(e.stdout, bold(l.ID()))
+	fmt.Fprintln(e.stdout)
+	for i, line := range strings.Split(q.Code, "\n") {
+		fmt.Fprintf(e.stdout, "%4d  %s\n", i+1, strings.ReplaceAll(line, "\t", "    "))
+	}
+	fmt.Fprintln(e.stdout)
+	fmt.Fprintf(e.stdout, "What does it print? One line per output line, finish with a single %q.\n", endOfAnswer)
+
+	var predicted []string
+	sc := e.input
+	for {
+		fmt.Fprint(e.stdout, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(e.stdout)
+			e.eof = true
+			if err := sc.Err(); err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(e.stdout, "No %q, the answer is left ungraded.\n", endOfAnswer)
+			return nil, nil
+		}
+		if sc.Text() == endOfAnswer {
+			break
+		}
+		predicted = append(predicted, sc.Text())
+	}
+
+	res, err := l.Run(ctx, e.root, lesson.RunOptions{})
+	if err != nil {
+		return nil, err
+	}
+	a := q.Grade(predicted, res.Output)
+	e.record(progress.Event{
+		Kind:    progress.Quiz,
+		Lesson:  l.ID(),
+		Chapter: l.Chapter(),
+		Passed:  a.Perfect(),
+		Score:   a.Score(),
+	})
+
+	fmt.Fprintln(e.stdout)
+	fmt.Fprintf(e.stdout, "%s (%d/%d lines, %s missed, %s not printed)\n",
+		bold("Diff"), a.Correct, a.Total, red("-"), green("+"))
+	for _, d := range a.Diff {
+		line := d.Op.String() + " " + d.Text
+		switch d.Op {
+		case quiz.Missing:
+			line = red(line)
+		case quiz.Extra:
+			line = green(line)
+		}
+		fmt.Fprintln(e.stdout, line)
+	}
+	if a.Perfect() {
+		fmt.Fprintln(e.stdout, green("Perfect!"))
+	}
+	if len(q.Hints) > 0 {
+		fmt.Fprintln(e.stdout)
+		fmt.Fprintln(e.stdout, bold("Explanations"))
+		for _, h := range q.Hints {
+			fmt.Fprintf(e.stdout, "%4d  %s %s\n", h.Line, h.Code, h.Comment)
+		}
+	}
+	if l.Doc != "" {
+		fmt.Fprintln(e.stdout)
+		fmt.Fprintln(e.stdout, strings.TrimRight(l.Doc, "\n"))
+	}
+	return a, nil
+}
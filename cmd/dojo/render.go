@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultWidth = 120
+
+// useColor is false when NO_COLOR is set or stdout is not a terminal.
+var useColor = func() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}()
+
+func ansi(code, s string) string {
+	if !useColor {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+func bold(s string) string  { return ansi("1", s) }
+func red(s string) string   { return ansi("31", s) }
+func green(s string) string { return ansi("32", s) }
+
+// terminalWidth reads $COLUMNS, which most shells export, falling back to defaultWidth.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 40 {
+		return n
+	}
+	return defaultWidth
+}
+
+// sideBySide renders two texts in columns separated by a vertical bar. Long lines are wrapped to their column.
+func sideBySide(w io.Writer, width int, leftTitle, left, rightTitle, right string) {
+	leftWidth := (width - 3) * 3 / 5
+	rightWidth := width - 3 - leftWidth
+	l := wrap(leftTitle, left, leftWidth)
+	r := wrap(rightTitle, right, rightWidth)
+	for i := range max(len(l), len(r)) {
+		var a, b string
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(r) {
+			b = r[i]
+		}
+		pad := strings.Repeat(" ", leftWidth-utf8.RuneCountInString(a))
+		if i < 2 {
+			a, b = bold(a), bold(b) // title and rule
+		}
+		fmt.Fprintln(w, strings.TrimRight(a+pad+" | "+b, " "))
+	}
+}
+
+// wrap expands tabs and splits text into lines of at most width runes, preceded by a title and a rule.
+func wrap(title, text string, width int) []string {
+	lines := []string{truncate(title, width), strings.Repeat("─", min(width, utf8.RuneCountInString(title)))}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		runes := []rune(line)
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// truncate shortens s to n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/quiz"
+	"github.com/juan-carvajal/go-dojo/internal/review"
+)
+
+var reviewFlags struct {
+	max   int
+	fresh int
+}
+
+func reviewFlagSet(fs *flag.FlagSet) {
+	fs.IntVar(&reviewFlags.max, "n", 20, "maximum number of due reviews in the session")
+	fs.IntVar(&reviewFlags.fresh, "new", 0, "number of never quizzed Examples to add, following the learning path")
+}
+
+func runReview(ctx context.Context, e *env, _ []string) error {
+	events, err := e.store.Events(e.learner)
+	if err != nil {
+		return err
+	}
+	items := review.Schedule(events)
+	now := time.Now()
+
+	var session []*lesson.Lesson
+	for _, it := range review.Due(items, now) {
+		if len(session) == reviewFlags.max {
+			break
+		}
+		l, err := e.index.Lookup(it.Lesson)
+		if errors.Is(err, lesson.ErrNotFound) {
+			continue // renamed or removed since
+		}
+		if err != nil {
+			return err
+		}
+		session = append(session, l)
+	}
+	due := len(session)
+	if reviewFlags.fresh > 0 {
+		quizzed := map[string]bool{}
+		for _, it := range items {
+			quizzed[it.Lesson] = true
+		}
+		path, err := e.index.Path()
+		if err != nil {
+			return err
+		}
+		for _, l := range path {
+			if len(session) == due+reviewFlags.fresh {
+				break
+			}
+			if _, err := quiz.New(l); err == nil && !quizzed[l.ID()] {
+				session = append(session, l)
+			}
+		}
+	}
+
+	if len(session) == 0 {
+		fmt.Fprintln(e.stdout, green("Nothing to review."))
+		if len(items) > 0 {
+			fmt.Fprintf(e.stdout, "Next review: %s on %s.\n", items[0].Lesson, items[0].Due.Local().Format("2006-01-02 15:04"))
+		} else {
+			fmt.Fprintln(e.stdout, "Take a quiz, or add new Examples with -new.")
+		}
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "%d due, %d new\n", due, len(session)-due)
+	for i, l := range session {
+		fmt.Fprintf(e.stdout, "\n%s\n", bold(fmt.Sprintf("[%d/%d]", i+1, len(session))))
+		a, err := e.quiz(ctx, l)
+		if err != nil {
+			return err
+		}
+		if a == nil {
+			fmt.Fprintf(e.stdout, "No more input, %d review(s) left for later.\n", len(session)-i)
+			break
+		}
+		it := itemOf(items, l.ID())
+		it.Review(review.Quality(a.Score()), now)
+		fmt.Fprintf(e.stdout, "\nNext review in %d day(s), %s.\n", it.Interval, it.Due.Local().Format("2006-01-02"))
+		if e.eof && i < len(session)-1 {
+			fmt.Fprintf(e.stdout, "No more input, %d review(s) left for later.\n", len(session)-i-1)
+			break
+		}
+	}
+	return nil
+}
+
+// itemOf returns the scheduled item of a lesson, or a new one.
+func itemOf(items []*review.Item, id string) *review.Item {
+	for _, it := range items {
+		if it.Lesson == id {
+			return it
+		}
+	}
+	return &review.Item{Lesson: id}
+}
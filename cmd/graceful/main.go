@@ -0,0 +1,107 @@
+// Command graceful is a small HTTP server that drains on SIGINT or SIGTERM, to try graceful shutdown by hand:
+//
+//	go build -o graceful ./cmd/graceful
+//	./graceful &
+//	curl 'localhost:8080/slow?d=5s' &
+//	kill %1
+//
+// The slow request completes, and a new one started meanwhile is refused. Requests running past the grace period
+// get their connection closed, and a second signal kills the server right away.
+//
+// Usage:
+//
+//	graceful [-addr host:port] [-grace duration]
+//
+// Routes:
+//
+//	/        answers "hello"
+//	/slow    answers "done" after the duration in parameter d, 1s by default
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/protocols/graceful"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, stop, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "graceful:", err)
+		os.Exit(1)
+	}
+}
+
+// run serves until ctx is done. It calls stop then, so that a second signal kills the process as usual.
+func run(ctx context.Context, stop func(), args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("graceful", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	grace := fs.Duration("grace", 10*time.Second, "time given to requests in flight on shutdown")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	logger := log.New(stderr, "", log.Ltime|log.Lmicroseconds)
+	srv := &http.Server{Handler: logRequests(logger, routes()), ErrorLog: logger}
+	srv.RegisterOnShutdown(func() { logger.Print("shutting down") })
+	fmt.Fprintln(stdout, "listening on", ln.Addr())
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := graceful.Serve(ctx, srv, ln, *grace); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	logger.Print("drained")
+	return nil
+}
+
+func routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello\n")
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		d := time.Second
+		if s := r.FormValue("d"); s != "" {
+			var err error
+			if d, err = time.ParseDuration(s); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		select {
+		case <-time.After(d):
+			io.WriteString(w, "done\n")
+		case <-r.Context().Done():
+		}
+	})
+	return mux
+}
+
+// logRequests logs each request as it comes, then when it completes.
+func logRequests(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("%s %s", r.Method, r.RequestURI)
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		logger.Printf("%s %s done in %s", r.Method, r.RequestURI, time.Since(start).Round(time.Millisecond))
+	})
+}
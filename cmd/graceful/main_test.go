@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waitLog waits for a log line ending with suffix, the lines being prefixed with the time.
+func waitLog(t *testing.T, logs <-chan string, suffix string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-logs:
+			require.True(t, ok, "no log %q", suffix)
+			if strings.HasSuffix(line, " "+suffix) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no log %q", suffix)
+		}
+	}
+}
+
+func Test_drainOnSignal(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the command")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("signals a process with SIGTERM")
+	}
+	bin := filepath.Join(t.TempDir(), "graceful")
+	out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+	require.NoError(t, err, "%s", out)
+
+	cmd := exec.Command(bin, "-addr", "127.0.0.1:0")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	stderr, err := cmd.StderrPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	lines := bufio.NewScanner(stdout)
+	require.True(t, lines.Scan())
+	addr, ok := strings.CutPrefix(lines.Text(), "listening on ")
+	require.True(t, ok, lines.Text())
+	logs := make(chan string, 16)
+	go func() {
+		defer close(logs)
+		lines := bufio.NewScanner(stderr)
+		for lines.Scan() {
+			logs <- lines.Text()
+		}
+	}()
+
+	inFlight := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow?d=1s")
+		if err != nil {
+			inFlight <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		inFlight <- string(b)
+	}()
+	waitLog(t, logs, "GET /slow?d=1s")
+
+	require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+	waitLog(t, logs, "shutting down")
+	_, err = net.Dial("tcp", addr)
+	require.ErrorIs(t, err, syscall.ECONNREFUSED)
+
+	require.Equal(t, "done\n", <-inFlight)
+	waitLog(t, logs, "drained")
+	require.NoError(t, cmd.Wait())
+}
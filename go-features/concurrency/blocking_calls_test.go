@@ -2,8 +2,13 @@ package concurrency
 
 import (
 	"context"
-	"fmt"
+	"testing"
+	"testing/synctest"
 	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/timeline"
 )
 
 func sendUntilCancelled(ctx context.Context, ch chan<- int, tickerDuration time.Duration) {
@@ -36,32 +41,163 @@ func sendNTimes(ctx context.Context, ch chan<- int, tickerDuration time.Duration
 	}
 }
 
-// Example_makingBlockingCalls shows how to make a blocking call non-blocking by calling inside a goroutine
-func Example_makingBlockingCalls() {
-	ch := make(chan int, 1) // The one-sized buffered channel is useful to avoid blocking the producer if the reader is slow.
-	go sendNTimes(context.Background(), ch, time.Millisecond*50, 5)
-	for v := range ch {
-		fmt.Println(v)
+// producer is the shape of the producers of the lessons: it sends on ch until it is done or ctx is, then closes ch.
+type producer func(ctx context.Context, ch chan<- int)
+
+// produce runs p in the synctest bubble of the caller, with a context done after timeout, none when zero, and
+// receives its values on a channel with the given buffer. The receiver is always waiting, so each value is received
+// at the virtual time it was sent. The timeline has the values, the close of the channel and the end of the context.
+//
+// A producer that doesn't return, or doesn't close its channel, makes the bubble panic with a deadlock.
+func produce(p producer, buffer int, timeout time.Duration) *timeline.Timeline {
+	tl := timeline.New()
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
+	defer tl.Watch(ctx, "ctx")() // stops watching before cancel, which is no event of the lesson
+	ch := make(chan int, buffer)
+	go p(ctx, ch)
+	timeline.Drain(tl, "main", ch)
+	synctest.Wait() // for the event of ctx, recorded by a goroutine of its own
+	return tl
+}
+
+// Test_makingBlockingCalls shows how to make a blocking call non-blocking by calling inside a goroutine.
+//
+// The test runs in a testing/synctest bubble: the ticker of sendNTimes ticks on the fake clock of the bubble, which
+// only moves when every goroutine in it is blocked. The 250ms pass instantly, and each value comes exactly 50ms after
+// the previous one. `dojo run Test_makingBlockingCalls` prints the timeline.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,testing requires=Example_readingFromChannelInForLoop
+func Test_makingBlockingCalls(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		tl := timeline.New()
+		ch := make(chan int, 1) // The one-sized buffered channel is useful to avoid blocking the producer if the reader is slow.
+		go sendNTimes(context.Background(), ch, time.Millisecond*50, 5)
+		timeline.Drain(tl, "main", ch) // for v := range ch, recording each value
 
-	// Output:
-	//0
-	//1
-	//2
-	//3
-	//4
+		t.Log("\n" + tl.String())
+		require.Equal(t, ""+
+			"  50ms main     receive 0\n"+
+			" 100ms main     receive 1\n"+
+			" 150ms main     receive 2\n"+
+			" 200ms main     receive 3\n"+
+			" 250ms main     receive 4\n"+
+			" 250ms main     closed\n", tl.String())
+	})
 }
 
-// Example_makingBlockingCallsWithCancelledContext shows how to make a blocking call non-blocking by calling inside a goroutine
-// this non-blocking producer functions can be controlled with a context
-func Example_makingBlockingCallsWithCancelledContext() {
-	ch := make(chan int, 1) // The one-sized buffered channel is useful to avoid blocking the producer if the reader is slow.
-	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
-	defer cancel()
-	go sendUntilCancelled(ctx, ch, time.Second*10)
-	for v := range ch {
-		fmt.Println(v) // This wil never run because the context is timeouts before any message is sent.
-	}
+// Test_makingBlockingCallsWithCancelledContext shows how to make a blocking call non-blocking by calling inside a
+// goroutine: this non-blocking producer functions can be controlled with a context.
+//
+// The context times out at 50ms, long before the first tick at 10s: the producer closes the channel then, and the
+// loop of the receiver ends without a value. On the fake clock of the bubble both happen at exactly 50ms.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,pitfall,testing requires=Test_makingBlockingCalls
+func Test_makingBlockingCallsWithCancelledContext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		tl := timeline.New()
+		ch := make(chan int, 1) // The one-sized buffered channel is useful to avoid blocking the producer if the reader is slow.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		tl.Watch(ctx, "ctx")
+		go sendUntilCancelled(ctx, ch, time.Second*10)
+		timeline.Drain(tl, "main", ch) // This will never receive because the context times out before any message is sent.
+		synctest.Wait()                // for the event of ctx, recorded by a goroutine of its own
+
+		t.Log("\n" + tl.String())
+		require.Equal(t, ""+
+			"  50ms ctx      canceled: context deadline exceeded\n"+
+			"  50ms main     closed\n", tl.String())
+	})
+}
 
-	// Output:
+// Test_producerTimelines runs the producers of the lessons on the virtual time of a testing/synctest bubble and
+// checks when each value comes: exactly on a tick, with nothing after the context is done. With real time the ticks
+// drift, and a timeout close to a tick races it.
+//
+// The buffer of the channel doesn't change the timeline with a receiver always waiting. It would with a slow one:
+// with no buffer, a producer waiting to send misses the ticks meanwhile, time.Ticker drops them.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,testing requires=Test_makingBlockingCallsWithCancelledContext
+func Test_producerTimelines(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		producer producer
+		buffer   int
+		timeout  time.Duration
+		want     string
+	}{
+		{
+			name: "sendNTimes",
+			producer: func(ctx context.Context, ch chan<- int) {
+				sendNTimes(ctx, ch, time.Second, 3)
+			},
+			buffer: 1,
+			want: "" +
+				"    1s main     receive 0\n" +
+				"    2s main     receive 1\n" +
+				"    3s main     receive 2\n" +
+				"    3s main     closed\n",
+		},
+		{
+			name: "sendNTimes unbuffered",
+			producer: func(ctx context.Context, ch chan<- int) {
+				sendNTimes(ctx, ch, time.Second, 3)
+			},
+			want: "" +
+				"    1s main     receive 0\n" +
+				"    2s main     receive 1\n" +
+				"    3s main     receive 2\n" +
+				"    3s main     closed\n",
+		},
+		{
+			name: "sendNTimes cancelled",
+			producer: func(ctx context.Context, ch chan<- int) {
+				sendNTimes(ctx, ch, time.Second, 3)
+			},
+			buffer:  1,
+			timeout: 2500 * time.Millisecond,
+			want: "" +
+				"    1s main     receive 0\n" +
+				"    2s main     receive 1\n" +
+				"  2.5s ctx      canceled: context deadline exceeded\n" +
+				"  2.5s main     closed\n",
+		},
+		{
+			name: "sendUntilCancelled",
+			producer: func(ctx context.Context, ch chan<- int) {
+				sendUntilCancelled(ctx, ch, time.Second)
+			},
+			buffer:  1,
+			timeout: 3500 * time.Millisecond,
+			want: "" +
+				"    1s main     receive 0\n" +
+				"    2s main     receive 1\n" +
+				"    3s main     receive 2\n" +
+				"  3.5s ctx      canceled: context deadline exceeded\n" +
+				"  3.5s main     closed\n",
+		},
+		{
+			name: "sendUntilCancelled before the first tick",
+			producer: func(ctx context.Context, ch chan<- int) {
+				sendUntilCancelled(ctx, ch, 10*time.Second)
+			},
+			buffer:  1,
+			timeout: 50 * time.Millisecond,
+			want: "" +
+				"  50ms ctx      canceled: context deadline exceeded\n" +
+				"  50ms main     closed\n",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				tl := produce(c.producer, c.buffer, c.timeout)
+				require.Equal(t, c.want, tl.String())
+			})
+		})
+	}
 }
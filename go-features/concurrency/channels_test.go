@@ -4,6 +4,8 @@ import "fmt"
 
 // Example_readingFromClosedChannel shows how you can read from closed channels without a problem,
 // but it can be confusing, so using the second return variable to check if the channel is open is recommended.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=concurrency,pitfall
 func Example_readingFromClosedChannel() {
 	ch := make(chan int)
 	close(ch)
@@ -19,6 +21,8 @@ func Example_readingFromClosedChannel() {
 }
 
 // Example_readingFromChannelInForLoop shows how to use the `range` keywork to read from a channel until closed safely.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=concurrency,basics requires=Example_readingFromClosedChannel
 func Example_readingFromChannelInForLoop() {
 	ch := make(chan int)
 	go func() {
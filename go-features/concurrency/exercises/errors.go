@@ -0,0 +1,6 @@
+package exercises
+
+import "errors"
+
+// ErrTimeout is returned by CallWithTimeout when f is too slow.
+var ErrTimeout = errors.New("timeout")
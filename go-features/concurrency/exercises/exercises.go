@@ -0,0 +1,29 @@
+//go:build !solution
+
+// Package exercises is the concurrency kata track. Replace every `panic("TODO")` with an implementation and run
+// `go run ./cmd/dojo check concurrency` to grade it.
+package exercises
+
+import (
+	"context"
+	"time"
+)
+
+// SendNTimes sends 0, 1, ..., n-1 to ch, one value per tick of a ticker of period d, and closes ch when it is done.
+// It must stop early, still closing ch, when ctx is cancelled.
+//
+// See concurrency.Test_makingBlockingCalls for the lesson this kata is based on.
+func SendNTimes(ctx context.Context, ch chan<- int, d time.Duration, n int) {
+	panic("TODO")
+}
+
+// Merge fans in the values of every channel into the returned one, which is closed once all the inputs are closed.
+func Merge(chs ...<-chan int) <-chan int {
+	panic("TODO")
+}
+
+// CallWithTimeout calls f and returns its result, or ErrTimeout if it does not return within d.
+// The goroutine running f must not leak when the timeout wins.
+func CallWithTimeout(f func() int, d time.Duration) (int, error) {
+	panic("TODO")
+}
@@ -0,0 +1,128 @@
+package exercises
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+)
+
+func Test_SendNTimes(t *testing.T) {
+	kata.Bubble(t, func(t *testing.T) {
+		start := time.Now()
+		ch := make(chan int)
+		var got []int
+		var at []time.Duration
+		done := make(chan struct{})
+		stop := make(chan struct{})
+		defer close(stop) // releases the reader if SendNTimes is still a stub
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					got = append(got, v)
+					at = append(at, time.Since(start))
+				}
+			}
+		}()
+
+		SendNTimes(context.Background(), ch, time.Second, 3)
+		<-done
+		require.Equal(t, []int{0, 1, 2}, got)
+		require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, at, "one value per tick")
+	})
+}
+
+func Test_SendNTimesCancelled(t *testing.T) {
+	kata.Bubble(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		ch := make(chan int, 10)
+
+		SendNTimes(ctx, ch, time.Second, 10)
+		var got []int
+		for v := range ch { // must be closed
+			got = append(got, v)
+		}
+		require.Equal(t, []int{0, 1}, got)
+	})
+}
+
+func Test_SendNTimesReaderGone(t *testing.T) {
+	kata.Bubble(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// Nobody reads the channel: the producer must give up when the context is done instead of blocking forever.
+		SendNTimes(ctx, make(chan int), time.Second, 3)
+		require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	})
+}
+
+func Test_Merge(t *testing.T) {
+	kata.Bubble(t, func(t *testing.T) {
+		a, b := make(chan int), make(chan int)
+		out := Merge(a, b) // called before starting the senders, which would block forever on a stub
+		go func() {
+			for i := range 3 {
+				a <- i
+			}
+			close(a)
+		}()
+		go func() {
+			for i := range 3 {
+				b <- 10 + i
+			}
+			close(b)
+		}()
+
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		slices.Sort(got)
+		require.Equal(t, []int{0, 1, 2, 10, 11, 12}, got)
+	})
+}
+
+func Test_MergeNothing(t *testing.T) {
+	kata.Bubble(t, func(t *testing.T) {
+		_, ok := <-Merge()
+		require.False(t, ok, "merging no channel is an already closed channel")
+	})
+}
+
+func Test_CallWithTimeout(t *testing.T) {
+	// The bubble fails with "blocked goroutines remain" if the goroutine calling f leaks.
+	kata.Bubble(t, func(t *testing.T) {
+		slow := func() int {
+			time.Sleep(time.Minute)
+			return 1
+		}
+		fast := func() int { return 2 }
+
+		v, err := CallWithTimeout(fast, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, 2, v)
+
+		start := time.Now()
+		_, err = CallWithTimeout(slow, time.Second)
+		require.ErrorIs(t, err, ErrTimeout)
+		require.Equal(t, time.Second, time.Since(start))
+
+		// Once slow returns, the goroutine that called it must be able to exit even though nobody reads its result.
+		time.Sleep(time.Minute)
+		synctest.Wait()
+	})
+}
@@ -0,0 +1,9 @@
+package exercises
+
+import (
+	"testing"
+
+	"github.com/juan-carvajal/go-dojo/internal/leak"
+)
+
+func TestMain(m *testing.M) { leak.Main(m) }
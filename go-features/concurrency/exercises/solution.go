@@ -0,0 +1,62 @@
+//go:build solution
+
+package exercises
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SendNTimes sends 0, 1, ..., n-1 to ch, one value per tick of a ticker of period d, and closes ch when it is done.
+// It must stop early, still closing ch, when ctx is cancelled.
+func SendNTimes(ctx context.Context, ch chan<- int, d time.Duration, n int) {
+	defer close(ch)
+	t := time.NewTicker(d)
+	defer t.Stop() // a forgotten Stop keeps the ticker alive
+	for i := range n {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		select { // the send must not block forever either, if the reader is gone
+		case <-ctx.Done():
+			return
+		case ch <- i:
+		}
+	}
+}
+
+// Merge fans in the values of every channel into the returned one, which is closed once all the inputs are closed.
+func Merge(chs ...<-chan int) <-chan int {
+	out := make(chan int)
+	var wg sync.WaitGroup
+	for _, ch := range chs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range ch {
+				out <- v
+			}
+		}()
+	}
+	go func() {
+		wg.Wait() // only close once every sender is done, or they would panic sending on a closed channel
+		close(out)
+	}()
+	return out
+}
+
+// CallWithTimeout calls f and returns its result, or ErrTimeout if it does not return within d.
+// The goroutine running f must not leak when the timeout wins.
+func CallWithTimeout(f func() int, d time.Duration) (int, error) {
+	res := make(chan int, 1) // buffered, so that the goroutine can finish its send when nobody reads it anymore
+	go func() { res <- f() }()
+	select {
+	case v := <-res:
+		return v, nil
+	case <-time.After(d):
+		return 0, ErrTimeout
+	}
+}
@@ -0,0 +1,47 @@
+// Package concurrencytest has the fixtures of the concurrency tests and lessons: a counter of what is in use at once,
+// to assert the bound of a pool or a pipeline, and an error for what is made to fail.
+package concurrencytest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBoom is the error of a stage or a job made to fail.
+var ErrBoom = errors.New("boom")
+
+// Peak counts what is in use, e.g. goroutines, connections or bytes, and the most that was at once. The zero value
+// is ready to use.
+type Peak struct {
+	now, max atomic.Int64
+}
+
+// Add adds n to what is in use, n being negative when it is released.
+func (p *Peak) Add(n int64) {
+	now := p.now.Add(n)
+	for m := p.max.Load(); now > m && !p.max.CompareAndSwap(m, now); m = p.max.Load() {
+	}
+}
+
+// Hold holds n for d: in a testing/synctest bubble, d of its fake clock.
+func (p *Peak) Hold(n int64, d time.Duration) {
+	p.Add(n)
+	defer p.Add(-n)
+	time.Sleep(d)
+}
+
+// Max returns the most that was in use at once.
+func (p *Peak) Max() int64 {
+	return p.max.Load()
+}
+
+// Func returns f, holding one of p while it runs.
+func Func[In, Out any](p *Peak, f func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, v In) (Out, error) {
+		p.Add(1)
+		defer p.Add(-1)
+		return f(ctx, v)
+	}
+}
@@ -0,0 +1,37 @@
+package concurrencytest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeak(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var p Peak
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Go(func() {
+				time.Sleep(time.Duration(i) * 1500 * time.Millisecond)
+				p.Hold(10, 2*time.Second) // from 0s, 1.5s, 3s and 4.5s: two at once
+			})
+		}
+		wg.Wait()
+		require.EqualValues(t, 20, p.Max())
+
+		f := Func(&p, func(_ context.Context, v int) (int, error) {
+			p.Hold(1, time.Second)
+			return v, nil
+		})
+		v, err := f(context.Background(), 7)
+		require.NoError(t, err)
+		require.Equal(t, 7, v)
+		require.EqualValues(t, 20, p.Max())
+		p.Add(25)
+		require.EqualValues(t, 25, p.Max())
+	})
+}
@@ -0,0 +1,167 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/leak"
+)
+
+var errTimeout = errors.New("timeout")
+
+// callWithTimeout runs call in a goroutine that sends its result on results, and gives up on it after timeout.
+func callWithTimeout(results chan string, call func() string, timeout time.Duration) (string, error) {
+	go func() { results <- call() }()
+	select {
+	case r := <-results:
+		return r, nil
+	case <-time.After(timeout):
+		return "", errTimeout
+	}
+}
+
+// Test_leakAbandonedSend shows the most common goroutine leak: a goroutine sending on an unbuffered channel that
+// nobody receives from anymore. callWithTimeout gives up on a slow call, and returns: when the call ends, its goroutine
+// blocks forever sending the result, since a send on an unbuffered channel waits for a receiver.
+//
+// A goroutine is never garbage collected, nor what it references. The leak is found by comparing the goroutines before
+// and after with leak.Find: the leaked goroutine is stuck in `chan send`, and its stack tells where. A TestMain with
+// leak.Main runs the same check after all the lessons of this package.
+//
+// The fix is a channel with a buffer of one: the send completes whether someone receives or not, the goroutine ends,
+// and the channel is garbage collected with the result in it.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,pitfall,memory requires=Test_makingBlockingCalls
+func Test_leakAbandonedSend(t *testing.T) {
+	before := leak.Snapshot()
+	release := make(chan struct{})
+	slow := func() string {
+		<-release
+		return "result"
+	}
+
+	results := make(chan string)
+	_, err := callWithTimeout(results, slow, 10*time.Millisecond)
+	require.ErrorIs(t, err, errTimeout)
+	close(release)
+	leaked := leak.Find(before, 100*time.Millisecond)
+	t.Log(leak.Report(leaked))
+	require.Len(t, leaked, 1)
+	require.Equal(t, "chan send", leaked[0].State)
+	require.Contains(t, leaked[0].CreatedBy(), "callWithTimeout")
+
+	<-results // only the lesson can still receive, to end the leak
+	require.Empty(t, leak.Find(before, leak.Wait))
+
+	release = make(chan struct{})
+	_, err = callWithTimeout(make(chan string, 1), slow, 10*time.Millisecond)
+	require.ErrorIs(t, err, errTimeout)
+	close(release)
+	require.Empty(t, leak.Find(before, leak.Wait))
+}
+
+// pollEvery calls work on every tick of a ticker, until ctx is done, which it notices on the next tick.
+func pollEvery(ctx context.Context, t *time.Ticker, work func()) {
+	for range t.C {
+		if ctx.Err() != nil {
+			return
+		}
+		work()
+	}
+}
+
+// pollUntilDone calls work on every tick of a ticker of period d, until ctx is done.
+func pollUntilDone(ctx context.Context, d time.Duration, work func()) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			work()
+		}
+	}
+}
+
+// Test_leakTickerStop shows a goroutine leaked by stopping a ticker: Stop doesn't close the channel of the ticker, it
+// only stops the ticks. pollEvery checks its context on each tick, the caller cancels it then stops the ticker, and
+// pollEvery waits for a tick that never comes, in `chan receive`.
+//
+// Forgetting to call Stop is no longer a leak: since Go 1.23, a ticker nobody references is garbage collected, stopped
+// or not, in modules declaring go 1.23 or later. Stop is still good practice, it stops the ticks right away.
+// pollUntilDone is both: it waits for the context or a tick, whichever comes first, and stops its ticker on return.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,pitfall,runtime requires=Test_leakAbandonedSend
+func Test_leakTickerStop(t *testing.T) {
+	before := leak.Snapshot()
+	ticker := time.NewTicker(time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	go pollEvery(ctx, ticker, func() {})
+
+	cancel()
+	ticker.Stop()
+	leaked := leak.Find(before, 100*time.Millisecond)
+	t.Log(leak.Report(leaked))
+	require.Len(t, leaked, 1)
+	require.Equal(t, "chan receive", leaked[0].State)
+	require.Contains(t, leaked[0].Func(), "pollEvery")
+
+	ticker.Reset(time.Millisecond) // one more tick, for pollEvery to see its context done
+	require.Empty(t, leak.Find(before, leak.Wait))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go pollUntilDone(ctx, time.Millisecond, func() {})
+	cancel()
+	require.Empty(t, leak.Find(before, leak.Wait))
+}
+
+// sum adds the values of ch until it is closed, and sends the total on total.
+func sum(ch <-chan int, total chan<- int) {
+	s := 0
+	for v := range ch {
+		s += v
+	}
+	total <- s
+}
+
+// Test_leakRangeNeverClosed shows a consumer leaked by its producer: a range over a channel only ends when the
+// channel is closed. The producer sends its values and returns without closing the channel, and sum waits for the next
+// value forever, in `chan receive`, its total never sent.
+//
+// The goroutine that sends is the one that knows when there is nothing more to send: it closes the channel, with a
+// defer so that an early return closes it too.
+//
+//dojo:meta difficulty=beginner minutes=5 tags=concurrency,pitfall requires=Example_readingFromChannelInForLoop,Test_leakAbandonedSend
+func Test_leakRangeNeverClosed(t *testing.T) {
+	before := leak.Snapshot()
+	produce := func(ch chan<- int, close func()) {
+		defer close()
+		for i := range 4 {
+			ch <- i
+		}
+	}
+
+	ch, total := make(chan int), make(chan int, 1)
+	go sum(ch, total)
+	produce(ch, func() {}) // forgets to close ch
+	leaked := leak.Find(before, 100*time.Millisecond)
+	t.Log(leak.Report(leaked))
+	require.Len(t, leaked, 1)
+	require.Equal(t, "chan receive", leaked[0].State)
+	require.Contains(t, leaked[0].Func(), "sum")
+
+	close(ch) // what the producer should have done
+	require.Equal(t, 6, <-total)
+	require.Empty(t, leak.Find(before, leak.Wait))
+
+	ch = make(chan int)
+	go sum(ch, total)
+	produce(ch, func() { close(ch) })
+	require.Equal(t, 6, <-total)
+	require.Empty(t, leak.Find(before, leak.Wait))
+}
@@ -0,0 +1,229 @@
+// Package pipeline builds pipelines of goroutines connected by channels: a source, stages that each receive values
+// from the previous one and send their results to the next, and the caller receiving from the last one.
+//
+// Every stage runs in a goroutine of a Pipeline. The first stage that fails cancels the context of the pipeline, with
+// its error as the cause: every stage stops at its next send or receive, and closes its output, down to the caller,
+// whose range ends once it received the values already in a Buffer. Wait then returns that error, once every
+// goroutine of the pipeline returned.
+//
+//	p := pipeline.New(ctx)
+//	paths := pipeline.Source(p, slices.Values(names))
+//	sizes := pipeline.Ordered(p, paths, 4, size)
+//	for size := range sizes {
+//		total += size
+//	}
+//	err := p.Wait()
+//
+// Channels between stages are unbuffered: a stage can't send before the next one receives, so a slow stage slows
+// down every stage before it, the source included. That is backpressure, and what bounds the memory of a pipeline.
+// Buffer adds a buffer where a stage is bursty.
+//
+// A caller that stops receiving before the end cancels the context given to New, or the stage sending to it waits
+// forever, and so does Wait.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// Pipeline is a group of stages, sharing a context that is cancelled when one of them fails.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// New returns an empty pipeline, which stops when ctx is done.
+func New(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context returns the context of the stages, done when the pipeline stops.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Go runs a stage in a goroutine. An error stops the pipeline, the first one is returned by Wait.
+func (p *Pipeline) Go(stage func(ctx context.Context) error) {
+	p.wg.Go(func() {
+		if err := stage(p.ctx); err != nil {
+			p.once.Do(func() {
+				p.err = err
+				p.cancel(err)
+			})
+		}
+	})
+}
+
+// Wait waits for every stage to return, and returns the first error. A pipeline stopped by its context returns the
+// error of the context.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel(nil)
+	return p.err
+}
+
+// send sends v on out, unless ctx is done first.
+func send[T any](ctx context.Context, out chan<- T, v T) error {
+	select {
+	case out <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// receive receives a value from in, unless ctx is done first. It returns false once in is closed.
+func receive[T any](ctx context.Context, in <-chan T) (T, bool, error) {
+	select {
+	case v, ok := <-in:
+		return v, ok, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
+
+// Source sends the values of seq, in order.
+func Source[T any](p *Pipeline, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	p.Go(func(ctx context.Context) error {
+		defer close(out)
+		for v := range seq {
+			if err := send(ctx, out, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Stage sends f of each value of in, in order. An error of f stops the pipeline.
+func Stage[In, Out any](p *Pipeline, in <-chan In, f func(context.Context, In) (Out, error)) <-chan Out {
+	out := make(chan Out)
+	p.Go(func(ctx context.Context) error {
+		defer close(out)
+		return apply(ctx, in, out, f)
+	})
+	return out
+}
+
+// apply sends f of each value of in on out, until in is closed.
+func apply[In, Out any](ctx context.Context, in <-chan In, out chan<- Out, f func(context.Context, In) (Out, error)) error {
+	for {
+		v, ok, err := receive(ctx, in)
+		if !ok {
+			return err
+		}
+		r, err := f(ctx, v)
+		if err != nil {
+			return err
+		}
+		if err := send(ctx, out, r); err != nil {
+			return err
+		}
+	}
+}
+
+// Buffer sends the values of in on a channel with a buffer of n: up to n values wait there for the next stage, and
+// the stages before keep going meanwhile.
+func Buffer[T any](p *Pipeline, in <-chan T, n int) <-chan T {
+	out := make(chan T, n)
+	p.Go(func(ctx context.Context) error {
+		defer close(out)
+		return apply(ctx, in, out, func(_ context.Context, v T) (T, error) { return v, nil })
+	})
+	return out
+}
+
+// FanOut runs f on the values of in in n goroutines, each taking the next value when it is free, and returns their
+// outputs. Merge them with Merge, results come in the order they are done: Ordered keeps the order of in. It panics
+// if n is less than 1: nothing would receive from in.
+func FanOut[In, Out any](p *Pipeline, in <-chan In, n int, f func(context.Context, In) (Out, error)) []<-chan Out {
+	if n < 1 {
+		panic(fmt.Sprintf("pipeline: FanOut to %d goroutines", n))
+	}
+	outs := make([]<-chan Out, n)
+	for i := range outs {
+		outs[i] = Stage(p, in, f)
+	}
+	return outs
+}
+
+// Merge sends the values of all ins, as they come. The output is closed once all ins are.
+func Merge[T any](p *Pipeline, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Add(1)
+		p.Go(func(ctx context.Context) error {
+			defer wg.Done()
+			return apply(ctx, in, out, func(_ context.Context, v T) (T, error) { return v, nil })
+		})
+	}
+	p.Go(func(context.Context) error {
+		wg.Wait()
+		close(out)
+		return nil
+	})
+	return out
+}
+
+// Ordered runs f on the values of in, up to n at a time, and sends the results in the order of in. A slow value holds
+// back the results after it, up to n of them: then no new value starts until it is done. It panics if n is less
+// than 1.
+func Ordered[In, Out any](p *Pipeline, in <-chan In, n int, f func(context.Context, In) (Out, error)) <-chan Out {
+	if n < 1 {
+		panic(fmt.Sprintf("pipeline: Ordered with %d at a time", n))
+	}
+	// Each value gets a channel for its result, queued in the order of in. The collector waits for the result at the
+	// head of the queue while the n-1 in the queue run: n at a time.
+	queue := make(chan chan Out, n-1)
+	p.Go(func(ctx context.Context) error {
+		defer close(queue)
+		for {
+			v, ok, err := receive(ctx, in)
+			if !ok {
+				return err
+			}
+			result := make(chan Out, 1)
+			if err := send(ctx, queue, result); err != nil {
+				return err
+			}
+			p.Go(func(ctx context.Context) error {
+				r, err := f(ctx, v)
+				if err != nil {
+					return err
+				}
+				result <- r
+				return nil
+			})
+		}
+	})
+
+	out := make(chan Out)
+	p.Go(func(ctx context.Context) error {
+		defer close(out)
+		return apply(ctx, queue, out, func(ctx context.Context, result chan Out) (Out, error) {
+			r, _, err := receive(ctx, result)
+			return r, err
+		})
+	})
+	return out
+}
+
+// Collect receives all the values of in, then waits for p.
+func Collect[T any](p *Pipeline, in <-chan T) ([]T, error) {
+	var vs []T
+	for v := range in {
+		vs = append(vs, v)
+	}
+	return vs, p.Wait()
+}
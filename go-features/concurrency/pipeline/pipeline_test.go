@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/internal/concurrencytest"
+	"github.com/juan-carvajal/go-dojo/internal/leak"
+)
+
+func TestMain(m *testing.M) { leak.Main(m) }
+
+func square(_ context.Context, v int) (int, error) {
+	return v * v, nil
+}
+
+// count yields 0, 1, 2... forever.
+func count(yield func(int) bool) {
+	for i := 0; yield(i); i++ {
+	}
+}
+
+// sleepy takes 2^(4-v) seconds for v: the first values are the slowest.
+func sleepy(ctx context.Context, v int) (int, error) {
+	select {
+	case <-time.After(time.Duration(1<<(4-v)) * time.Second):
+		return v, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestStages(t *testing.T) {
+	p := New(context.Background())
+	vs, err := Collect(p, Stage(p, Source(p, slices.Values([]int{1, 2, 3, 4})), square))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 4, 9, 16}, vs)
+
+	p = New(context.Background())
+	vs, err = Collect(p, Buffer(p, Source(p, slices.Values([]int{})), 2))
+	require.NoError(t, err)
+	require.Empty(t, vs)
+}
+
+func TestFanOutMerge(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var busy concurrencytest.Peak
+		start := time.Now()
+		p := New(context.Background())
+		outs := FanOut(p, Source(p, slices.Values([]int{0, 1, 2, 3, 4})), 3, concurrencytest.Func(&busy, sleepy))
+		vs, err := Collect(p, Merge(p, outs...))
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 3, 4, 1, 0}, vs) // 0, 1 and 2 start at 0s, 3 at 4s after 2, 4 at 6s after 3
+		require.Equal(t, 16*time.Second, time.Since(start))
+		require.EqualValues(t, 3, busy.Max())
+	})
+}
+
+func TestOrdered(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		synctest.Test(t, func(t *testing.T) {
+			var busy concurrencytest.Peak
+			p := New(context.Background())
+			out := Ordered(p, Source(p, slices.Values([]int{0, 1, 2, 3, 4})), n, concurrencytest.Func(&busy, sleepy))
+			vs, err := Collect(p, out)
+			require.NoError(t, err)
+			require.Equal(t, []int{0, 1, 2, 3, 4}, vs)
+			require.EqualValues(t, n, busy.Max())
+		})
+	}
+}
+
+func TestLessThanOne(t *testing.T) {
+	p := New(context.Background())
+	in := Source(p, slices.Values([]int{1, 2}))
+	require.PanicsWithValue(t, "pipeline: FanOut to 0 goroutines", func() { FanOut(p, in, 0, square) })
+	require.PanicsWithValue(t, "pipeline: Ordered with -1 at a time", func() { Ordered(p, in, -1, square) })
+	vs, err := Collect(p, in)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, vs)
+}
+
+func TestErrorStopsEveryStage(t *testing.T) {
+	failAt3 := func(_ context.Context, v int) (int, error) {
+		if v == 3 {
+			return 0, concurrencytest.ErrBoom
+		}
+		return v, nil
+	}
+	for name, build := range map[string]func(p *Pipeline, in <-chan int) <-chan int{
+		"Stage": func(p *Pipeline, in <-chan int) <-chan int {
+			return Stage(p, Stage(p, in, failAt3), square)
+		},
+		"Buffer": func(p *Pipeline, in <-chan int) <-chan int {
+			return Buffer(p, Stage(p, Buffer(p, in, 4), failAt3), 4)
+		},
+		"FanOut": func(p *Pipeline, in <-chan int) <-chan int {
+			return Merge(p, FanOut(p, in, 3, failAt3)...)
+		},
+		"Ordered": func(p *Pipeline, in <-chan int) <-chan int {
+			return Ordered(p, in, 3, failAt3)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			leak.Check(t)
+			p := New(context.Background())
+			vs, err := Collect(p, build(p, Source(p, count)))
+			require.ErrorIs(t, err, concurrencytest.ErrBoom)
+			require.ErrorIs(t, context.Cause(p.Context()), concurrencytest.ErrBoom)
+			require.NotContains(t, vs, 3)
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	leak.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := New(ctx)
+	n := 0
+	for range Ordered(p, Stage(p, Source(p, count), square), 4, square) {
+		if n++; n == 3 {
+			cancel()
+		}
+	}
+	require.ErrorIs(t, p.Wait(), context.Canceled)
+}
+
+func TestBackpressure(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		buffer int
+		ahead  int
+	}{
+		// The caller holds the value it received, and each goroutine before it holds the next one, waiting to send it:
+		// the stage, and the source. A buffer holds its values, and the value its goroutine waits to send.
+		{name: "unbuffered", ahead: 2},
+		{name: "buffer of 4", buffer: 4, ahead: 7},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				var produced atomic.Int32
+				var seq iter.Seq[int] = func(yield func(int) bool) {
+					for i := 0; ; i++ {
+						produced.Add(1)
+						if !yield(i) {
+							return
+						}
+					}
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				p := New(ctx)
+				in := Stage(p, Source(p, seq), square)
+				if c.buffer > 0 {
+					in = Buffer(p, in, c.buffer)
+				}
+				received := 0
+				for range in {
+					received++
+					time.Sleep(time.Second) // a slow caller
+					synctest.Wait()
+					if ctx.Err() == nil { // values still in the buffer come after cancel
+						require.EqualValues(t, received+c.ahead, produced.Load())
+					}
+					if received == 10 {
+						cancel()
+					}
+				}
+				require.ErrorIs(t, p.Wait(), context.Canceled)
+			})
+		})
+	}
+}
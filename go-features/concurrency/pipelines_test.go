@@ -0,0 +1,298 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/pipeline"
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/timeline"
+	"github.com/juan-carvajal/go-dojo/internal/leak"
+)
+
+// slowly returns f taking d, recording each result on tl as who.
+func slowly[In, Out any](tl *timeline.Timeline, who string, d time.Duration, f func(In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, v In) (Out, error) {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			var zero Out
+			return zero, ctx.Err()
+		}
+		r, err := f(v)
+		if err != nil {
+			tl.Record(who, "%v: %v", v, err)
+		} else {
+			tl.Record(who, "%v -> %v", v, r)
+		}
+		return r, err
+	}
+}
+
+func squareInt(v int) (int, error) { return v * v, nil }
+
+// Test_pipelineStages shows a pipeline: goroutines connected by channels, each stage receiving from the one before,
+// and sending its results to the one after. Parsing takes 1s per value, squaring 1.5s: done one after the other, 3
+// values would take 7.5s. As stages, they overlap: while the first value is squared the second one is parsed, and
+// the pipeline takes 5.5s, the time of the first value through all the stages, then 1.5s per value, the time of the
+// slowest stage. The parse of 2 is done at 2s, but waits for square to be done with 1 to hand it over.
+//
+// The pipeline package has the goroutines and channels of each stage, and what a stage must do to be a good citizen,
+// see Test_pipelineCancellation.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency requires=Test_makingBlockingCalls
+func Test_pipelineStages(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		tl := timeline.New()
+		p := pipeline.New(context.Background())
+		strs := pipeline.Source(p, slices.Values([]string{"1", "2", "3"}))
+		ints := pipeline.Stage(p, strs, slowly(tl, "parse", time.Second, strconv.Atoi))
+		squares := pipeline.Stage(p, ints, slowly(tl, "square", 1500*time.Millisecond, squareInt))
+		for v := range squares {
+			tl.Record("main", "receive %d", v)
+		}
+		require.NoError(t, p.Wait())
+
+		t.Log("\n" + tl.String())
+		require.Equal(t, ""+
+			"    1s parse    1 -> 1\n"+
+			"    2s parse    2 -> 2\n"+
+			"  2.5s square   1 -> 1\n"+
+			"  2.5s main     receive 1\n"+
+			"  3.5s parse    3 -> 3\n"+
+			"    4s square   2 -> 4\n"+
+			"    4s main     receive 4\n"+
+			"  5.5s square   3 -> 9\n"+
+			"  5.5s main     receive 9\n", tl.String())
+	})
+}
+
+// naiveStage is a stage as often written: it sends f of each value of in on its output, until in is closed.
+func naiveStage[In, Out any](in <-chan In, f func(In) Out) <-chan Out {
+	out := make(chan Out)
+	go func() {
+		defer close(out)
+		for v := range in {
+			out <- f(v)
+		}
+	}()
+	return out
+}
+
+// Test_pipelineCancellation shows why every stage of a pipeline must watch a context. The caller stops at the first
+// value it doesn't want: with naive stages, the last stage waits forever to send its next value, the stage before
+// waits to send to it, and so on to the source. The whole pipeline leaks, as Test_leakAbandonedSend does for one
+// goroutine.
+//
+// In the pipeline package, every send and receive of a stage selects on the context of the pipeline too. A caller
+// stopping early cancels it, the first stage that fails does too: every stage returns and closes its output, and
+// Wait returns once no goroutine is left.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,pitfall requires=Test_pipelineStages,Test_leakAbandonedSend
+func Test_pipelineCancellation(t *testing.T) {
+	before := leak.Snapshot()
+	source := make(chan string)
+	go func() {
+		defer close(source)
+		for _, s := range []string{"1", "2", "x", "4", "5"} {
+			source <- s
+		}
+	}()
+	parsed := naiveStage(source, func(s string) error {
+		_, err := strconv.Atoi(s)
+		return err
+	})
+	for err := range parsed {
+		if err != nil {
+			break // the caller gives up
+		}
+	}
+	leaked := leak.Find(before, 100*time.Millisecond)
+	t.Log(leak.Report(leaked))
+	require.Len(t, leaked, 2) // the source, and the stage
+
+	for range parsed { // only the lesson can still receive, to end the leak
+	}
+	require.Empty(t, leak.Find(before, leak.Wait))
+
+	p := pipeline.New(context.Background())
+	strs := pipeline.Source(p, slices.Values([]string{"1", "2", "x", "4", "5"}))
+	ints := pipeline.Stage(p, strs, func(_ context.Context, s string) (int, error) { return strconv.Atoi(s) })
+	vs, err := pipeline.Collect(p, ints)
+	require.Equal(t, []int{1, 2}, vs)
+	require.EqualError(t, err, `strconv.Atoi: parsing "x": invalid syntax`)
+	require.Empty(t, leak.Find(before, leak.Wait))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p = pipeline.New(ctx)
+	for v := range pipeline.Source(p, slices.Values([]int{1, 2, 3, 4, 5})) {
+		if v == 2 {
+			cancel() // the caller gives up
+		}
+	}
+	require.ErrorIs(t, p.Wait(), context.Canceled)
+	require.Empty(t, leak.Find(before, leak.Wait))
+}
+
+// Test_pipelineFanOut shows how to run a slow stage in several goroutines: fan-out, then how to gather their results:
+// fan-in. Fetching the pages takes 4s, 3s, 3s and 1s: 11s in one goroutine.
+//
+// FanOut starts 2 goroutines, each taking the next page when it is free, and Merge gathers their results as they
+// come: page 1 at 3s, page 2 starts then, page 0 at 4s, page 3 starts then, and is done before page 2. It takes 6s,
+// the pages out of order. When the order matters, Ordered runs up to 2 at a time too, but sends the results in the
+// order of the pages: page 0 holds back page 1, and as both are being fetched, or done and waiting, page 2 only
+// starts at 4s. It takes 7s.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency requires=Test_pipelineStages
+func Test_pipelineFanOut(t *testing.T) {
+	durations := []time.Duration{4 * time.Second, 3 * time.Second, 3 * time.Second, time.Second}
+	fetch := func(tl *timeline.Timeline) func(context.Context, int) (string, error) {
+		return func(ctx context.Context, v int) (string, error) {
+			return slowly(tl, "fetch", durations[v], func(v int) (string, error) {
+				return fmt.Sprint("page ", v), nil
+			})(ctx, v)
+		}
+	}
+	for _, c := range []struct {
+		name  string
+		stage func(p *pipeline.Pipeline, in <-chan int, f func(context.Context, int) (string, error)) <-chan string
+		want  string
+	}{
+		{
+			name: "FanOut and Merge",
+			stage: func(p *pipeline.Pipeline, in <-chan int, f func(context.Context, int) (string, error)) <-chan string {
+				return pipeline.Merge(p, pipeline.FanOut(p, in, 2, f)...)
+			},
+			want: "" +
+				"    3s fetch    1 -> page 1\n" +
+				"    3s main     receive page 1\n" +
+				"    4s fetch    0 -> page 0\n" +
+				"    4s main     receive page 0\n" +
+				"    5s fetch    3 -> page 3\n" +
+				"    5s main     receive page 3\n" +
+				"    6s fetch    2 -> page 2\n" +
+				"    6s main     receive page 2\n",
+		},
+		{
+			name: "Ordered",
+			stage: func(p *pipeline.Pipeline, in <-chan int, f func(context.Context, int) (string, error)) <-chan string {
+				return pipeline.Ordered(p, in, 2, f)
+			},
+			want: "" +
+				"    3s fetch    1 -> page 1\n" +
+				"    4s fetch    0 -> page 0\n" +
+				"    4s main     receive page 0\n" +
+				"    4s main     receive page 1\n" +
+				"    5s fetch    3 -> page 3\n" +
+				"    7s fetch    2 -> page 2\n" +
+				"    7s main     receive page 2\n" +
+				"    7s main     receive page 3\n",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				tl := timeline.New()
+				p := pipeline.New(context.Background())
+				for page := range c.stage(p, pipeline.Source(p, slices.Values([]int{0, 1, 2, 3})), fetch(tl)) {
+					tl.Record("main", "receive %s", page)
+				}
+				require.NoError(t, p.Wait())
+
+				t.Log("\n" + tl.String())
+				require.Equal(t, c.want, tl.String())
+			})
+		})
+	}
+}
+
+// Test_pipelineBackpressure shows how a slow stage slows down the stages before it. The channels between stages are
+// unbuffered: a stage can only send once the next one receives. The caller takes 1s per value, and the source makes
+// its values at that pace too, not faster: each goroutine of the pipeline holds one value, waiting for the next one to
+// take it. Backpressure is what bounds the memory of a pipeline, whatever the speed of the source.
+//
+// A buffer absorbs bursts: the source runs 4 values further ahead, 3 in the buffer and the one its goroutine waits to
+// send, then it is slowed down all the same. A buffer never makes a slow stage faster.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,memory requires=Test_pipelineStages
+func Test_pipelineBackpressure(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		buffer int
+		want   string
+	}{
+		{
+			name: "unbuffered",
+			want: "" +
+				"    0s source   make 0\n" +
+				"    0s source   make 1\n" +
+				"    1s main     done with 0\n" +
+				"    1s source   make 2\n" +
+				"    2s main     done with 1\n" +
+				"    2s source   make 3\n" +
+				"    3s main     done with 2\n" +
+				"    3s source   make 4\n" +
+				"    4s main     done with 3\n" +
+				"    4s source   make 5\n" +
+				"    5s main     done with 4\n" +
+				"    5s source   make 6\n" +
+				"    6s main     done with 5\n" +
+				"    6s source   make 7\n" +
+				"    7s main     done with 6\n" +
+				"    8s main     done with 7\n",
+		},
+		{
+			name:   "buffer of 3",
+			buffer: 3,
+			want: "" +
+				"    0s source   make 0\n" +
+				"    0s source   make 1\n" +
+				"    0s source   make 2\n" +
+				"    0s source   make 3\n" +
+				"    0s source   make 4\n" +
+				"    0s source   make 5\n" +
+				"    1s main     done with 0\n" +
+				"    1s source   make 6\n" +
+				"    2s main     done with 1\n" +
+				"    2s source   make 7\n" +
+				"    3s main     done with 2\n" +
+				"    4s main     done with 3\n" +
+				"    5s main     done with 4\n" +
+				"    6s main     done with 5\n" +
+				"    7s main     done with 6\n" +
+				"    8s main     done with 7\n",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				tl := timeline.New()
+				p := pipeline.New(context.Background())
+				values := pipeline.Source(p, func(yield func(int) bool) {
+					for i := range 8 {
+						tl.Record("source", "make %d", i)
+						if !yield(i) {
+							return
+						}
+					}
+				})
+				if c.buffer > 0 {
+					values = pipeline.Buffer(p, values, c.buffer)
+				}
+				for v := range values {
+					time.Sleep(time.Second)
+					tl.Record("main", "done with %d", v)
+				}
+				require.NoError(t, p.Wait())
+
+				t.Log("\n" + tl.String())
+				require.Equal(t, c.want, tl.String())
+			})
+		})
+	}
+}
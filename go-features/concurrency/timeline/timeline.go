@@ -0,0 +1,135 @@
+// Package timeline records what goroutines do and when, for lessons to show a timeline of sends, receives and
+// cancellations.
+//
+// Times are measured from the creation of the timeline with time.Now. In a testing/synctest bubble, that is the fake
+// clock of the bubble: time only moves when every goroutine is blocked, so events get exact times, e.g. 50ms rather
+// than 50.21ms, and a lesson can assert its whole timeline.
+//
+//	synctest.Test(t, func(t *testing.T) {
+//		tl := timeline.New()
+//		ch := make(chan int)
+//		go func() {
+//			defer close(ch)
+//			time.Sleep(time.Second)
+//			timeline.Send(tl, "producer", ch, 1)
+//		}()
+//		timeline.Drain(tl, "main", ch)
+//		t.Log(tl)
+//	})
+package timeline
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of an event. Events at the same instant are ordered by kind, causes before effects: the scheduler
+// runs the goroutines woken at an instant in any order, and their events would be recorded in any order too.
+type Kind int
+
+const (
+	KindNote    Kind = iota // anything else, see Timeline.Record, first at an instant
+	KindCancel              // a context done
+	KindSend                // a value sent on a channel
+	KindReceive             // a value received from a channel
+	KindClose               // a channel closed, seen by its receiver
+)
+
+// Event is something a goroutine did, At some time after the start of the timeline.
+type Event struct {
+	At   time.Duration
+	Kind Kind
+	Who  string
+	What string
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%6s %-8s %s", e.At, e.Who, e.What)
+}
+
+// Timeline is a list of events. Its methods can be called from any goroutine.
+type Timeline struct {
+	start  time.Time
+	mu     sync.Mutex
+	events []Event
+}
+
+// New starts a timeline now.
+func New() *Timeline {
+	return &Timeline{start: time.Now()}
+}
+
+// Record records a note from who.
+func (tl *Timeline) Record(who, format string, args ...any) {
+	tl.add(KindNote, who, fmt.Sprintf(format, args...))
+}
+
+func (tl *Timeline) add(kind Kind, who, what string) {
+	at := time.Since(tl.start)
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.events = append(tl.events, Event{At: at, Kind: kind, Who: who, What: what})
+}
+
+// Events returns the events recorded so far, by time, then by kind, then in the order they were recorded.
+func (tl *Timeline) Events() []Event {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	events := slices.Clone(tl.events)
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return cmp.Or(cmp.Compare(a.At, b.At), cmp.Compare(a.Kind, b.Kind))
+	})
+	return events
+}
+
+// String returns the events, one per line, like ` 100ms main     receive 1`.
+func (tl *Timeline) String() string {
+	var b strings.Builder
+	for _, e := range tl.Events() {
+		b.WriteString(e.String() + "\n")
+	}
+	return b.String()
+}
+
+// Watch records when ctx is done, as who, with the cause, e.g. `canceled: context deadline exceeded`. The event is
+// recorded by a goroutine of its own: in a bubble, synctest.Wait makes sure it was before reading the timeline. The
+// returned function stops watching, it returns false if ctx was done already.
+func (tl *Timeline) Watch(ctx context.Context, who string) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		tl.add(KindCancel, who, "canceled: "+context.Cause(ctx).Error())
+	})
+}
+
+// Send sends v on ch, and records it once sent.
+func Send[T any](tl *Timeline, who string, ch chan<- T, v T) {
+	ch <- v
+	tl.add(KindSend, who, fmt.Sprint("send ", v))
+}
+
+// Receive receives a value from ch, and records it, or that ch is closed.
+func Receive[T any](tl *Timeline, who string, ch <-chan T) (T, bool) {
+	v, ok := <-ch
+	if ok {
+		tl.add(KindReceive, who, fmt.Sprint("receive ", v))
+	} else {
+		tl.add(KindClose, who, "closed")
+	}
+	return v, ok
+}
+
+// Drain receives from ch until it is closed, recording each value, and returns them.
+func Drain[T any](tl *Timeline, who string, ch <-chan T) []T {
+	var vs []T
+	for {
+		v, ok := Receive(tl, who, ch)
+		if !ok {
+			return vs
+		}
+		vs = append(vs, v)
+	}
+}
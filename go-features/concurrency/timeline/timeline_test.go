@@ -0,0 +1,54 @@
+package timeline
+
+import (
+	"context"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		tl := New()
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		tl.Watch(ctx, "ctx")
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			tl.Record("producer", "start")
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+					Send(tl, "producer", ch, i)
+				}
+			}
+		}()
+
+		require.Equal(t, []int{0, 1}, Drain(tl, "main", ch))
+		synctest.Wait()
+		require.Equal(t, ""+
+			"    0s producer start\n"+
+			"    1s producer send 0\n"+
+			"    1s main     receive 0\n"+
+			"    2s producer send 1\n"+
+			"    2s main     receive 1\n"+
+			"  2.5s ctx      canceled: context deadline exceeded\n"+
+			"  2.5s main     closed\n", tl.String())
+	})
+}
+
+func TestWatchStopped(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		tl := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		require.True(t, tl.Watch(ctx, "ctx")())
+		cancel()
+		synctest.Wait()
+		require.Empty(t, tl.Events())
+	})
+}
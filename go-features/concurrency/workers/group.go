@@ -0,0 +1,85 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Group is a group of goroutines working on parts of the same task, like golang.org/x/sync/errgroup: Wait returns
+// the first error, and the first error cancels the context of the group. The zero value is a group without limit and
+// without context.
+type Group struct {
+	cancel func(error)
+	wg     sync.WaitGroup
+	sem    chan struct{} // a token per running goroutine, nil without limit
+	once   sync.Once
+	err    error
+}
+
+// WithContext returns a group, and a context cancelled by its first error or when Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the group to n goroutines at once, no limit when n is negative. It panics if goroutines are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("workers: SetLimit with %d goroutines running", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a goroutine, waiting for one of the running ones to return when the group is at its limit.
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(g.sem, f)
+}
+
+// TryGo runs f in a goroutine if the group is below its limit, and reports whether it did.
+func (g *Group) TryGo(f func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(g.sem, f)
+	return true
+}
+
+// start runs f in a goroutine, which gives its token back to sem when done.
+func (g *Group) start(sem chan struct{}, f func() error) {
+	g.wg.Go(func() {
+		defer func() {
+			if sem != nil {
+				<-sem
+			}
+		}()
+		if err := f(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel(err)
+				}
+			})
+		}
+	})
+}
+
+// Wait waits for the goroutines of the group to return, and returns the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
@@ -0,0 +1,105 @@
+package workers
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Semaphore is a budget, e.g. of bytes or connections, that work takes a weight of while it runs. Waiters are served
+// in order: a heavy waiter isn't starved by light ones that would fit, the ones after it wait too.
+type Semaphore struct {
+	size    int64
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List // of waiter
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{} // closed when the weight is acquired
+}
+
+// NewSemaphore returns a semaphore with a budget of size.
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire takes n out of the budget, waiting for it unless ctx is done first. A weight above the size of the
+// semaphore waits for ctx.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	if n > s.size { // never fits, it would only hold back the waiters after it
+		s.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	w := waiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready: // acquired meanwhile: give it back
+			s.cur -= n
+			s.notify()
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if front { // the waiters after it may fit now
+				s.notify()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes n out of the budget if it is there and nobody is waiting, without waiting.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release gives n back to the budget. It panics when releasing more than was acquired.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic(fmt.Sprintf("workers: released %d more than acquired", -s.cur))
+	}
+	s.notify()
+}
+
+// notify gives their weight to the waiters that fit, in order, up to the first one that doesn't.
+func (s *Semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(waiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
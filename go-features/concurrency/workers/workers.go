@@ -0,0 +1,75 @@
+// Package workers bounds how much work runs at once: a Pool of n goroutines taking values from a queue, a Semaphore
+// where each piece of work takes a weight out of a budget, and a Group of goroutines with an optional limit, whose
+// first error cancels the others.
+//
+// Starting one goroutine per item is the first thing to try, goroutines are cheap. What isn't is what they hold: a
+// connection each, a buffer each, a slot of a rate limited API each. With a million items the goroutines are a few
+// GB of stacks, and the million connections are an outage. These types keep the shape of that code, and bound it.
+package workers
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the result of a value of a Pool.
+type Result[In, Out any] struct {
+	In  In
+	Out Out
+	Err error
+}
+
+// Pool runs a function on the values submitted to it, in a fixed number of goroutines.
+//
+// Submit blocks while the queue is full and every worker busy, which slows down the caller to the pace of the
+// workers. A queue of one lets the caller submit the next value while the workers finish the previous ones, as the
+// one-sized buffered channel of Test_makingBlockingCalls lets its producer send while the reader is busy.
+//
+// Results must be received while submitting: a worker waits to send its result, and while it does, it takes no new
+// values.
+type Pool[In, Out any] struct {
+	queue   chan In
+	results chan Result[In, Out]
+	wg      sync.WaitGroup
+}
+
+// NewPool starts n workers running f, with a queue of the given size.
+func NewPool[In, Out any](n, queue int, f func(In) (Out, error)) *Pool[In, Out] {
+	p := &Pool[In, Out]{
+		queue:   make(chan In, queue),
+		results: make(chan Result[In, Out]),
+	}
+	for range n {
+		p.wg.Go(func() {
+			for v := range p.queue {
+				out, err := f(v)
+				p.results <- Result[In, Out]{In: v, Out: out, Err: err}
+			}
+		})
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.results)
+	}()
+	return p
+}
+
+// Submit queues v, waiting for room in the queue unless ctx is done first.
+func (p *Pool[In, Out]) Submit(ctx context.Context, v In) error {
+	select {
+	case p.queue <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close tells the workers that nothing more will be submitted. Results is closed once they are done with the queue.
+func (p *Pool[In, Out]) Close() {
+	close(p.queue)
+}
+
+// Results returns the results, in the order the workers are done.
+func (p *Pool[In, Out]) Results() <-chan Result[In, Out] {
+	return p.results
+}
@@ -0,0 +1,198 @@
+package workers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/internal/concurrencytest"
+	"github.com/juan-carvajal/go-dojo/internal/leak"
+)
+
+func TestMain(m *testing.M) { leak.Main(m) }
+
+func TestPool(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var busy concurrencytest.Peak
+		start := time.Now()
+		p := NewPool(3, 1, func(v int) (int, error) {
+			busy.Hold(1, time.Second)
+			if v == 4 {
+				return 0, concurrencytest.ErrBoom
+			}
+			return v * v, nil
+		})
+		submitted := make(chan error, 1)
+		go func() {
+			defer p.Close()
+			for i := range 9 {
+				if err := p.Submit(context.Background(), i); err != nil {
+					submitted <- err
+					return
+				}
+			}
+			submitted <- nil
+		}()
+
+		var outs []int
+		for r := range p.Results() {
+			if r.In == 4 {
+				require.ErrorIs(t, r.Err, concurrencytest.ErrBoom)
+				continue
+			}
+			require.NoError(t, r.Err)
+			require.Equal(t, r.In*r.In, r.Out)
+			outs = append(outs, r.Out)
+		}
+		slices.Sort(outs)
+		require.NoError(t, <-submitted)
+		require.Equal(t, []int{0, 1, 4, 9, 25, 36, 49, 64}, outs)
+		require.EqualValues(t, 3, busy.Max())
+		require.Equal(t, 3*time.Second, time.Since(start))
+	})
+}
+
+func TestPoolSubmitCancelled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		release := make(chan struct{})
+		p := NewPool(1, 1, func(v int) (int, error) {
+			<-release
+			return v, nil
+		})
+		require.NoError(t, p.Submit(context.Background(), 1)) // taken by the worker
+		require.NoError(t, p.Submit(context.Background(), 2)) // queued
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.ErrorIs(t, p.Submit(ctx, 3), context.DeadlineExceeded)
+
+		close(release)
+		p.Close()
+		var ins []int
+		for r := range p.Results() {
+			ins = append(ins, r.In)
+		}
+		require.Equal(t, []int{1, 2}, ins)
+	})
+}
+
+func TestSemaphore(t *testing.T) {
+	s := NewSemaphore(10)
+	require.NoError(t, s.Acquire(context.Background(), 6))
+	require.True(t, s.TryAcquire(4))
+	require.False(t, s.TryAcquire(1))
+	s.Release(10)
+	require.True(t, s.TryAcquire(10))
+	s.Release(10)
+	require.PanicsWithValue(t, "workers: released 1 more than acquired", func() { s.Release(1) })
+}
+
+func TestSemaphoreOrder(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewSemaphore(10)
+		require.NoError(t, s.Acquire(context.Background(), 8))
+		acquired := make(chan error, 2)
+		acquire := func(n int64) { acquired <- s.Acquire(context.Background(), n) }
+		go acquire(5) // waits for 5, held back by the 8
+		synctest.Wait()
+		go acquire(1) // would fit, but waits behind the 5
+		synctest.Wait()
+		require.False(t, s.TryAcquire(1))
+		require.Empty(t, acquired)
+
+		s.Release(8)
+		require.NoError(t, <-acquired)
+		require.NoError(t, <-acquired)
+		require.True(t, s.TryAcquire(4))
+		s.Release(10)
+	})
+}
+
+func TestSemaphoreCancelled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewSemaphore(10)
+		require.NoError(t, s.Acquire(context.Background(), 8))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		acquired := make(chan error)
+		go func() { acquired <- s.Acquire(context.Background(), 2) }()
+		synctest.Wait()
+		require.ErrorIs(t, s.Acquire(ctx, 5), context.DeadlineExceeded) // the 2 arrived first, and got its weight
+		require.NoError(t, <-acquired)
+
+		go func() { acquired <- s.Acquire(context.Background(), 1) }()
+		synctest.Wait()
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() { acquired <- s.Acquire(ctx, 3) }() // holds back the 1 behind it, until it gives up
+		synctest.Wait()
+		require.ErrorIs(t, s.Acquire(ctx, 11), context.DeadlineExceeded)
+		require.ErrorIs(t, <-acquired, context.DeadlineExceeded)
+		s.Release(10)
+		require.NoError(t, <-acquired)
+		s.Release(1)
+	})
+}
+
+func TestGroup(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		g, ctx := WithContext(context.Background())
+		g.Go(func() error {
+			time.Sleep(time.Second)
+			return concurrencytest.ErrBoom
+		})
+		g.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.ErrorIs(t, g.Wait(), concurrencytest.ErrBoom)
+		require.ErrorIs(t, context.Cause(ctx), concurrencytest.ErrBoom)
+
+		g, ctx = WithContext(context.Background())
+		g.Go(func() error { return nil })
+		require.NoError(t, g.Wait())
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+		var zero Group
+		zero.Go(func() error { return concurrencytest.ErrBoom })
+		require.ErrorIs(t, zero.Wait(), concurrencytest.ErrBoom)
+	})
+}
+
+func TestGroupLimit(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var busy concurrencytest.Peak
+		var g Group
+		g.SetLimit(2)
+		start := time.Now()
+		for i := range 5 {
+			g.Go(func() error {
+				busy.Hold(1, time.Second)
+				return nil
+			})
+			if i == 1 {
+				require.False(t, g.TryGo(func() error { return nil }))
+				require.PanicsWithError(t, "workers: SetLimit with 2 goroutines running", func() { g.SetLimit(3) })
+			}
+		}
+		require.NoError(t, g.Wait())
+		require.EqualValues(t, 2, busy.Max())
+		require.Equal(t, 3*time.Second, time.Since(start))
+
+		require.True(t, g.TryGo(func() error { return nil }))
+		require.NoError(t, g.Wait())
+		g.SetLimit(-1)
+		for range 5 {
+			g.Go(func() error {
+				busy.Hold(1, time.Second)
+				return nil
+			})
+		}
+		require.NoError(t, g.Wait())
+		require.EqualValues(t, 5, busy.Max())
+	})
+}
@@ -0,0 +1,271 @@
+package concurrency
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/internal/concurrencytest"
+	"github.com/juan-carvajal/go-dojo/go-features/concurrency/workers"
+)
+
+// Test_workerPool contrasts a goroutine per item with a pool of workers. Each item holds a connection for 1s: with a
+// goroutine per item, 100 items hold 100 connections at once, and 100k would hold 100k, to a database that accepts
+// a few hundred. The results also need a mutex, or a slice indexed by item.
+//
+// A pool of 4 workers holds 4 connections at most, whatever the number of items, and takes 25s instead of 1s: the
+// bound is the point. Submit waits while the workers are busy and the queue full. The queue of one is the one-sized
+// buffered channel of Test_makingBlockingCalls: the caller submits the next item while the workers finish theirs.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,memory requires=Test_makingBlockingCalls
+func Test_workerPool(t *testing.T) {
+	const items = 100
+	synctest.Test(t, func(t *testing.T) {
+		var conns concurrencytest.Peak
+		start := time.Now()
+		var mu sync.Mutex
+		var results []int
+		var wg sync.WaitGroup
+		for i := range items {
+			wg.Go(func() {
+				conns.Hold(1, time.Second)
+				mu.Lock()
+				defer mu.Unlock()
+				results = append(results, i)
+			})
+		}
+		wg.Wait()
+		t.Logf("goroutine per item: %d results in %v, %d connections at once", len(results), time.Since(start), conns.Max())
+		require.Len(t, results, items)
+		require.Equal(t, time.Second, time.Since(start))
+		require.EqualValues(t, items, conns.Max())
+	})
+
+	synctest.Test(t, func(t *testing.T) {
+		var conns concurrencytest.Peak
+		start := time.Now()
+		pool := workers.NewPool(4, 1, func(i int) (int, error) {
+			conns.Hold(1, time.Second)
+			return i, nil
+		})
+		go func() {
+			defer pool.Close()
+			for i := range items {
+				if err := pool.Submit(context.Background(), i); err != nil {
+					return
+				}
+			}
+		}()
+		var results []int
+		for r := range pool.Results() {
+			results = append(results, r.Out)
+		}
+		t.Logf("pool of 4: %d results in %v, %d connections at once", len(results), time.Since(start), conns.Max())
+		require.Len(t, results, items)
+		require.Equal(t, 25*time.Second, time.Since(start))
+		require.EqualValues(t, 4, conns.Max())
+	})
+}
+
+// Test_weightedSemaphore bounds work by what it costs rather than by count. Jobs need 60, 30, 30, 80, 10 and 40MB
+// for 1s: with a goroutine per job, they need 250MB at once. A pool bounds the count only: with 2 workers, 60 and 80MB
+// can still run together.
+//
+// A semaphore of 100MB makes each job acquire its size before it starts, and release it when done. Acquiring in the
+// loop, before starting the goroutine, starts the jobs in order: the second 30MB waits for room, and the jobs behind
+// it wait too, even the 10MB that would fit, so that a big job isn't starved by small ones. It takes 4s, never using
+// more than 90MB.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency,memory requires=Test_workerPool
+func Test_weightedSemaphore(t *testing.T) {
+	sizes := []int64{60, 30, 30, 80, 10, 40}
+	synctest.Test(t, func(t *testing.T) {
+		var mem concurrencytest.Peak
+		start := time.Now()
+		var wg sync.WaitGroup
+		for _, size := range sizes {
+			wg.Go(func() { mem.Hold(size, time.Second) })
+		}
+		wg.Wait()
+		t.Logf("goroutine per job: %v, %dMB at once", time.Since(start), mem.Max())
+		require.EqualValues(t, 250, mem.Max())
+	})
+
+	synctest.Test(t, func(t *testing.T) {
+		var mem concurrencytest.Peak
+		start := time.Now()
+		sem := workers.NewSemaphore(100)
+		var wg sync.WaitGroup
+		for _, size := range sizes {
+			require.NoError(t, sem.Acquire(context.Background(), size))
+			wg.Go(func() {
+				defer sem.Release(size)
+				mem.Hold(size, time.Second)
+			})
+		}
+		wg.Wait()
+		t.Logf("semaphore of 100MB: %v, %dMB at once", time.Since(start), mem.Max())
+		require.Equal(t, 4*time.Second, time.Since(start))
+		require.EqualValues(t, 90, mem.Max())
+	})
+}
+
+var errNotFound = errors.New("not found")
+
+// Test_groupSetLimit contrasts a sync.WaitGroup with a group, as golang.org/x/sync/errgroup has. Fetching 10 pages
+// takes 1s each, and page 2 isn't found after 500ms. With a WaitGroup, the error needs a variable and a mutex of its
+// own, and nothing tells the other goroutines: the 9 other pages are fetched, for nothing.
+//
+// A group returns the first error from Wait, and cancels its context with it: the fetches watching the context stop.
+// SetLimit(3) runs 3 at a time, Go waiting for one of them to return before starting the next: after the error, the
+// next pages start with a cancelled context and return at once. No page is fetched, and it takes 500ms.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=concurrency requires=Test_workerPool,Test_makingBlockingCallsWithCancelledContext
+func Test_groupSetLimit(t *testing.T) {
+	fetch := func(ctx context.Context, page int, fetched *atomic.Int32) error {
+		d := time.Second
+		if page == 2 {
+			d = 500 * time.Millisecond
+		}
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if page == 2 {
+			return fmt.Errorf("page %d: %w", page, errNotFound)
+		}
+		fetched.Add(1)
+		return nil
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		var fetched atomic.Int32
+		start := time.Now()
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var firstErr error
+		for page := range 10 {
+			wg.Go(func() {
+				if err := fetch(context.Background(), page, &fetched); err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+			})
+		}
+		wg.Wait()
+		t.Logf("WaitGroup: %v after %v, %d pages fetched", firstErr, time.Since(start), fetched.Load())
+		require.ErrorIs(t, firstErr, errNotFound)
+		require.EqualValues(t, 9, fetched.Load())
+	})
+
+	synctest.Test(t, func(t *testing.T) {
+		var fetched atomic.Int32
+		start := time.Now()
+		g, ctx := workers.WithContext(context.Background())
+		g.SetLimit(3)
+		for page := range 10 {
+			g.Go(func() error { return fetch(ctx, page, &fetched) })
+		}
+		err := g.Wait()
+		t.Logf("Group: %v after %v, %d pages fetched", err, time.Since(start), fetched.Load())
+		require.EqualError(t, err, "page 2: not found")
+		require.Equal(t, 500*time.Millisecond, time.Since(start))
+		require.Zero(t, fetched.Load())
+	})
+}
+
+// hashItems is how many items the benchmarks hash, each with hashItem.
+const hashItems = 1000
+
+// hashItem is CPU bound work, tens of µs: hashing 16KB.
+func hashItem(i int) [sha256.Size]byte {
+	var b [16 << 10]byte
+	b[0] = byte(i)
+	return sha256.Sum256(b[:])
+}
+
+// BenchmarkWorkers hashes 1000 items in one goroutine, in a goroutine per item, and bounded to GOMAXPROCS goroutines
+// by each of the workers package, for GOMAXPROCS of 1, 2, 4 and 8:
+//
+//	go test -run '^$' -bench Workers ./go-features/concurrency/
+//
+// With CPU bound work, more goroutines than GOMAXPROCS only wait for a CPU: the bounded ones get the speedup of a
+// goroutine per item. The pool allocates the least, its goroutines are started once per op; the semaphore and the
+// group still start a goroutine per item, fewer at once. With GOMAXPROCS=1 every concurrent one is slower than a
+// single goroutine, it only adds scheduling, and GOMAXPROCS above runtime.NumCPU() adds nothing.
+func BenchmarkWorkers(b *testing.B) {
+	for _, procs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("GOMAXPROCS=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			b.Run("sequential", func(b *testing.B) {
+				for b.Loop() {
+					for i := range hashItems {
+						hashItem(i)
+					}
+				}
+			})
+			b.Run("goroutine per item", func(b *testing.B) {
+				for b.Loop() {
+					var wg sync.WaitGroup
+					for i := range hashItems {
+						wg.Go(func() { hashItem(i) })
+					}
+					wg.Wait()
+				}
+			})
+			b.Run("pool", func(b *testing.B) {
+				for b.Loop() {
+					pool := workers.NewPool(procs, 1, func(i int) ([sha256.Size]byte, error) { return hashItem(i), nil })
+					go func() {
+						defer pool.Close()
+						for i := range hashItems {
+							_ = pool.Submit(context.Background(), i)
+						}
+					}()
+					for range pool.Results() {
+					}
+				}
+			})
+			b.Run("semaphore", func(b *testing.B) {
+				for b.Loop() {
+					sem := workers.NewSemaphore(int64(procs))
+					var wg sync.WaitGroup
+					for i := range hashItems {
+						_ = sem.Acquire(context.Background(), 1)
+						wg.Go(func() {
+							defer sem.Release(1)
+							hashItem(i)
+						})
+					}
+					wg.Wait()
+				}
+			})
+			b.Run("group", func(b *testing.B) {
+				for b.Loop() {
+					var g workers.Group
+					g.SetLimit(procs)
+					for i := range hashItems {
+						g.Go(func() error {
+							hashItem(i)
+							return nil
+						})
+					}
+					_ = g.Wait()
+				}
+			})
+		})
+	}
+}
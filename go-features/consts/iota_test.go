@@ -3,6 +3,8 @@ package consts
 import "fmt"
 
 // Example_iotaIdentity shows the basic usage of iota.
+//
+//dojo:meta difficulty=beginner minutes=2 tags=basics,spec
 func Example_iotaIdentity() {
 	const (
 		c0 = iota // c0 == 0
@@ -20,6 +22,8 @@ func Example_iotaIdentity() {
 
 // Example_iotaSkip shows that you can skip iota "rows", meaning not use the iota in that row and override the with something else.
 // The count will be incremented as normal in the next row.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=spec requires=Example_iotaIdentity
 func Example_iotaSkip() {
 	const (
 		a = 1 << iota // a == 1  (iota == 0)
@@ -39,6 +43,8 @@ func Example_iotaSkip() {
 }
 
 // Example_iotaCustomIncrement shows that you can use a custom expression to increment, for example a combination of bitwise + multiplication.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=spec requires=Example_iotaSkip
 func Example_iotaCustomIncrement() {
 	type byteSize float64
 
@@ -67,6 +73,8 @@ func Example_iotaCustomIncrement() {
 }
 
 // Example_iotaMixedTypes shows that you can define mixed types with iota expressions.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=spec,pitfall requires=Example_iotaIdentity
 func Example_iotaMixedTypes() {
 	const (
 		u         = iota * 42 // u == 0     (untyped integer constant)
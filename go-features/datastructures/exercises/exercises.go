@@ -0,0 +1,22 @@
+//go:build !solution
+
+// Package exercises is the datastructures kata track. Replace every `panic("TODO")` with an implementation and run
+// `go run ./cmd/dojo check datastructures` to grade it.
+package exercises
+
+// AppendCopy returns s with v appended, without ever writing to the backing array of s: the caller may still be
+// using it through other slices, as in datastructures.Example_sliceBehavior.
+func AppendCopy(s []int, v int) []int {
+	panic("TODO")
+}
+
+// SetDefault stores v under k unless k is already present, and returns the map. It must work on a nil map (see
+// datastructures.Example_allowedMapOperations).
+func SetDefault(m map[string]int, k string, v int) map[string]int {
+	panic("TODO")
+}
+
+// Dedup returns the distinct values of s in order of first appearance. s must not be modified.
+func Dedup(s []string) []string {
+	panic("TODO")
+}
@@ -0,0 +1,46 @@
+package exercises
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+)
+
+func Test_AppendCopy(t *testing.T) {
+	kata.Attempt(t, func() {
+		backing := []int{0, 1, 2, 3}
+		s := backing[:2] // len 2, cap 4: a plain append would overwrite backing[2]
+
+		got := AppendCopy(s, 99)
+		require.Equal(t, []int{0, 1, 99}, got)
+		require.Equal(t, []int{0, 1, 2, 3}, backing, "the backing array of s was modified")
+
+		got[0] = 42
+		require.Equal(t, 0, s[0], "the result shares its backing array with s")
+
+		require.Equal(t, []int{1}, AppendCopy(nil, 1))
+	})
+}
+
+func Test_SetDefault(t *testing.T) {
+	kata.Attempt(t, func() {
+		var m map[string]int
+		m = SetDefault(m, "a", 1)
+		require.Equal(t, map[string]int{"a": 1}, m)
+
+		m = SetDefault(m, "a", 2)
+		m = SetDefault(m, "b", 0)
+		require.Equal(t, map[string]int{"a": 1, "b": 0}, m)
+	})
+}
+
+func Test_Dedup(t *testing.T) {
+	kata.Attempt(t, func() {
+		s := []string{"b", "a", "b", "c", "a"}
+		require.Equal(t, []string{"b", "a", "c"}, Dedup(s))
+		require.Equal(t, []string{"b", "a", "b", "c", "a"}, s, "the input was modified")
+		require.Empty(t, Dedup(nil))
+	})
+}
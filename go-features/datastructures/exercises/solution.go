@@ -0,0 +1,37 @@
+//go:build solution
+
+package exercises
+
+import "slices"
+
+// AppendCopy returns s with v appended, without ever writing to the backing array of s: the caller may still be
+// using it through other slices, as in datastructures.Example_sliceBehavior.
+func AppendCopy(s []int, v int) []int {
+	return append(slices.Clip(s), v) // with len == cap, append has to allocate a new backing array
+}
+
+// SetDefault stores v under k unless k is already present, and returns the map. It must work on a nil map (see
+// datastructures.Example_allowedMapOperations).
+func SetDefault(m map[string]int, k string, v int) map[string]int {
+	if m == nil {
+		m = make(map[string]int) // writing to a nil map panics
+	}
+	if _, ok := m[k]; !ok {
+		m[k] = v
+	}
+	return m
+}
+
+// Dedup returns the distinct values of s in order of first appearance. s must not be modified.
+func Dedup(s []string) []string {
+	seen := make(map[string]struct{}, len(s))
+	out := make([]string, 0, len(s)) // a new backing array, filtering in place would modify s
+	for _, v := range s {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
@@ -9,6 +9,8 @@ func recoverFromPanic() {
 }
 
 // Example_allowedMapOperations shows what operations are allowed on a nil map.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=pitfall,runtime
 func Example_allowedMapOperations() {
 	var m map[string]string // nil map
 	delete(m, "")           // allowed on nil map
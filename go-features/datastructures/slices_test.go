@@ -17,6 +17,8 @@ import "fmt"
 // underlying array with a new capacity according to this function (`growslice`): https://github.com/golang/go/blob/master/src/runtime/slice.go#L177
 //
 // [Go container's article]: https://go101.org/article/container.html
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=memory,pitfall
 func Example_sliceBehavior() {
 	a := [...]int{0, 1, 2, 3, 4, 5, 6}
 	s0 := a[:]     // <=> s0 := a[0:7:7]
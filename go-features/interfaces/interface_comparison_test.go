@@ -4,6 +4,8 @@ import "fmt"
 
 // Example_interfaceNilComparison shows how nil interface comparisons work.
 // An interface is nil only when both its type and value are nil.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=pitfall,spec requires=Example_interfaceEquality
 func Example_interfaceNilComparison() {
 	var i interface{}
 	fmt.Println(i == nil) // true: both type and value are nil
@@ -21,6 +23,8 @@ func Example_interfaceNilComparison() {
 
 // Example_interfaceEquality shows basic interface equality comparisons.
 // Two interfaces are equal if they have identical dynamic types and equal dynamic values.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=basics,spec
 func Example_interfaceEquality() {
 	var a, b interface{}
 
@@ -44,6 +48,8 @@ func Example_interfaceEquality() {
 
 // Example_interfaceComparablePanic shows that comparing interfaces containing
 // non-comparable types causes a runtime panic.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=pitfall,runtime requires=Example_interfaceEquality
 func Example_interfaceComparablePanic() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -64,6 +70,8 @@ func Example_interfaceComparablePanic() {
 
 // Example_interfacePointerComparison shows how pointer values are compared
 // when stored in interfaces.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=spec requires=Example_interfaceEquality
 func Example_interfacePointerComparison() {
 	x := 42
 	y := 42
@@ -84,6 +92,8 @@ func Example_interfacePointerComparison() {
 
 // Example_interfaceTypeAssertion shows how type assertions interact with
 // comparisons and nil checking.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=spec requires=Example_interfaceNilComparison
 func Example_interfaceTypeAssertion() {
 	var i interface{} = (*int)(nil)
 
@@ -101,6 +111,8 @@ func Example_interfaceTypeAssertion() {
 
 // Example_interfaceStructComparison shows how structs are compared when
 // stored in interfaces.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=spec requires=Example_interfaceEquality
 func Example_interfaceStructComparison() {
 	type Point struct {
 		X, Y int
@@ -122,6 +134,8 @@ func Example_interfaceStructComparison() {
 
 // Example_interfaceDifferentTypes shows comparison behavior with different
 // concrete types stored in interfaces.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=pitfall,spec requires=Example_interfaceEquality
 func Example_interfaceDifferentTypes() {
 	type MyInt int
 
@@ -141,6 +155,8 @@ func Example_interfaceDifferentTypes() {
 
 // Example_interfaceEmptyVsNil shows the distinction between empty interfaces
 // holding values versus being nil.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=spec requires=Example_interfaceNilComparison
 func Example_interfaceEmptyVsNil() {
 	var i interface{}
 	fmt.Println(i == nil) // true
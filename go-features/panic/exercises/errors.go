@@ -0,0 +1,6 @@
+package exercises
+
+import "errors"
+
+// ErrPanicked is wrapped by the errors converted from panics.
+var ErrPanicked = errors.New("panicked")
@@ -0,0 +1,23 @@
+//go:build !solution
+
+// Package exercises is the panic kata track. Replace every `panic("TODO")` with an implementation and run
+// `go run ./cmd/dojo check panic` to grade it.
+package exercises
+
+// SafeCall calls f and turns a panic into an error wrapping ErrPanicked. It returns nil when f returns normally.
+func SafeCall(f func()) error {
+	panic("TODO")
+}
+
+// Go runs f in a new goroutine and returns a channel receiving the outcome of f: nil, or an error wrapping
+// ErrPanicked if f panicked. The channel is closed afterwards. A panic in f must not crash the program
+// (see panic.Example_panicInGoroutine).
+func Go(f func()) <-chan error {
+	panic("TODO")
+}
+
+// CleanupAll calls every cleanup function in reverse order, like deferred calls, even when some of them panic.
+// It returns the panics as errors wrapping ErrPanicked, joined in the order they happened.
+func CleanupAll(cleanups ...func()) error {
+	panic("TODO")
+}
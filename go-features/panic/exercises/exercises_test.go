@@ -0,0 +1,54 @@
+package exercises
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+)
+
+func Test_SafeCall(t *testing.T) {
+	kata.Attempt(t, func() {
+		require.NoError(t, SafeCall(func() {}))
+
+		err := SafeCall(func() { panic("boom") })
+		require.ErrorIs(t, err, ErrPanicked)
+		require.ErrorContains(t, err, "boom")
+
+		err = SafeCall(func() { panic(errors.ErrUnsupported) })
+		require.ErrorIs(t, err, ErrPanicked)
+		require.ErrorContains(t, err, errors.ErrUnsupported.Error())
+	})
+}
+
+func Test_Go(t *testing.T) {
+	kata.Attempt(t, func() {
+		errs := Go(func() { panic("Something went wrong in the worker!") })
+		err := <-errs
+		require.ErrorIs(t, err, ErrPanicked)
+		_, ok := <-errs
+		require.False(t, ok, "the channel must be closed")
+
+		ran := false
+		require.NoError(t, <-Go(func() { ran = true }))
+		require.True(t, ran)
+	})
+}
+
+func Test_CleanupAll(t *testing.T) {
+	kata.Attempt(t, func() {
+		var order []string
+		err := CleanupAll(
+			func() { order = append(order, "first"); panic("panic first") },
+			func() { order = append(order, "second"); panic("panic second") },
+			func() { order = append(order, "third") },
+		)
+		require.Equal(t, []string{"third", "second", "first"}, order)
+		require.ErrorIs(t, err, ErrPanicked)
+		require.EqualError(t, err, "panicked: panic second\npanicked: panic first")
+
+		require.NoError(t, CleanupAll())
+	})
+}
@@ -0,0 +1,43 @@
+//go:build solution
+
+package exercises
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SafeCall calls f and turns a panic into an error wrapping ErrPanicked. It returns nil when f returns normally.
+func SafeCall(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanicked, r) // a named result is the only way to change what is returned
+		}
+	}()
+	f()
+	return nil
+}
+
+// Go runs f in a new goroutine and returns a channel receiving the outcome of f: nil, or an error wrapping
+// ErrPanicked if f panicked. The channel is closed afterwards. A panic in f must not crash the program
+// (see panic.Example_panicInGoroutine).
+func Go(f func()) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		ch <- SafeCall(f) // recover only works in the goroutine that panicked
+	}()
+	return ch
+}
+
+// CleanupAll calls every cleanup function in reverse order, like deferred calls, even when some of them panic.
+// It returns the panics as errors wrapping ErrPanicked, joined in the order they happened.
+func CleanupAll(cleanups ...func()) error {
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := SafeCall(cleanups[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
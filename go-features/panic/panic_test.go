@@ -12,6 +12,8 @@ func recoverFromPanic() {
 }
 
 // Example_recoverFromPanic shows how panics can be created explicitly and recovered using the `recover` function.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=basics,runtime
 func Example_recoverFromPanic() {
 	defer recoverFromPanic()
 	panic("Oops! Something went wrong!")
@@ -19,6 +21,8 @@ func Example_recoverFromPanic() {
 }
 
 // Example_panicStoppingExecution shows how a panic will not allow the program to continue after it has been triggered (except with deferred functions).
+//
+//dojo:meta difficulty=beginner minutes=2 tags=runtime requires=Example_recoverFromPanic
 func Example_panicStoppingExecution() {
 	defer recoverFromPanic()
 	panic("initial panic")
@@ -27,6 +31,8 @@ func Example_panicStoppingExecution() {
 }
 
 // Example_panicChain shows how panic chains work with defer functions.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=runtime requires=Example_panicStoppingExecution
 func Example_panicChain() {
 	defer recoverFromPanic()
 	defer func() {
@@ -41,6 +47,8 @@ func Example_panicChain() {
 }
 
 // Example_panicChain2 shows how the deferred functions up to the point where the program panic initially will always have a chance to run, even if another deferred function panics.
+//
+//dojo:meta difficulty=advanced minutes=5 tags=pitfall,runtime requires=Example_panicChain
 func Example_panicChain2() {
 	defer recoverFromPanic()
 	defer func() {
@@ -64,6 +72,8 @@ func Example_panicChain2() {
 }
 
 // Example_panicInGoroutine shows code that would crash because the `recover` mechanism works at the goroutine level only. Each goroutine needs to handle it's panic stack.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=concurrency,pitfall requires=Example_recoverFromPanic
 func Example_panicInGoroutine() {
 	//defer recoverFromPanic()
 	//var wg sync.WaitGroup
@@ -79,6 +89,8 @@ func Example_panicInGoroutine() {
 }
 
 // Example_panicInGoroutineGracefully shows how to handle panics inside a goroutine.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,runtime requires=Example_panicInGoroutine
 func Example_panicInGoroutineGracefully() {
 	var wg sync.WaitGroup
 	wg.Add(1)
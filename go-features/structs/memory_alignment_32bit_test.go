@@ -0,0 +1,68 @@
+//go:build 386 || arm || mips || mipsle
+
+package structs
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Example_structMemoryAlignment shows how the memory alignment constraints will affect struct size at runtime, on
+// 32-bit architectures where int64 is only 4-byte aligned. See memory_alignment_test.go for the 64-bit lesson and the
+// size and alignment guarantees behind both.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=memory,spec
+func Example_structMemoryAlignment() {
+	type t1 struct {
+		a int8
+
+		// To make field b 4-byte aligned, 3 bytes
+		// need to be padded here.
+
+		b int64
+		c int16
+
+		// To make the size of type T1 be a multiple
+		// of the alignment guarantee of T1, 2 bytes
+		// need to be padded here.
+	}
+	// The size of T1 is 16 (= 1 + 3 + 8 + 2 + 2).
+
+	type t2 struct {
+		a int8
+
+		// To make field c 2-byte aligned, one byte
+		// needs to be padded here.
+
+		c int16
+
+		// Field b is already 4-byte aligned, so no
+		// bytes need to be padded here.
+
+		b int64
+	}
+	// The size of T2 is 12 (= 1 + 1 + 2 + 8).
+
+	fmt.Println("Size of t1:", unsafe.Sizeof(t1{}))
+	fmt.Println("Size of t2:", unsafe.Sizeof(t2{}))
+	// Output:
+	//Size of t1: 16
+	//Size of t2: 12
+}
+
+// Example_structMemoryAlignmentOptimized shows how by ordering the fields from largest to the smallest will minimize the
+// need for padding between fields, thus reducing the total size of the struct. On 32-bit architectures, one byte is
+// still padded at the end to keep the size a multiple of 4.
+//
+//dojo:meta difficulty=advanced minutes=5 tags=memory requires=Example_structMemoryAlignment
+func Example_structMemoryAlignmentOptimized() {
+	type t1 struct {
+		b int64
+		c int16
+		a int8
+	}
+
+	fmt.Println("Size of t1:", unsafe.Sizeof(t1{}))
+	// Output:
+	//Size of t1: 12
+}
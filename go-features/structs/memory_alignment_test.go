@@ -1,3 +1,5 @@
+//go:build !(386 || arm || mips || mipsle)
+
 package structs
 
 import (
@@ -41,9 +43,13 @@ import (
 //
 // In practice, this means that total struct sizes are not a simple sum of its parts in all cases.
 //
+// The expected output is the one of 64-bit architectures, memory_alignment_32bit_test.go expects the 32-bit sizes.
+//
 // [Memory Layout Article]: https://go101.org/article/memory-layout.html
 // [Value copy cost article]: https://go101.org/article/value-copy-cost.html#value-sizes
 // [Alignment and size guarantees]: https://golang.org/ref/spec#Size_and_alignment_guarantees
+//
+//dojo:meta difficulty=advanced minutes=15 tags=memory,spec
 func Example_structMemoryAlignment() {
 	type t1 struct {
 		a int8
@@ -97,6 +103,8 @@ func Example_structMemoryAlignment() {
 
 // Example_structMemoryAlignmentOptimized shows how by ordering the fields from largest to the smallest will minimize the
 // need for padding between fields, thus reducing the total size of the struct.
+//
+//dojo:meta difficulty=advanced minutes=5 tags=memory requires=Example_structMemoryAlignment
 func Example_structMemoryAlignmentOptimized() {
 	type t1 struct {
 		b int64
@@ -25,6 +25,8 @@ func (l *List) Append(val int) { *l = append(*l, val) }
 // (&x).m()
 //
 // IMPORTANT: Keep in mind that mixing value and pointer receiver in the same struct is not recommended by Go docs.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=spec
 func Example_methodSets() {
 	var lst List
 	lst.Append(1)
@@ -63,6 +65,8 @@ type Singer struct {
 // If S contains an embedded field T, the method sets of S and *S both include promoted methods with receiver T.
 // The method set of *S also includes promoted methods with receiver *T.
 // If S contains an embedded field *T, the method sets of S and *S both include promoted methods with receiver T or *T.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=spec requires=Example_methodSets
 func Example_embeddings() {
 	var gaga = Singer{Person: Person{"Gaga", 30}}
 	gaga.PrintName() // Name: Gaga
@@ -81,6 +85,8 @@ func Example_embeddings() {
 // will take the address before calling the method.
 // https://go101.org/article/type-embedding.html and https://go101.org/article/method.html#call elaborate on the concepts
 // described here.*/
+//
+//dojo:meta difficulty=advanced minutes=5 tags=pitfall,spec requires=Example_embeddings
 func Example_embeddings2() {
 	var gaga = Singer{Person: Person{"Gaga", 30}}
 	gaga.PrintName() // Name: Gaga
@@ -10,6 +10,8 @@ import (
 )
 
 // Example_switchOrder helps understand the order of execution of switch cases
+//
+//dojo:meta difficulty=beginner minutes=2 tags=basics,testing
 func Test_switchOrder(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		weekday := time.Now().Weekday()
@@ -25,6 +27,8 @@ func Test_switchOrder(t *testing.T) {
 }
 
 // Example_switchOrder also shows the linear order of the switch case without a default case. Also, we can see that the fallthrough keyword can be used to continue running the next case.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=basics,spec
 func Example_switchOrder() {
 	switch 2 {
 	case 1:
@@ -58,6 +62,8 @@ func Foo(n int) int {
 }
 
 // ExampleFoo shows the order of execution from left to right and top to bottom, and also shows the fallthrough keyword.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=pitfall,spec requires=Example_switchOrder
 func ExampleFoo() {
 	switch Foo(2) {
 	case Foo(1), Foo(2), Foo(3):
@@ -75,6 +81,8 @@ func ExampleFoo() {
 }
 
 // Example_break shows how to use the explicit break. Golang's switch cases break implicitly.
+//
+//dojo:meta difficulty=beginner minutes=2 tags=basics,spec
 func Example_break() {
 	argv := []any{"cat"}
 	switch argv[0] {
@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// turns is a number of turns given to goroutines waiting on a sync.Cond, and what they saw.
+type turns struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	turns   int // turns given and not taken yet
+	waiting int // goroutines waiting for a turn
+	wakeups int // times a goroutine returned from Wait
+}
+
+// take waits for a turn and takes it.
+func (t *turns) take() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting++
+	for t.turns == 0 { // a loop: waking up doesn't mean there is a turn, another goroutine may have taken it
+		t.cond.Wait()
+		t.wakeups++
+	}
+	t.turns--
+	t.waiting--
+}
+
+// give gives a turn, then wakes the waiters with wake, t.cond.Signal or t.cond.Broadcast.
+func (t *turns) give(wake func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.turns++
+	wake()
+}
+
+// until waits for cond to be true of t.
+func (t *turns) until(cond func(t *turns) bool) {
+	for {
+		t.mu.Lock()
+		ok := cond(t)
+		t.mu.Unlock()
+		if ok {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+// Example_condSignalBroadcast shows how goroutines wait on a sync.Cond for a change of some state, here turns, and
+// what Signal and Broadcast wake. Wait unlocks the mutex while it waits, and locks it again before returning.
+//
+// Signal wakes one waiter. Broadcast wakes all of them: with one turn for two waiters, one takes it and the other
+// goes back to waiting, which is why Wait is always in a loop checking the state. Broadcast is for changes every
+// waiter cares about, Signal for changes one waiter can use.
+//
+// A Cond has no memory: a Signal while nobody waits is lost. The state has the memory, the waiter checks it before
+// waiting. Channels are often simpler: a closed channel is a broadcast that is never lost.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency requires=Example_mutex
+func Example_condSignalBroadcast() {
+	t := &turns{}
+	t.cond = sync.NewCond(&t.mu)
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(t.take)
+	}
+	t.until(func(t *turns) bool { return t.waiting == 3 })
+
+	t.give(t.cond.Signal)
+	t.until(func(t *turns) bool { return t.waiting == 2 })
+	fmt.Println("Signal:", t.wakeups, "woken, 2 waiting")
+
+	t.give(t.cond.Broadcast)
+	t.until(func(t *turns) bool { return t.wakeups == 3 && t.waiting == 1 })
+	fmt.Println("Broadcast:", t.wakeups-1, "woken, 1 waiting again")
+
+	t.give(t.cond.Signal)
+	wg.Wait()
+
+	t.cond.Signal() // nobody waits: lost
+	wg.Go(t.take)
+	t.until(func(t *turns) bool { return t.waiting == 1 })
+	fmt.Println("after a lost Signal:", t.waiting, "waiting")
+	t.give(t.cond.Signal)
+	wg.Wait()
+	// Output:
+	// Signal: 1 woken, 2 waiting
+	// Broadcast: 2 woken, 1 waiting again
+	// after a lost Signal: 1 waiting
+}
@@ -0,0 +1,200 @@
+package sync
+
+import (
+	"fmt"
+	"hash/maphash"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Example_syncMap shows sync.Map, a map safe for concurrent use without a mutex of its own. LoadOrStore and
+// CompareAndSwap read and write as one step, what a map behind a mutex needs the lock held across for.
+//
+// It is no faster in general than a map with a mutex: it is made for keys written once and read many times, like a
+// cache that only grows, and for goroutines working on disjoint sets of keys. Its values are of type any, and Range
+// sees the keys in no particular order, like a range over a map.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency requires=Example_mutex
+func Example_syncMap() {
+	var m sync.Map
+	m.Store("go", 2009)
+	v, loaded := m.LoadOrStore("go", 2012)
+	fmt.Println(v, loaded)
+	v, loaded = m.LoadOrStore("rust", 2015)
+	fmt.Println(v, loaded)
+	fmt.Println(m.CompareAndSwap("rust", 2010, 2015), m.CompareAndSwap("rust", 2015, 2010))
+
+	var keys []string
+	m.Range(func(k, _ any) bool {
+		keys = append(keys, k.(string))
+		return true
+	})
+	slices.Sort(keys)
+	fmt.Println(keys)
+	// Output:
+	// 2009 true
+	// 2015 false
+	// false true
+	// [go rust]
+}
+
+// shardedMap is a map split in shards, each with its own lock: goroutines using keys of different shards don't
+// contend. The shard of a key is its hash modulo the number of shards.
+type shardedMap[V any] struct {
+	seed   maphash.Seed
+	shards [16]struct {
+		mu sync.RWMutex
+		m  map[string]V
+	}
+}
+
+func newShardedMap[V any]() *shardedMap[V] {
+	s := &shardedMap[V]{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].m = map[string]V{}
+	}
+	return s
+}
+
+func (s *shardedMap[V]) shard(k string) int {
+	return int(maphash.String(s.seed, k) % uint64(len(s.shards)))
+}
+
+func (s *shardedMap[V]) Load(k string) (V, bool) {
+	sh := &s.shards[s.shard(k)]
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	v, ok := sh.m[k]
+	return v, ok
+}
+
+func (s *shardedMap[V]) Store(k string, v V) {
+	sh := &s.shards[s.shard(k)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.m[k] = v
+}
+
+// Update sets k to f of its value, as one step.
+func (s *shardedMap[V]) Update(k string, f func(V) V) {
+	sh := &s.shards[s.shard(k)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.m[k] = f(sh.m[k])
+}
+
+// Test_shardedMap shows a sharded map: a map split in 16 shards, each a map with its own lock, the shard of a key
+// given by its hash. Goroutines using keys of different shards don't wait for one another, where a single mutex would
+// make them take turns. Unlike sync.Map, it is typed, and it can update a value as one step, with the lock of its
+// shard held.
+//
+// Here 8 goroutines count words at once: run it with -race, the counts are exact, and the race detector is quiet.
+// BenchmarkMaps compares it with sync.Map and a map behind one mutex.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency,testing requires=Example_syncMap,Test_mutexCheckThenAct
+func Test_shardedMap(t *testing.T) {
+	counts := newShardedMap[int]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				counts.Update("word"+strconv.Itoa(i%100), func(n int) int { return n + 1 })
+			}
+		})
+	}
+	wg.Wait()
+	for i := range 100 {
+		n, ok := counts.Load("word" + strconv.Itoa(i))
+		require.True(t, ok)
+		require.Equal(t, 80, n)
+	}
+}
+
+// mutexMap is a map behind one mutex, for the benchmarks.
+type mutexMap[V any] struct {
+	mu sync.RWMutex
+	m  map[string]V
+}
+
+func (m *mutexMap[V]) Load(k string) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.m[k]
+	return v, ok
+}
+
+func (m *mutexMap[V]) Store(k string, v V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m[k] = v
+}
+
+// anyMap is a sync.Map, typed for the benchmarks.
+type anyMap[V any] struct{ m sync.Map }
+
+func (m *anyMap[V]) Load(k string) (V, bool) {
+	v, ok := m.m.Load(k)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return v.(V), true
+}
+
+func (m *anyMap[V]) Store(k string, v V) { m.m.Store(k, v) }
+
+// BenchmarkMaps loads and stores 1000 keys from GOMAXPROCS goroutines at once, in a map behind a sync.RWMutex, a
+// sync.Map and a sharded map, reading mostly, or writing one time out of 4:
+//
+//	go test -run '^$' -bench Maps -benchmem -cpu 1,2,4,8 ./go-features/sync/
+//
+// Reading stable keys, sync.Map reads without any lock, and scales with the CPUs. Writing, it allocates an entry per
+// new value, and the sharded map is ahead: its goroutines mostly lock different shards. With one CPU, nothing
+// contends, and the plain map is as good as any.
+func BenchmarkMaps(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	type store interface {
+		Load(string) (int, bool)
+		Store(string, int)
+	}
+	for _, w := range []struct {
+		name   string
+		writes int // one write out of writes operations
+	}{
+		{name: "read mostly", writes: 1000},
+		{name: "write 1 of 4", writes: 4},
+	} {
+		for _, m := range []struct {
+			name string
+			new  func() store
+		}{
+			{name: "RWMutex", new: func() store { return &mutexMap[int]{m: map[string]int{}} }},
+			{name: "sync.Map", new: func() store { return &anyMap[int]{} }},
+			{name: "sharded", new: func() store { return newShardedMap[int]() }},
+		} {
+			b.Run(w.name+"/"+m.name, func(b *testing.B) {
+				s := m.new()
+				for i, k := range keys {
+					s.Store(k, i)
+				}
+				b.RunParallel(func(pb *testing.PB) {
+					for i := 0; pb.Next(); i++ {
+						k := keys[i%len(keys)]
+						if i%w.writes == 0 {
+							s.Store(k, i)
+						} else {
+							s.Load(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Example_mutex shows how a sync.Mutex guards a map written by many goroutines: only one goroutine at a time runs
+// between Lock and Unlock. Without it, concurrent writes to a map crash the program with `concurrent map writes`.
+// The zero value is an unlocked mutex, ready to use.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=concurrency,basics requires=concurrency.Example_readingFromChannelInForLoop
+func Example_mutex() {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Go(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			counts[[]string{"even", "odd"}[i%2]]++
+		})
+	}
+	wg.Wait()
+	fmt.Println(counts)
+	// Output: map[even:50 odd:50]
+}
+
+// account is a balance and the mutex guarding it.
+type account struct {
+	mu      sync.Mutex
+	balance int
+}
+
+// Example_copyingLockedMutex shows what copying a mutex does: the copy has the state of the original, locked
+// included. Here the copy is made by append, growing the slice while a mutex in it is locked: the new array has a
+// locked copy, the unlock goes to the old array, and the account is locked forever.
+//
+// A mutex must not be copied after first use, nor anything holding one: pass *account, not account, and keep
+// accounts in a []*account or a map of pointers. go vet reports the copies it can see, as assignments or arguments
+// of such types, but not this one.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,pitfall,memory requires=Example_mutex
+func Example_copyingLockedMutex() {
+	accounts := make([]account, 1)
+	a := &accounts[0]
+	a.mu.Lock()
+	accounts = append(accounts, account{}) // a new array, with a copy of the locked account
+	a.balance += 10
+	a.mu.Unlock()
+
+	fmt.Println(accounts[0].balance, accounts[0].mu.TryLock())
+	// Output: 0 false
+}
+
+// Test_mutexCheckThenAct shows a race condition that is no data race: each access to the counter holds the lock,
+// and the race detector has nothing to report, but the increment doesn't. Every goroutine gets the value, then sets it
+// plus one: they all get 0, and the counter ends at 1, not 10. A mutex guards an invariant, and must be held for the
+// whole of the operation keeping it, here the read and the write.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,pitfall requires=Example_mutex
+func Test_mutexCheckThenAct(t *testing.T) {
+	var mu sync.Mutex
+	n := 0
+	get := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return n
+	}
+	set := func(v int) {
+		mu.Lock()
+		defer mu.Unlock()
+		n = v
+	}
+
+	var got, wg sync.WaitGroup
+	got.Add(10)
+	for range 10 {
+		wg.Go(func() {
+			v := get()
+			got.Done()
+			got.Wait() // every goroutine got the value before any sets it: the worst interleaving, every time
+			set(v + 1)
+		})
+	}
+	wg.Wait()
+	require.Equal(t, 1, n)
+
+	n = 0
+	inc := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+	}
+	for range 10 {
+		wg.Go(inc)
+	}
+	wg.Wait()
+	require.Equal(t, 10, n)
+}
+
+// BenchmarkCounter increments a counter from GOMAXPROCS goroutines at once, guarded by a sync.Mutex, or a
+// sync/atomic integer:
+//
+//	go test -run '^$' -bench Counter -cpu 1,2,4,8 ./go-features/sync/
+//
+// With one goroutine the mutex is a couple of atomic operations. Contended, goroutines spin then sleep waiting for
+// it, and the mutex gets slower with each CPU, the atomic integer much less so: all it does is one atomic add.
+func BenchmarkCounter(b *testing.B) {
+	b.Run("Mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		n := 0
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				n++
+				mu.Unlock()
+			}
+		})
+	})
+	b.Run("atomic", func(b *testing.B) {
+		var n atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				n.Add(1)
+			}
+		})
+	})
+}
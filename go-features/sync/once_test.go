@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Example_once shows how sync.Once runs a function once, however many goroutines call Do: the first one runs it, the
+// others wait for it to return. Lazy initialization of something shared is the common use.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=concurrency,basics requires=Example_mutex
+func Example_once() {
+	var once sync.Once
+	loads := 0
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			once.Do(func() { loads++ })
+		})
+	}
+	wg.Wait()
+	fmt.Println("loads:", loads)
+	// Output: loads: 1
+}
+
+// call calls f, and prints what it panics with.
+func call(f func()) {
+	defer func() { fmt.Println("panic:", recover()) }()
+	f()
+}
+
+// Example_oncePanicking shows what happens when the function of a sync.Once panics: Once considers it done. The
+// next Do returns at once without calling it, as if it had worked, and what it should have initialized stays zero.
+//
+// sync.OnceFunc remembers the panic instead, and panics again with it on every call: the caller can't miss that the
+// initialization failed.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,pitfall requires=Example_once,panic.Example_recoverFromPanic
+func Example_oncePanicking() {
+	load := func() { panic("no config") }
+
+	var once sync.Once
+	call(func() { once.Do(load) })
+	call(func() { once.Do(load) })
+
+	loadOnce := sync.OnceFunc(load)
+	call(loadOnce)
+	call(loadOnce)
+	// Output:
+	// panic: no config
+	// panic: <nil>
+	// panic: no config
+	// panic: no config
+}
+
+// Example_onceValue shows sync.OnceValue and sync.OnceValues, which run a function once and return its results on
+// every call. The results are cached whatever they are, errors included: a failed first call is never retried. To
+// retry, guard the value with a mutex instead, and keep it only once it is valid.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,pitfall requires=Example_once
+func Example_onceValue() {
+	calls := 0
+	port := sync.OnceValue(func() int {
+		calls++
+		return 8080
+	})
+	fmt.Println(port(), port(), "calls:", calls)
+
+	calls = 0
+	config := sync.OnceValues(func() (string, error) {
+		calls++
+		return "", errors.New("config server unreachable")
+	})
+	_, err := config()
+	fmt.Println(err)
+	_, err = config() // the server may be back, nobody asks
+	fmt.Println(err, "calls:", calls)
+	// Output:
+	// 8080 8080 calls: 1
+	// config server unreachable
+	// config server unreachable calls: 1
+}
@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_poolReuse shows how a sync.Pool hands back objects that were put in it, rather than allocating new ones: Get
+// returns one that was Put, or calls New when the pool is empty. The object comes back as it was put, with its data:
+// reset it before Put, or the next user gets what the previous one wrote, e.g. the body of another user's response.
+//
+// A pool is a cache, not a free list: it may drop objects at any time, and it does with the race detector, to find
+// code that expects them back. Nothing but New should be relied on.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=concurrency,memory,pitfall requires=Example_mutex
+func Test_poolReuse(t *testing.T) {
+	pool := sync.Pool{New: func() any { return new(bytes.Buffer) }}
+	reused := false
+	for range 10 { // Put and Get may run on different Ps, whose objects are kept apart
+		b := pool.Get().(*bytes.Buffer)
+		b.WriteString("secret of the previous user")
+		pool.Put(b)
+		next := pool.Get().(*bytes.Buffer)
+		if next == b {
+			reused = true
+			require.Equal(t, "secret of the previous user", next.String())
+		}
+		next.Reset()
+		pool.Put(next)
+	}
+	require.True(t, reused)
+}
+
+// Test_poolAcrossGC shows how long a sync.Pool keeps objects: until the second garbage collection. Each collection
+// moves the objects of the pool to a victim cache, and drops the previous victims. An object that is got and put
+// back between collections stays in the pool, one unused for two collections is garbage.
+//
+// A pool fits objects reused at a steady pace, like buffers of requests. It doesn't keep anything for long, and it
+// can't bound how many objects there are: it is no connection pool.
+//
+//dojo:meta difficulty=advanced minutes=5 tags=memory,runtime requires=Test_poolReuse
+func Test_poolAcrossGC(t *testing.T) {
+	pool := sync.Pool{New: func() any { return new(bytes.Buffer) }}
+	survived := false
+	for range 10 {
+		b := new(bytes.Buffer)
+		pool.Put(b)
+		runtime.GC()
+		if pool.Get() == b {
+			survived = true
+			break
+		}
+	}
+	require.True(t, survived, "one collection: in the victim cache")
+
+	b := new(bytes.Buffer)
+	pool.Put(b)
+	runtime.GC()
+	runtime.GC()
+	require.NotSame(t, b, pool.Get(), "two collections: dropped")
+}
+
+// Test_poolOfPointers shows why pools hold pointers. Put takes an any: a []byte put in the pool is boxed in an
+// interface, which allocates its slice header, the very allocation the pool was meant to save. A *[]byte fits in the
+// interface as is.
+//
+//dojo:meta difficulty=advanced minutes=5 tags=memory,pitfall requires=Test_poolReuse
+func Test_poolOfPointers(t *testing.T) {
+	values := sync.Pool{New: func() any { return make([]byte, 0, 4<<10) }}
+	valueAllocs := testing.AllocsPerRun(100, func() {
+		b := values.Get().([]byte)
+		values.Put(b[:0])
+	})
+
+	pointers := sync.Pool{New: func() any {
+		b := make([]byte, 0, 4<<10)
+		return &b
+	}}
+	pointerAllocs := testing.AllocsPerRun(100, func() {
+		b := pointers.Get().(*[]byte)
+		*b = (*b)[:0]
+		pointers.Put(b)
+	})
+	t.Logf("allocations per Get and Put: %v of []byte, %v of *[]byte", valueAllocs, pointerAllocs)
+	require.GreaterOrEqual(t, valueAllocs, 1.0)
+	require.Less(t, pointerAllocs, valueAllocs)
+}
+
+// BenchmarkBuffers gets a 4KB buffer from GOMAXPROCS goroutines at once, allocated each time or from a sync.Pool:
+//
+//	go test -run '^$' -bench Buffers -benchmem -cpu 1,2,4,8 ./go-features/sync/
+//
+// The pool saves the allocations, and the work of the garbage collector collecting them. Its objects are kept per P,
+// the processors of the scheduler: goroutines on different Ps don't contend for them.
+func BenchmarkBuffers(b *testing.B) {
+	write := func(w io.Writer) { // an interface, the buffer escapes to the heap as it would in a handler
+		for range 64 {
+			io.WriteString(w, "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+		}
+	}
+	b.Run("new", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				write(bytes.NewBuffer(make([]byte, 0, 4<<10)))
+			}
+		})
+	})
+	b.Run("Pool", func(b *testing.B) {
+		pool := sync.Pool{New: func() any { return bytes.NewBuffer(make([]byte, 0, 4<<10)) }}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				buf := pool.Get().(*bytes.Buffer)
+				write(buf)
+				buf.Reset()
+				pool.Put(buf)
+			}
+		})
+	})
+}
@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// rwLocker is what the lessons need of a reader/writer lock.
+type rwLocker interface {
+	Lock()
+	Unlock()
+	RLock()
+	TryRLock() bool
+	RUnlock()
+}
+
+// readerPreferring is a reader/writer lock as often written first: a count of readers, and a writer waiting for it
+// to be zero. Readers come in as long as no writer holds the lock.
+type readerPreferring struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	readers int
+	writer  bool
+	waiting int // writers waiting in Lock
+}
+
+func newReaderPreferring() *readerPreferring {
+	l := &readerPreferring{}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *readerPreferring) RLock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.writer {
+		l.cond.Wait()
+	}
+	l.readers++
+}
+
+func (l *readerPreferring) TryRLock() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer {
+		return false
+	}
+	l.readers++
+	return true
+}
+
+func (l *readerPreferring) RUnlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.readers--
+	l.cond.Broadcast()
+}
+
+func (l *readerPreferring) Lock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waiting++
+	for l.writer || l.readers > 0 {
+		l.cond.Wait()
+	}
+	l.waiting--
+	l.writer = true
+}
+
+func (l *readerPreferring) Unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writer = false
+	l.cond.Broadcast()
+}
+
+// writerWaiting reports whether a writer waits in Lock, l being read locked.
+func writerWaiting(l rwLocker) bool {
+	switch l := l.(type) {
+	case *sync.RWMutex:
+		if l.TryRLock() { // fails once a writer waits
+			l.RUnlock()
+			return false
+		}
+		return true
+	case *readerPreferring:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiting > 0
+	}
+	panic(fmt.Sprintf("unexpected %T", l))
+}
+
+// overlappingReaders runs a writer while readers overlap: reader 1 reads, the writer waits for it, reader 2 comes,
+// then reader 1 leaves. It returns who got the lock, in order.
+func overlappingReaders(l rwLocker) []string {
+	var mu sync.Mutex
+	var order []string
+	got := func(who string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, who)
+	}
+
+	l.RLock()
+	got("reader 1")
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		l.Lock()
+		got("writer")
+		l.Unlock()
+	})
+	for !writerWaiting(l) {
+		runtime.Gosched()
+	}
+
+	reading := l.TryRLock() // reader 2, while reader 1 still reads
+	l.RUnlock()             // reader 1 leaves
+	if !reading {
+		l.RLock() // reader 2 waits for its turn
+	}
+	got("reader 2")
+	l.RUnlock()
+	wg.Wait()
+	return order
+}
+
+// Example_rwMutex shows how a sync.RWMutex lets readers share the lock, and writers have it alone. A writer waiting
+// in Lock holds back the readers that come after it: reader 2 waits for the writer, even though reader 1 still reads
+// when it comes. Otherwise, readers overlapping one another would keep the writer out forever.
+//
+// A lock that lets reader 2 in starves its writers: readerPreferring, a count of readers the writer waits to be zero,
+// is the one often written first. With it, reader 2 reads before the writer.
+//
+// The flip side is recursive read locking: a reader calling RLock again while a writer waits deadlocks, the second
+// RLock waiting for the writer, the writer waiting for the first RLock.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency,pitfall requires=Example_mutex,Example_condSignalBroadcast
+func Example_rwMutex() {
+	fmt.Println("sync.RWMutex:    ", overlappingReaders(&sync.RWMutex{}))
+	fmt.Println("readerPreferring:", overlappingReaders(newReaderPreferring()))
+	// Output:
+	// sync.RWMutex:     [reader 1 writer reader 2]
+	// readerPreferring: [reader 1 reader 2 writer]
+}
+
+// BenchmarkReadMostly reads a map from GOMAXPROCS goroutines at once, writing it one time out of 100, guarded by a
+// sync.RWMutex, locked for reads with Lock, as a plain mutex, or with RLock:
+//
+//	go test -run '^$' -bench ReadMostly -cpu 1,2,4,8 ./go-features/sync/
+//
+// The RWMutex only pays off when readers do contend: RLock is an atomic add on a counter all CPUs share, and holding
+// the read lock a few ns, as here, the mutex may well be as fast.
+func BenchmarkReadMostly(b *testing.B) {
+	for _, l := range []struct {
+		name string
+		lock func(*sync.RWMutex) (lock, unlock func())
+	}{
+		{name: "Mutex", lock: func(mu *sync.RWMutex) (func(), func()) { return mu.Lock, mu.Unlock }},
+		{name: "RWMutex", lock: func(mu *sync.RWMutex) (func(), func()) { return mu.RLock, mu.RUnlock }},
+	} {
+		b.Run(l.name, func(b *testing.B) {
+			var mu sync.RWMutex
+			m := map[int]int{}
+			rlock, runlock := l.lock(&mu)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if i%100 == 0 {
+						mu.Lock()
+						m[i%10]++
+						mu.Unlock()
+						continue
+					}
+					rlock()
+					_ = m[i%10]
+					runlock()
+				}
+			})
+		})
+	}
+}
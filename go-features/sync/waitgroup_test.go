@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Example_waitGroupGo shows sync.WaitGroup.Go, which replaces the Add, go and defer Done of
+// panic.Example_panicInGoroutineGracefully: it adds one to the counter, and runs the function in a goroutine that
+// calls Done when it returns. The goroutines write disjoint elements of a slice, which needs no lock, and Wait makes
+// their writes visible to the caller.
+//
+// The Add must happen before the goroutine starts. Called inside it, Add races with Wait: here the goroutine waits
+// for a signal before its Add, and Wait sees a counter of zero, and returns before any work is done. Go can't be
+// misused that way. A panic in the function still crashes the program: recovering is up to the function.
+//
+//dojo:meta difficulty=beginner minutes=5 tags=concurrency,pitfall requires=panic.Example_panicInGoroutineGracefully
+func Example_waitGroupGo() {
+	var wg sync.WaitGroup
+	squares := make([]int, 5)
+	for i := range squares {
+		wg.Go(func() { squares[i] = i * i })
+	}
+	wg.Wait()
+	fmt.Println(squares)
+
+	start, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		<-start
+		wg.Add(1) // too late
+		defer wg.Done()
+	}()
+	wg.Wait()
+	fmt.Println("Wait returned, the goroutine hasn't started")
+	close(start)
+	<-done
+	// Output:
+	// [0 1 4 9 16]
+	// Wait returned, the goroutine hasn't started
+}
@@ -27,6 +27,8 @@ type cString = subtype.SString
 //	 func (c cString) Len(){
 //		 return len(c)
 //	 }
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=spec
 func Example_typeAlias() {
 	a := aString("hello a")
 	fmt.Println(a.String(), a.Len()) // `String()` and `Len()` is available here, even tho they are not directly attached to aString
@@ -43,6 +45,9 @@ func Example_typeAlias() {
 	//hello c
 }
 
+// Example_typeAliasComparison shows that an alias and its type compare with ==, being the same type.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=spec requires=Example_typeAlias
 func Example_typeAliasComparison() {
 	a := aString("hello")
 	fmt.Println(a.String(), a.Len()) // `String()` and `Len()` is available here, even tho they are not directly attached to aString
@@ -0,0 +1,117 @@
+// Package book renders the lessons of the dojo as a static HTML site and as a single Markdown file.
+//
+// The book has one chapter per package of the README's package list, in the same order. Each lesson comes with its
+// prose, code, expected output, references and the command to try it. The output is self-contained: styles are
+// inlined and nothing is fetched from the network, neither while building nor while reading.
+package book
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+// Chapter is a lesson package of the book.
+type Chapter struct {
+	Name        string // package, e.g. concurrency or protocols
+	Description string
+	Lessons     []*lesson.Lesson
+}
+
+// Book is the content to render.
+type Book struct {
+	Title    string
+	Chapters []*Chapter
+}
+
+var (
+	packageHeading = regexp.MustCompile("^##+ .*`([^`]+)`$")    // ## Protocols `protocols`
+	packageItem    = regexp.MustCompile("^- `([^`]+)`: *(.*)$") // - `consts`: Use of `const` blocks
+	readmeTitle    = regexp.MustCompile(`^# (.+)$`)
+)
+
+// README is a chapter list read from the package list of a README file.
+type README struct {
+	Title    string
+	Chapters []Chapter // without lessons
+}
+
+// ParseREADME reads the package list of the README: headings ending with a quoted package name, followed by a
+// description paragraph, and list items starting with a quoted package name and its description.
+func ParseREADME(path string) (*README, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &README{}
+	var current *Chapter // heading whose description is being read
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case r.Title == "" && readmeTitle.MatchString(line):
+			r.Title = readmeTitle.FindStringSubmatch(line)[1]
+		case packageHeading.MatchString(line):
+			r.Chapters = append(r.Chapters, Chapter{Name: packageHeading.FindStringSubmatch(line)[1]})
+			current = &r.Chapters[len(r.Chapters)-1]
+		case packageItem.MatchString(line):
+			m := packageItem.FindStringSubmatch(line)
+			r.Chapters = append(r.Chapters, Chapter{Name: m[1], Description: m[2]})
+			current = nil
+		case strings.HasPrefix(line, "#"):
+			current = nil
+		case current != nil && line != "":
+			current.Description = strings.TrimSpace(current.Description + " " + line)
+		}
+	}
+	return r, sc.Err()
+}
+
+// New lays out the lessons of the index in the chapters of the README. The lessons of a chapter follow the learning
+// path; chapters missing from the README come last and chapters without lessons are dropped.
+func New(idx *lesson.Index, readme *README) (*Book, error) {
+	path, err := idx.Path()
+	if err != nil {
+		return nil, err
+	}
+	b := &Book{Title: readme.Title}
+	byName := map[string]*Chapter{}
+	for _, c := range readme.Chapters {
+		if _, ok := byName[c.Name]; !ok {
+			byName[c.Name] = &Chapter{Name: c.Name, Description: c.Description}
+			b.Chapters = append(b.Chapters, byName[c.Name])
+		}
+	}
+	for _, l := range path {
+		c, ok := byName[l.Chapter()]
+		if !ok {
+			c = &Chapter{Name: l.Chapter()}
+			byName[c.Name] = c
+			b.Chapters = append(b.Chapters, c)
+		}
+		c.Lessons = append(c.Lessons, l)
+	}
+	chapters := b.Chapters[:0]
+	for _, c := range b.Chapters {
+		if len(c.Lessons) > 0 {
+			chapters = append(chapters, c)
+		}
+	}
+	b.Chapters = chapters
+	return b, nil
+}
+
+// TryIt is the command that runs a lesson.
+func TryIt(l *lesson.Lesson) string {
+	return "go run ./cmd/dojo run " + l.ID()
+}
+
+// Anchor is the HTML id of a lesson.
+func Anchor(l *lesson.Lesson) string {
+	return strings.ReplaceAll(l.ID(), "/", "-")
+}
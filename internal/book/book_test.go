@@ -0,0 +1,89 @@
+package book
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+func repoBook(t *testing.T) *Book {
+	t.Helper()
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+	idx, err := lesson.Discover(root)
+	require.NoError(t, err)
+	readme, err := ParseREADME(filepath.Join(root, "README.md"))
+	require.NoError(t, err)
+	b, err := New(idx, readme)
+	require.NoError(t, err)
+	return b
+}
+
+func Test_newFollowsTheREADME(t *testing.T) {
+	b := repoBook(t)
+	require.Equal(t, "Go-Dojo", b.Title)
+
+	var names []string
+	lessons := 0
+	for _, c := range b.Chapters {
+		names = append(names, c.Name)
+		lessons += len(c.Lessons)
+		require.NotEmpty(t, c.Lessons, c.Name)
+		for _, l := range c.Lessons {
+			require.Equal(t, c.Name, l.Chapter())
+			require.False(t, l.IsExercise())
+		}
+	}
+	require.Equal(t, "protocols", names[0])
+	require.NotContains(t, names, "go-features", "chapters without lessons are dropped")
+	require.NotContains(t, names, "exercises")
+	i := slices.IndexFunc(b.Chapters, func(c *Chapter) bool { return c.Name == "panic" })
+	require.NotEqual(t, -1, i, "panic chapter")
+	require.Equal(t, "Panic propagation and recovery mechanics.", b.Chapters[i].Description)
+	require.NotZero(t, lessons)
+}
+
+func Test_highlight(t *testing.T) {
+	got := Highlight("func f() string { return \"<b>\" + 1 } // done")
+	require.Equal(t, `<span class="kw">func</span> f() <span class="bi">string</span> { <span class="kw">return</span> `+
+		`<span class="str">&#34;&lt;b&gt;&#34;</span> + <span class="num">1</span> } <span class="com">// done</span>`, got)
+
+	require.Equal(t, "&#34;unterminated", Highlight(`"unterminated`))
+}
+
+// assets matches the attributes that make a browser fetch something while rendering the page, as whole words: Go
+// code on the page has identifiers like SetURL(.
+var assets = regexp.MustCompile(`(?i)\b(src|srcset)=|<link\b|@import\b|\burl\(`)
+
+func Test_writeIsSelfContained(t *testing.T) {
+	b := repoBook(t)
+	dir := t.TempDir()
+	require.NoError(t, b.WriteHTML(dir))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	for _, c := range b.Chapters {
+		require.Contains(t, string(index), `href="`+chapterFile(c)+`"`)
+		page, err := os.ReadFile(filepath.Join(dir, chapterFile(c)))
+		require.NoError(t, err)
+		require.NotRegexp(t, assets, string(page))
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "panic.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(page), `id="panic.Example_panicChain2"`)
+	require.Contains(t, string(page), "third\nsecond\nfirst\nRecovered from panic: panic first")
+	require.Contains(t, string(page), "go run ./cmd/dojo run panic.Example_panicChain2")
+
+	var md strings.Builder
+	require.NoError(t, b.WriteMarkdown(&md))
+	require.Contains(t, md.String(), "## Example_panicChain2\n")
+	require.Contains(t, md.String(), "```go\nfunc Example_panicChain2() {")
+}
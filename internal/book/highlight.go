@@ -0,0 +1,70 @@
+package book
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// predeclared identifiers highlighted like keywords.
+var predeclared = map[string]bool{
+	"any": true, "append": true, "bool": true, "byte": true, "cap": true, "clear": true, "close": true,
+	"comparable": true, "complex": true, "complex64": true, "complex128": true, "copy": true, "delete": true,
+	"error": true, "false": true, "float32": true, "float64": true, "imag": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "iota": true, "len": true, "make": true, "max": true, "min": true,
+	"new": true, "nil": true, "panic": true, "print": true, "println": true, "real": true, "recover": true,
+	"rune": true, "string": true, "true": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true,
+}
+
+// Highlight escapes Go source for HTML and wraps its tokens in spans with classes: kw (keywords), bi (predeclared
+// identifiers), str, num and com (comments). Source that does not scan is escaped as is.
+func Highlight(src string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	failed := false
+	s.Init(file, []byte(src), func(token.Position, string) { failed = true }, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		start := file.Offset(pos)
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // automatically inserted
+		}
+		class := ""
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.IDENT && predeclared[lit]:
+			class = "bi"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok == token.COMMENT:
+			class = "com"
+		}
+		if class == "" {
+			continue
+		}
+		end := start + len(lit)
+		if tok.IsKeyword() {
+			end = start + len(tok.String())
+		}
+		b.WriteString(html.EscapeString(src[last:start]))
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(src[start:end]) + `</span>`)
+		last = end
+	}
+	if failed {
+		return html.EscapeString(src)
+	}
+	b.WriteString(html.EscapeString(src[last:]))
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package book
+
+import (
+	"go/doc/comment"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+const style = `
+body { font-family: system-ui, sans-serif; line-height: 1.5; margin: 0; color: #1d1d1f; }
+nav { position: fixed; top: 0; bottom: 0; width: 15rem; overflow-y: auto; padding: 1rem; background: #f5f5f7; }
+nav a { display: block; color: inherit; text-decoration: none; padding: .1rem 0; }
+nav a.current { font-weight: bold; }
+main { margin-left: 17rem; max-width: 60rem; padding: 1rem 2rem; }
+section.lesson { border-top: 1px solid #ddd; padding-top: 1rem; margin-top: 2rem; }
+.meta { color: #6e6e73; font-size: .9rem; }
+.tag { background: #e8e8ed; border-radius: .3rem; padding: 0 .4rem; margin-right: .3rem; }
+pre { background: #f5f5f7; padding: .8rem; overflow-x: auto; border-radius: .3rem; }
+pre.output { background: #1d1d1f; color: #f5f5f7; }
+code { font-family: ui-monospace, monospace; font-size: .9rem; }
+.kw { color: #ad3da4; font-weight: bold; } .bi { color: #3e8087; } .str { color: #c41a16; }
+.num { color: #1c00cf; } .com { color: #707f8c; font-style: italic; }
+`
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"highlight":   func(src string) template.HTML { return template.HTML(Highlight(src)) },
+	"prose":       prose,
+	"anchor":      Anchor,
+	"tryIt":       TryIt,
+	"chapterFile": chapterFile,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Chapter}}{{.Chapter.Name}} · {{end}}{{.Book.Title}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<nav>
+<a href="index.html"{{if not .Chapter}} class="current"{{end}}>{{.Book.Title}}</a>
+{{range .Book.Chapters}}<a href="{{chapterFile .}}"{{if eq . $.Chapter}} class="current"{{end}}>{{.Name}}</a>
+{{end}}</nav>
+<main>
+{{- with .Chapter}}
+<h1>{{.Name}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<ol>{{range .Lessons}}<li><a href="#{{anchor .}}">{{.Name}}</a></li>{{end}}</ol>
+{{range .Lessons}}
+<section class="lesson" id="{{anchor .}}">
+<h2>{{.Name}}</h2>
+<p class="meta">{{.Meta.Difficulty}}{{if .Meta.Minutes}} · {{.Meta.Minutes}} min{{end}}
+{{range .Meta.Tags}} <span class="tag">{{.}}</span>{{end}}</p>
+{{prose .}}
+<pre><code>{{highlight .Source}}</code></pre>
+{{if .HasOutput}}<p>Output{{if .Unordered}} (unordered){{end}}:</p>
+<pre class="output"><code>{{.Output}}</code></pre>{{end}}
+{{if .Meta.References}}<p>References:</p>
+<ul>{{range .Meta.References}}<li><a href="{{.URL}}">{{.Title}}</a></li>{{end}}</ul>{{end}}
+<p>Try it: <code>{{tryIt .}}</code></p>
+</section>
+{{end}}
+{{- else}}
+<h1>{{.Book.Title}}</h1>
+{{range .Book.Chapters}}<h2><a href="{{chapterFile .}}">{{.Name}}</a></h2>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<ul>{{$c := .}}{{range .Lessons}}<li><a href="{{chapterFile $c}}#{{anchor .}}">{{.Name}}</a></li>{{end}}</ul>
+{{end}}
+{{- end}}
+</main>
+</body>
+</html>
+`))
+
+// prose renders the doc comment of a lesson.
+func prose(l *lesson.Lesson) template.HTML {
+	var p comment.Parser
+	d := p.Parse(l.Doc)
+	pr := comment.Printer{HeadingLevel: 3}
+	return template.HTML(pr.HTML(d))
+}
+
+func chapterFile(c *Chapter) string {
+	return strings.ReplaceAll(c.Name, "/", "-") + ".html"
+}
+
+// WriteHTML writes index.html and one page per chapter into dir.
+func (b *Book) WriteHTML(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	write := func(name string, c *Chapter) error {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		data := struct {
+			Book    *Book
+			Chapter *Chapter
+			Style   template.CSS
+		}{b, c, template.CSS(style)}
+		if err := pageTemplate.Execute(f, data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	if err := write("index.html", nil); err != nil {
+		return err
+	}
+	for _, c := range b.Chapters {
+		if err := write(chapterFile(c), c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
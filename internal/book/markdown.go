@@ -0,0 +1,52 @@
+package book
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes the whole book as a single Markdown document.
+func (b *Book) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", b.Title)
+	for _, c := range b.Chapters {
+		fmt.Fprintf(&sb, "- [%s](#%s)\n", c.Name, c.Name)
+	}
+	for _, c := range b.Chapters {
+		fmt.Fprintf(&sb, "\n# %s\n\n", c.Name)
+		if c.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", c.Description)
+		}
+		for _, l := range c.Lessons {
+			fmt.Fprintf(&sb, "## %s\n\n", l.Name)
+			meta := []string{l.Meta.Difficulty.String()}
+			if l.Meta.Minutes > 0 {
+				meta = append(meta, fmt.Sprintf("%d min", l.Meta.Minutes))
+			}
+			for _, t := range l.Meta.Tags {
+				meta = append(meta, "`"+t+"`")
+			}
+			fmt.Fprintf(&sb, "_%s_\n\n", strings.Join(meta, " · "))
+
+			var p comment.Parser
+			pr := comment.Printer{HeadingLevel: 3}
+			sb.Write(pr.Markdown(p.Parse(l.Doc)))
+			fmt.Fprintf(&sb, "\n```go\n%s\n```\n\n", l.Source)
+			if l.HasOutput {
+				fmt.Fprintf(&sb, "Output:\n\n```text\n%s\n```\n\n", l.Output)
+			}
+			if len(l.Meta.References) > 0 {
+				sb.WriteString("References:\n\n")
+				for _, r := range l.Meta.References {
+					fmt.Fprintf(&sb, "- [%s](%s)\n", r.Title, r.URL)
+				}
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "Try it: `%s`\n\n", TryIt(l))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,141 @@
+// Package flashcard exports the Examples of the dojo as flashcards that Anki can import.
+//
+// The front of a card is the code of the Example, stripped like in quiz mode; the back is its expected output
+// followed by the comments explaining each line and the doc comment of the lesson. Cards are laid out in decks
+// mirroring the packages (`go-dojo::go-features::panic`) and tagged with the metadata of their lesson.
+//
+// Two formats are written, both using the file headers of the Anki text importer so no field mapping is needed:
+// tab separated values, and comma separated values for tools that only read CSV. Fields are HTML with no tab nor
+// newline, and each card has a stable GUID (its lesson ID) so that re-importing an updated export updates the
+// existing cards instead of duplicating them.
+package flashcard
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+	"github.com/juan-carvajal/go-dojo/internal/quiz"
+)
+
+// RootDeck is the parent deck of every card.
+const RootDeck = "go-dojo"
+
+// Card is the flashcard of an Example.
+type Card struct {
+	GUID  string // lesson ID
+	Deck  string
+	Front string // HTML
+	Back  string // HTML
+	Tags  []string
+}
+
+// Cards turns every Example with an expected output into a card, in index order.
+func Cards(lessons []*lesson.Lesson) ([]Card, error) {
+	var cards []Card
+	for _, l := range lessons {
+		if l.IsExercise() {
+			continue
+		}
+		q, err := quiz.New(l)
+		if errors.Is(err, quiz.ErrNoOutput) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", l.ID(), err)
+		}
+		cards = append(cards, New(q))
+	}
+	return cards, nil
+}
+
+// New makes the card of a quiz.
+func New(q *quiz.Quiz) Card {
+	l := q.Lesson
+	front := fmt.Sprintf("<p><b>%s</b>: what does it print?</p><pre><code>%s</code></pre>",
+		html.EscapeString(l.ID()), field(q.Code))
+
+	var back strings.Builder
+	output := "Output"
+	if l.Unordered {
+		output += " (in any order)"
+	}
+	fmt.Fprintf(&back, "<p>%s:</p><pre>%s</pre>", output, field(l.Output))
+	if len(q.Hints) > 0 {
+		back.WriteString("<ul>")
+		for _, h := range q.Hints {
+			fmt.Fprintf(&back, "<li><code>%s</code> %s</li>", field(h.Code), field(h.Comment))
+		}
+		back.WriteString("</ul>")
+	}
+	if l.Doc != "" {
+		fmt.Fprintf(&back, "<p>%s</p>", field(strings.TrimSpace(l.Doc)))
+	}
+
+	tags := []string{RootDeck}
+	if l.Meta.Difficulty != lesson.Unrated {
+		tags = append(tags, l.Meta.Difficulty.String())
+	}
+	tags = append(tags, l.Meta.Tags...)
+	return Card{
+		GUID:  l.ID(),
+		Deck:  RootDeck + "::" + strings.ReplaceAll(l.Package, "/", "::"),
+		Front: front,
+		Back:  back.String(),
+		Tags:  tags,
+	}
+}
+
+// field escapes text for an HTML field of a single line: newlines become <br> and tabs character references, which
+// keep the indentation of code in a <pre>.
+func field(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, "\t", "&#9;")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// header is the file header understood by the Anki text importer, see https://docs.ankiweb.net/importing/text-files.html.
+func header(separator string) string {
+	return "#separator:" + separator + "\n" +
+		"#html:true\n" +
+		"#notetype:Basic\n" +
+		"#columns:GUID\tDeck\tFront\tBack\tTags\n" +
+		"#guid column:1\n" +
+		"#deck column:2\n" +
+		"#tags column:5\n"
+}
+
+// WriteTSV writes the cards as tab separated values.
+func WriteTSV(w io.Writer, cards []Card) error {
+	var b strings.Builder
+	b.WriteString(header("tab"))
+	for _, c := range cards {
+		b.WriteString(strings.Join(c.row(), "\t"))
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCSV writes the cards as comma separated values.
+func WriteCSV(w io.Writer, cards []Card) error {
+	if _, err := io.WriteString(w, strings.Replace(header("comma"), "\t", ",", -1)); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	for _, c := range cards {
+		if err := cw.Write(c.row()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (c Card) row() []string {
+	return []string{c.GUID, c.Deck, c.Front, c.Back, strings.Join(c.Tags, " ")}
+}
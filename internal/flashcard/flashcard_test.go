@@ -0,0 +1,61 @@
+package flashcard
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+func Test_cards(t *testing.T) {
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+	idx, err := lesson.Discover(root)
+	require.NoError(t, err)
+	cards, err := Cards(idx.Lessons)
+	require.NoError(t, err)
+
+	var card *Card
+	for i := range cards {
+		require.NotContains(t, cards[i].GUID, "exercises")
+		if cards[i].GUID == "interfaces.Example_interfaceNilComparison" {
+			card = &cards[i]
+		}
+	}
+	require.NotNil(t, card)
+	require.Equal(t, "go-dojo::go-features::interfaces", card.Deck)
+	require.Contains(t, card.Tags, "pitfall")
+	require.Contains(t, card.Front, "func Example_interfaceNilComparison() {<br>&#9;")
+	require.NotContains(t, card.Front, "Output:")
+	require.Contains(t, card.Back, "Output:")
+}
+
+func Test_write(t *testing.T) {
+	cards := []Card{{
+		GUID:  "pkg.Example_x",
+		Deck:  "go-dojo::go-features::pkg",
+		Front: field("func f() {\n\tprintln(\"a, b\")\n}"),
+		Back:  field("a, b"),
+		Tags:  []string{"go-dojo", "beginner"},
+	}}
+	require.Equal(t, "func f() {<br>&#9;println(&#34;a, b&#34;)<br>}", cards[0].Front)
+
+	var tsv bytes.Buffer
+	require.NoError(t, WriteTSV(&tsv, cards))
+	lines := strings.Split(strings.TrimSuffix(tsv.String(), "\n"), "\n")
+	require.Equal(t, "#separator:tab", lines[0])
+	require.Equal(t, "pkg.Example_x\tgo-dojo::go-features::pkg\t"+cards[0].Front+"\ta, b\tgo-dojo beginner", lines[len(lines)-1])
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCSV(&out, cards))
+	require.Contains(t, out.String(), "#columns:GUID,Deck,Front,Back,Tags\n")
+	r := csv.NewReader(strings.NewReader(out.String()))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{cards[0].row()}, records)
+}
@@ -0,0 +1,57 @@
+// Package kata supports the exercise tracks of the dojo.
+//
+// Every lesson package can have an `exercises` sub package made of three kinds of files:
+//
+//   - stubs, built by default, whose function bodies are `panic("TODO")`,
+//   - reference solutions, behind the `solution` build tag,
+//   - tests grading whichever of the two is built.
+//
+// Grading tests wrap their body with Attempt, or Bubble for tests running on virtual time, so that stubs that were not
+// implemented yet are reported as skipped instead of failing the whole test run.
+package kata
+
+import (
+	"testing"
+	"testing/synctest"
+)
+
+// TODO is the panic value of the stubs waiting for an implementation.
+const TODO = "TODO"
+
+// SolutionTag is the build tag that swaps the stubs with their reference solutions.
+const SolutionTag = "solution"
+
+// Attempt runs a grading function, skipping the test when it reaches an unimplemented stub.
+func Attempt(t testing.TB, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			if r == TODO {
+				t.Skip("not implemented yet")
+			}
+			panic(r)
+		}
+	}()
+	f()
+}
+
+// Bubble is Attempt for tests that run in a testing/synctest bubble. The skip is reported on t itself, since skipping
+// the bubble's own T would still mark t as passed.
+func Bubble(t *testing.T, f func(t *testing.T)) {
+	t.Helper()
+	todo := false
+	synctest.Test(t, func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != nil {
+				if r != TODO {
+					panic(r)
+				}
+				todo = true
+			}
+		}()
+		f(t)
+	})
+	if todo {
+		t.Skip("not implemented yet")
+	}
+}
@@ -0,0 +1,81 @@
+package kata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+func Test_attemptSkipsStubs(t *testing.T) {
+	var skipped bool
+	t.Run("stub", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		Attempt(t, func() { panic(TODO) })
+	})
+	require.True(t, skipped)
+
+	t.Run("bubble", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		Bubble(t, func(t *testing.T) { panic(TODO) })
+	})
+	require.True(t, skipped)
+
+	require.PanicsWithValue(t, "boom", func() {
+		Attempt(t, func() { panic("boom") })
+	})
+}
+
+func Test_discoverTracks(t *testing.T) {
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+
+	tr, err := FindTrack(root, "concurrency")
+	require.NoError(t, err)
+	require.Equal(t, "go-features/concurrency/exercises", tr.Package)
+
+	ex, err := tr.Lookup("SendNTimes")
+	require.NoError(t, err)
+	require.Contains(t, ex.Stub, `panic("TODO")`)
+	require.Contains(t, ex.Solution, "defer close(ch)")
+	require.Equal(t, []string{"Test_SendNTimes", "Test_SendNTimesCancelled", "Test_SendNTimesReaderGone"}, ex.Tests)
+
+	_, err = FindTrack(root, "consts")
+	require.ErrorIs(t, err, ErrNoTrack)
+}
+
+func Test_gradeExercise(t *testing.T) {
+	ex := &Exercise{Tests: []string{"Test_A", "Test_AEmpty"}}
+	g := &Grade{Tests: map[string]Status{"Test_A": Passed, "Test_AEmpty": Passed}}
+	require.Equal(t, Passed, g.Exercise(ex))
+
+	g.Tests["Test_AEmpty"] = Todo
+	require.Equal(t, Todo, g.Exercise(ex))
+
+	delete(g.Tests, "Test_AEmpty") // never ran, e.g. a previous test crashed the binary
+	require.Equal(t, Failed, g.Exercise(ex))
+}
+
+func Test_checkSolutions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+	tr, err := FindTrack(root, "panic")
+	require.NoError(t, err)
+
+	g, err := tr.Check(context.Background(), root, CheckOptions{})
+	require.NoError(t, err)
+	for _, ex := range tr.Exercises {
+		require.Equal(t, Todo, g.Exercise(ex), ex.Name)
+	}
+
+	g, err = tr.Check(context.Background(), root, CheckOptions{Solution: true})
+	require.NoError(t, err)
+	for _, ex := range tr.Exercises {
+		require.Equal(t, Passed, g.Exercise(ex), ex.Name)
+	}
+}
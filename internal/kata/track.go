@@ -0,0 +1,265 @@
+package kata
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+// ErrNoTrack is returned when a package has no exercises.
+var ErrNoTrack = errors.New("no exercises")
+
+// Track is the `exercises` package of a lesson package.
+type Track struct {
+	Name      string // lesson package, e.g. concurrency
+	Package   string // slash separated directory relative to the module root
+	Exercises []*Exercise
+}
+
+// Exercise is a stub function of a track.
+type Exercise struct {
+	Name     string
+	Doc      string
+	Stub     string   // declaration built by default
+	Solution string   // declaration behind the solution build tag
+	Tests    []string // grading tests, named Test_<Name>...
+}
+
+// Status is the grade of an exercise or of a single test.
+type Status string
+
+const (
+	Todo   Status = "todo" // a stub was reached, the test skipped
+	Passed Status = "pass"
+	Failed Status = "fail"
+)
+
+// Grade is the outcome of `go test` over a track.
+type Grade struct {
+	Tests map[string]Status
+	Log   string // output of the failed tests
+}
+
+// Exercise grades an exercise from the status of its tests: failed if any failed, todo if any was skipped.
+func (g *Grade) Exercise(e *Exercise) Status {
+	status := Passed
+	for _, t := range e.Tests {
+		switch g.Tests[t] {
+		case Failed, "":
+			return Failed
+		case Todo:
+			status = Todo
+		}
+	}
+	return status
+}
+
+// DiscoverTracks finds every `exercises` package under the lesson roots of the module.
+func DiscoverTracks(root string) ([]*Track, error) {
+	var tracks []*Track
+	for _, r := range lesson.DefaultRoots {
+		err := filepath.WalkDir(filepath.Join(root, r), func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() || d.Name() != "exercises" {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			t, err := parseTrack(p, filepath.ToSlash(rel))
+			if err != nil {
+				return err
+			}
+			tracks = append(tracks, t)
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tracks, nil
+}
+
+// FindTrack returns the track of a lesson package, named like in `dojo list` (e.g. concurrency).
+func FindTrack(root, name string) (*Track, error) {
+	tracks, err := DiscoverTracks(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tracks {
+		if t.Name == name || t.Package == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoTrack, name)
+}
+
+// Lookup returns the exercise with the given name.
+func (t *Track) Lookup(name string) (*Exercise, error) {
+	for _, e := range t.Exercises {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no exercise %s", t.Name, name)
+}
+
+func parseTrack(dir, pkg string) (*Track, error) {
+	t := &Track{Name: strings.TrimSuffix(lesson.ShortPackage(pkg), "/exercises"), Package: pkg}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stubCtx, solutionCtx := build.Default, build.Default
+	solutionCtx.BuildTags = append(solutionCtx.BuildTags, SolutionTag)
+
+	byName := map[string]*Exercise{}
+	var tests []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		stub, _ := stubCtx.MatchFile(dir, name)
+		solution, _ := solutionCtx.MatchFile(dir, name)
+		if stub && solution && !strings.HasSuffix(name, "_test.go") {
+			continue // shared by both builds, e.g. errors
+		}
+		fset := token.NewFileSet()
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil {
+				continue
+			}
+			decl := string(src[fset.Position(fn.Pos()).Offset:fset.Position(fn.End()).Offset])
+			switch {
+			case strings.HasSuffix(name, "_test.go"):
+				if strings.HasPrefix(fn.Name.Name, "Test_") {
+					tests = append(tests, fn.Name.Name)
+				}
+			case !fn.Name.IsExported():
+			case stub:
+				ex := exercise(byName, t, fn.Name.Name)
+				ex.Doc, ex.Stub = fn.Doc.Text(), decl
+			case solution:
+				exercise(byName, t, fn.Name.Name).Solution = decl
+			}
+		}
+	}
+	for _, ex := range t.Exercises {
+		for _, test := range tests {
+			if rest, ok := strings.CutPrefix(test, "Test_"+ex.Name); ok && !startsWithLower(rest) {
+				ex.Tests = append(ex.Tests, test)
+			}
+		}
+	}
+	return t, nil
+}
+
+// startsWithLower tells if s continues an identifier, e.g. Test_MergeNothing belongs to Merge, but
+// Test_SendNTimesx would not belong to SendNTimes.
+func startsWithLower(s string) bool {
+	return s != "" && s[0] >= 'a' && s[0] <= 'z'
+}
+
+func exercise(byName map[string]*Exercise, t *Track, name string) *Exercise {
+	if e, ok := byName[name]; ok {
+		return e
+	}
+	e := &Exercise{Name: name}
+	byName[name] = e
+	t.Exercises = append(t.Exercises, e)
+	return e
+}
+
+// CheckOptions tweaks how a track is graded.
+type CheckOptions struct {
+	Race     bool
+	Solution bool // grade the reference solutions instead of the stubs
+}
+
+// Check runs the grading tests of the track with `go test -json`.
+func (t *Track) Check(ctx context.Context, root string, opts CheckOptions) (*Grade, error) {
+	args := []string{"test", "-count=1", "-json"}
+	if opts.Race {
+		args = append(args, "-race")
+	}
+	if opts.Solution {
+		args = append(args, "-tags", SolutionTag)
+	}
+	args = append(args, "./"+t.Package)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	g := &Grade{Tests: map[string]Status{}}
+	var log strings.Builder
+	outputs := map[string]*strings.Builder{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		var ev struct {
+			Action string
+			Test   string
+			Output string
+		}
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		if ev.Test == "" || strings.Contains(ev.Test, "/") {
+			if ev.Action == "output" && ev.Test == "" {
+				log.WriteString(ev.Output) // build failures, races and package level panics
+			}
+			continue
+		}
+		switch ev.Action {
+		case "output":
+			if outputs[ev.Test] == nil {
+				outputs[ev.Test] = &strings.Builder{}
+			}
+			outputs[ev.Test].WriteString(ev.Output)
+		case "pass":
+			g.Tests[ev.Test] = Passed
+		case "skip":
+			g.Tests[ev.Test] = Todo
+		case "fail":
+			g.Tests[ev.Test] = Failed
+			if o := outputs[ev.Test]; o != nil {
+				log.WriteString(o.String())
+			}
+		}
+	}
+	log.WriteString(stderr.String())
+	g.Log = log.String()
+	if err != nil && len(g.Tests) == 0 {
+		return g, fmt.Errorf("go test %s: %s", t.Package, strings.TrimSpace(g.Log))
+	}
+	return g, nil
+}
@@ -0,0 +1,255 @@
+// Package layout computes the memory layout of struct types for a given GOARCH with go/types, without compiling
+// nor running anything on that architecture.
+//
+// The sizes and alignments are those of the gc compiler (types.SizesFor), which follow the spec's guarantees
+// and, on 32-bit architectures, only align 64-bit values to 4 bytes.
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Arches are the architectures the layout lessons are checked on: the 32 and 64-bit flavours of x86 and ARM.
+var Arches = []string{"386", "arm", "amd64", "arm64"}
+
+// Field is a field of a struct and the padding that follows it.
+type Field struct {
+	Name    string
+	Type    string
+	Offset  int64
+	Size    int64
+	Align   int64
+	Padding int64 // bytes between the end of the field and the next field, or the end of the struct
+}
+
+// Struct is the layout of a struct type.
+type Struct struct {
+	Name   string // Type, or Func.Type for types declared in a function
+	Size   int64
+	Align  int64
+	Fields []Field
+
+	typ *types.Struct
+}
+
+// Padding is the number of bytes of the struct not used by any field.
+func (s Struct) Padding() int64 {
+	p := s.Size
+	for _, f := range s.Fields {
+		p -= f.Size
+	}
+	return p
+}
+
+// Sum writes the size as the sum of its fields and non-zero paddings, the way the lessons explain it: `1 + 7 + 8 + 2 + 6`.
+func (s Struct) Sum() string {
+	var terms []string
+	for _, f := range s.Fields {
+		terms = append(terms, strconv.FormatInt(f.Size, 10))
+		if f.Padding > 0 {
+			terms = append(terms, strconv.FormatInt(f.Padding, 10))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Sizes returns the gc sizes of arch.
+func Sizes(arch string) (types.Sizes, error) {
+	sizes := types.SizesFor("gc", arch)
+	if sizes == nil {
+		return nil, fmt.Errorf("unknown GOARCH %q", arch)
+	}
+	return sizes, nil
+}
+
+// Of computes the layout of st.
+func Of(name string, st *types.Struct, sizes types.Sizes) Struct {
+	vars := make([]*types.Var, st.NumFields())
+	for i := range vars {
+		vars[i] = st.Field(i)
+	}
+	offsets := sizes.Offsetsof(vars)
+	s := Struct{Name: name, Size: sizes.Sizeof(st), Align: sizes.Alignof(st), typ: st}
+	for i, v := range vars {
+		f := Field{
+			Name:   v.Name(),
+			Type:   types.TypeString(v.Type(), types.RelativeTo(v.Pkg())),
+			Offset: offsets[i],
+			Size:   sizes.Sizeof(v.Type()),
+			Align:  sizes.Alignof(v.Type()),
+		}
+		next := s.Size
+		if i+1 < len(vars) {
+			next = offsets[i+1]
+		}
+		f.Padding = next - f.Offset - f.Size
+		s.Fields = append(s.Fields, f)
+	}
+	return s
+}
+
+// Package type-checks the package in dir, test files of the package included, as built for arch and returns the
+// layout of every struct type it declares, in source order.
+func Package(dir, arch string) ([]Struct, error) {
+	sizes, err := Sizes(arch)
+	if err != nil {
+		return nil, err
+	}
+	ctxt := build.Default
+	ctxt.GOARCH = arch
+	ctxt.CgoEnabled = false
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	pkgName := ""
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(dir, e.Name()); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(f.Name.Name, "_test") {
+			continue // external test package
+		}
+		pkgName = f.Name.Name
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s for %s", dir, arch)
+	}
+
+	lookup, err := exports(dir, arch, files)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", lookup), Sizes: sizes}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	if _, err := conf.Check(pkgName, fset, files, info); err != nil {
+		return nil, err
+	}
+
+	var structs []Struct
+	add := func(prefix string, spec *ast.TypeSpec) {
+		obj := info.Defs[spec.Name]
+		if obj == nil {
+			return
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			return // generic, laid out per instantiation
+		}
+		if st, ok := obj.Type().Underlying().(*types.Struct); ok {
+			structs = append(structs, Of(prefix+spec.Name.Name, st, sizes))
+		}
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						add("", ts)
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Type.TypeParams != nil || d.Recv != nil && isGenericRecv(d.Recv) {
+					continue // local types may depend on the type parameters
+				}
+				ast.Inspect(d, func(n ast.Node) bool {
+					if ts, ok := n.(*ast.TypeSpec); ok {
+						add(d.Name.Name+".", ts)
+					}
+					return true
+				})
+			}
+		}
+	}
+	return structs, nil
+}
+
+// exports returns an importer lookup reading the export data of the imports of files, as compiled for arch by go
+// list: importing from source would type-check the dependencies for the host architecture instead.
+func exports(dir, arch string, files []*ast.File) (importer.Lookup, error) {
+	args := []string{"list", "-export", "-deps", "-f", "{{.ImportPath}}={{.Export}}"}
+	seen := map[string]bool{}
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if path != "unsafe" && path != "C" && !seen[path] {
+				seen[path] = true
+				args = append(args, path)
+			}
+		}
+	}
+	paths := map[string]string{}
+	if len(seen) > 0 {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOARCH="+arch, "CGO_ENABLED=0")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go list: %v: %s", err, stderr.String())
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if path, export, ok := strings.Cut(line, "="); ok && export != "" {
+				paths[path] = export
+			}
+		}
+	}
+	return func(path string) (io.ReadCloser, error) {
+		export, ok := paths[path]
+		if !ok {
+			export, ok = paths["vendor/"+path]
+		}
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	}, nil
+}
+
+// isGenericRecv tells if a method receiver has type parameters: T[K] or *T[K, V].
+func isGenericRecv(recv *ast.FieldList) bool {
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch typ.(type) {
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// Find returns the struct with the given name.
+func Find(structs []Struct, name string) (Struct, bool) {
+	for _, s := range structs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Struct{}, false
+}
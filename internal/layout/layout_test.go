@@ -0,0 +1,199 @@
+package layout
+
+import (
+	"go/build"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+const structsPkg = "go-features/structs"
+
+func repoRoot(t *testing.T) string {
+	t.Helper()
+	root, err := lesson.FindRoot(".")
+	require.NoError(t, err)
+	return root
+}
+
+var typesInt = types.Typ[types.Int]
+
+// bitsOf is the word size of arch, as the lesson comments name it: "64-bit".
+func bitsOf(t *testing.T, arch string) string {
+	t.Helper()
+	sizes, err := Sizes(arch)
+	require.NoError(t, err)
+	return strconv.Itoa(int(sizes.Sizeof(typesInt))*8) + "-bit"
+}
+
+// claim is a layout explained by a comment of the structs lessons, e.g. "The size of T1 is 24 (= 1 + 7 + 8 + 2 + 6)
+// bytes on 64-bit architectures".
+type claim struct {
+	bits   string // the word size of the architectures it is about, e.g. 64-bit
+	strukt string // e.g. Example_structMemoryAlignment.t1
+	size   string
+	sum    string // the field sizes and paddings, as Struct.Sum prints them
+}
+
+var (
+	sizeSentence = regexp.MustCompile(`The size of (\w+) is ([^.]+)\.`)
+	sizeSum      = regexp.MustCompile(`(\d+) \(= ([\d +]+)\)(?: bytes)?(?: on (\d+-bit) architectures)?`)
+)
+
+// claims returns the layouts the comments of l explain, those not naming their architectures being about the
+// architectures of bits, the ones l is built for.
+func claims(l *lesson.Lesson, bits string) []claim {
+	var text []string
+	for _, line := range strings.Split(l.Doc+"\n"+l.Source, "\n") {
+		if c, ok := strings.CutPrefix(strings.TrimSpace(line), "//"); ok {
+			text = append(text, c)
+		}
+	}
+	var cs []claim
+	for _, sentence := range sizeSentence.FindAllStringSubmatch(strings.Join(strings.Fields(strings.Join(text, " ")), " "), -1) {
+		for _, m := range sizeSum.FindAllStringSubmatch(sentence[2], -1) {
+			c := claim{bits: m[3], strukt: l.Name + "." + strings.ToLower(sentence[1]), size: m[1], sum: m[2]}
+			if c.bits == "" {
+				c.bits = bits
+			}
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+var sizeLine = regexp.MustCompile(`(?m)^Size of (\w+): (\d+)$`)
+
+// Test_structsLessons checks the layout comments and the expected outputs of the structs lessons of every
+// architecture against the sizes go/types computes for it. A comment in a file built for 64-bit architectures may
+// explain the 32-bit layout too: it is checked on the 32-bit ones.
+func Test_structsLessons(t *testing.T) {
+	root := repoRoot(t)
+	lessons := map[string][]*lesson.Lesson{}
+	explained := map[claim]bool{}
+	for _, arch := range Arches {
+		ctxt := build.Default
+		ctxt.GOARCH = arch
+		idx, err := lesson.DiscoverContext(&ctxt, root, structsPkg)
+		require.NoError(t, err)
+		lessons[arch] = idx.Lessons
+		for _, l := range idx.Lessons {
+			for _, c := range claims(l, bitsOf(t, arch)) {
+				explained[c] = true
+			}
+		}
+	}
+
+	for _, arch := range Arches {
+		t.Run(arch, func(t *testing.T) {
+			structs, err := Package(filepath.Join(root, structsPkg), arch)
+			require.NoError(t, err)
+			bits := bitsOf(t, arch)
+
+			for c := range explained {
+				if c.bits != bits {
+					continue
+				}
+				s, ok := Find(structs, c.strukt)
+				require.True(t, ok, c.strukt)
+				require.Equal(t, c.sum, s.Sum(), "%s on %s architectures", c.strukt, bits)
+				require.Equal(t, c.size, strconv.FormatInt(s.Size, 10), "%s on %s architectures", c.strukt, bits)
+			}
+
+			checked := 0
+			for _, l := range lessons[arch] {
+				for _, m := range sizeLine.FindAllStringSubmatch(l.Output, -1) {
+					s, ok := Find(structs, l.Name+"."+m[1])
+					require.True(t, ok, "%s declares %s", l.Name, m[1])
+					require.Equal(t, m[2], strconv.FormatInt(s.Size, 10), "%s: %s", l.ID(), m[0])
+					checked++
+				}
+			}
+			require.Equal(t, 3, checked)
+		})
+	}
+	require.Len(t, explained, 4, "t1 and t2 of Example_structMemoryAlignment, on 64-bit and 32-bit architectures")
+}
+
+// qemu are the qemu-user emulators of each architecture.
+var qemu = map[string]string{"386": "qemu-i386", "arm": "qemu-arm", "amd64": "qemu-x86_64", "arm64": "qemu-aarch64"}
+
+// Test_structsLessonsCrossArch cross-compiles the structs lessons for every architecture and runs them natively or
+// under qemu-user when it is installed. The go/types check of Test_structsLessons covers the others.
+func Test_structsLessonsCrossArch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross-compiles the lessons")
+	}
+	root := repoRoot(t)
+	for _, arch := range Arches {
+		t.Run(arch, func(t *testing.T) {
+			bin := filepath.Join(t.TempDir(), "structs.test")
+			build := exec.Command("go", "test", "-c", "-o", bin, "./"+structsPkg)
+			build.Dir = root
+			build.Env = append(os.Environ(), "GOARCH="+arch, "CGO_ENABLED=0")
+			out, err := build.CombinedOutput()
+			require.NoError(t, err, string(out))
+
+			var cmd *exec.Cmd
+			switch {
+			case arch == runtime.GOARCH || (arch == "386" && runtime.GOARCH == "amd64" && runtime.GOOS == "linux"):
+				cmd = exec.Command(bin)
+			default:
+				emulator, err := exec.LookPath(qemu[arch])
+				if err != nil {
+					emulator, err = exec.LookPath(qemu[arch] + "-static")
+				}
+				if err != nil {
+					t.Skipf("%s is not installed, %s layouts are only checked with go/types", qemu[arch], arch)
+				}
+				cmd = exec.Command(emulator, bin)
+			}
+			cmd.Args = append(cmd.Args, "-test.run", "^Example", "-test.v")
+			out, err = cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			require.Contains(t, string(out), "--- PASS: Example_structMemoryAlignment ")
+		})
+	}
+}
+
+func Test_optimizeAndMap(t *testing.T) {
+	structs, err := Package(filepath.Join(repoRoot(t), structsPkg), "amd64")
+	require.NoError(t, err)
+	sizes, err := Sizes("amd64")
+	require.NoError(t, err)
+
+	s, ok := Find(structs, "Example_structMemoryAlignment.t1")
+	require.True(t, ok)
+	require.Equal(t, int64(24), s.Size)
+	require.Equal(t, int64(13), s.Padding())
+
+	bytes, legend := Map(s, 8)
+	require.Equal(t, `    0  1  2  3  4  5  6  7
+ 0  a  .  .  .  .  .  .  .
+ 8  b  b  b  b  b  b  b  b
+16  c  c  .  .  .  .  .  .
+`, bytes)
+	require.Equal(t, `a a int8 (offset 0, size 1, align 1, 7 padding)
+b b int64 (offset 8, size 8, align 8)
+c c int16 (offset 16, size 2, align 2, 6 padding)
+. padding
+`, legend)
+
+	opt := Optimize(s, sizes)
+	require.Equal(t, "8 + 2 + 1 + 5", opt.Sum(), "the order of Example_structMemoryAlignmentOptimized")
+	require.Equal(t, "type t1 struct {\n\tb int64\n\tc int16\n\ta int8\n}", Declaration(opt))
+
+	singer, ok := Find(structs, "Singer")
+	require.True(t, ok)
+	require.Equal(t, "type Singer struct {\n\tPerson\n\tworks []string\n}", Declaration(Optimize(singer, sizes)))
+}
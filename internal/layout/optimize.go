@@ -0,0 +1,155 @@
+package layout
+
+import (
+	"cmp"
+	"fmt"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Optimize reorders the fields of s to minimize its size: zero-sized fields first, since a trailing one is padded to
+// keep pointers past it inside the struct, then by decreasing alignment. As the size of every Go type is a multiple of
+// its alignment, no padding is left between fields and only the tail is padded to the alignment of the struct.
+// Fields of equal alignment keep their order.
+func Optimize(s Struct, sizes types.Sizes) Struct {
+	n := s.typ.NumFields()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		a, b := s.typ.Field(i).Type(), s.typ.Field(j).Type()
+		zi, zj := sizes.Sizeof(a) == 0, sizes.Sizeof(b) == 0
+		if zi != zj {
+			if zi {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(sizes.Alignof(b), sizes.Alignof(a))
+	})
+	vars := make([]*types.Var, n)
+	tags := make([]string, n)
+	for i, o := range order {
+		vars[i] = s.typ.Field(o)
+		tags[i] = s.typ.Tag(o)
+	}
+	return Of(s.Name, types.NewStruct(vars, tags), sizes)
+}
+
+// Declaration writes s as a type declaration. Comments of the original declaration are lost.
+func Declaration(s Struct) string {
+	name := s.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	width := 0
+	for i := range s.typ.NumFields() {
+		if v := s.typ.Field(i); !v.Embedded() {
+			width = max(width, len(v.Name()))
+		}
+	}
+	for i := range s.typ.NumFields() {
+		v := s.typ.Field(i)
+		typ := types.TypeString(v.Type(), types.RelativeTo(v.Pkg()))
+		line := typ
+		if !v.Embedded() {
+			line = fmt.Sprintf("%-*s %s", width, v.Name(), typ)
+		}
+		switch tag := s.typ.Tag(i); {
+		case strings.Contains(tag, "`"):
+			line += " " + strconv.Quote(tag)
+		case tag != "":
+			line += " `" + tag + "`"
+		}
+		fmt.Fprintf(&b, "\t%s\n", line)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// padding is the cell of a padding byte in a Map.
+const padding = '.'
+
+// Map draws the bytes of s, word by word, with one letter per field and dots for padding. Runs of identical lines
+// are collapsed to a `*` line like hexdump does. The legend lists the letter of every field.
+func Map(s Struct, word int64) (bytes, legend string) {
+	labels := make([]rune, len(s.Fields))
+	used := map[rune]bool{padding: true}
+	for i, f := range s.Fields {
+		candidates := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+		if f.Name != "" && f.Name != "_" {
+			r := []rune(f.Name)[0]
+			candidates = string(r) + strings.ToLower(string(r)) + strings.ToUpper(string(r)) + candidates
+		}
+		labels[i] = '?'
+		for _, r := range candidates {
+			if !used[r] {
+				labels[i] = r
+				used[r] = true
+				break
+			}
+		}
+	}
+
+	cells := make([]rune, s.Size)
+	for i := range cells {
+		cells[i] = padding
+	}
+	for i, f := range s.Fields {
+		for o := f.Offset; o < f.Offset+f.Size; o++ {
+			cells[o] = labels[i]
+		}
+	}
+
+	var b strings.Builder
+	offWidth := len(strconv.FormatInt(max(s.Size-1, 0), 10))
+	fmt.Fprintf(&b, "%*s", offWidth, "")
+	for i := range word {
+		fmt.Fprintf(&b, " %2d", i)
+	}
+	b.WriteString("\n")
+	prev, collapsed := "", false
+	for off := int64(0); off < s.Size; off += word {
+		var line strings.Builder
+		for _, c := range cells[off:min(off+word, s.Size)] {
+			fmt.Fprintf(&line, "  %c", c)
+		}
+		if line.String() == prev && off+word < s.Size {
+			if !collapsed {
+				b.WriteString("*\n")
+				collapsed = true
+			}
+			continue
+		}
+		prev, collapsed = line.String(), false
+		fmt.Fprintf(&b, "%*d%s\n", offWidth, off, line.String())
+	}
+
+	var l strings.Builder
+	for i, f := range s.Fields {
+		name := f.Name
+		if name == "" {
+			name = "_"
+		}
+		fmt.Fprintf(&l, "%c %-*s %s (offset %d, size %d, align %d", labels[i], nameWidth(s), name, f.Type, f.Offset, f.Size, f.Align)
+		if f.Padding > 0 {
+			fmt.Fprintf(&l, ", %d padding", f.Padding)
+		}
+		l.WriteString(")\n")
+	}
+	fmt.Fprintf(&l, "%c padding\n", padding)
+	return b.String(), l.String()
+}
+
+func nameWidth(s Struct) int {
+	w := 0
+	for _, f := range s.Fields {
+		w = max(w, len(f.Name))
+	}
+	return w
+}
@@ -0,0 +1,159 @@
+// Package leak finds goroutines that outlive the code that started them.
+//
+// It compares the goroutines running before and after: a goroutine that started meanwhile and is still there is a
+// leak, unless it is one of the goroutines the runtime and the standard library keep for themselves, like the one
+// delivering signals. Goroutines get a moment to exit, since some end right after the code that started them
+// returns, e.g. after their last wg.Done().
+//
+// Packages check all their Examples and Tests at once with a TestMain:
+//
+//	func TestMain(m *testing.M) { leak.Main(m) }
+//
+// Or each Test with leak.Check(t). A leak is reported with the stack of the goroutine and the function that started
+// it. The stacks of the goroutines that called that function are only known to the runtime when the test binary
+// runs with GODEBUG=tracebackancestors=N, N being how many ancestors to show.
+package leak
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Wait is how long Main and Check give goroutines to exit before reporting them.
+const Wait = time.Second
+
+// Goroutine is a goroutine, as runtime.Stack shows it.
+type Goroutine struct {
+	ID    int
+	State string // why it is waiting, e.g. "chan send", or "running"
+	Stack string // its stack, then the function that started it, and the ancestors when known
+}
+
+// Func returns the function the goroutine is in, e.g. `main.worker`.
+func (g Goroutine) Func() string {
+	f, _, _ := strings.Cut(g.Stack, "\n")
+	if i := strings.LastIndexByte(f, '('); i > 0 {
+		f = f[:i]
+	}
+	return f
+}
+
+// CreatedBy returns the function that started the goroutine, empty for the main goroutine.
+func (g Goroutine) CreatedBy() string {
+	_, f, ok := strings.Cut(g.Stack, "created by ")
+	if !ok {
+		return ""
+	}
+	f, _, _ = strings.Cut(f, "\n")
+	f, _, _ = strings.Cut(f, " in goroutine ")
+	return f
+}
+
+func (g Goroutine) String() string {
+	return fmt.Sprintf("goroutine %d [%s]:\n%s", g.ID, g.State, g.Stack)
+}
+
+// ignored are the functions of the goroutines that the runtime and the standard library start on demand and keep.
+var ignored = []string{
+	"os/signal.signal_recv", // delivers signals, from the first signal.Notify
+	"os/signal.loop",
+	"runtime.ensureSigM", // moves signal masks around, for signal.Notify too
+	"runtime.ReadTrace",
+}
+
+// Snapshot returns the goroutines running now, except the caller's and the ignored ones.
+func Snapshot() []Goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	gs := parse(string(buf))
+	return slices.DeleteFunc(gs[1:], func(g Goroutine) bool { // the first one is the caller
+		return slices.Contains(ignored, g.Func())
+	})
+}
+
+// parse reads the output of runtime.Stack: goroutines separated by an empty line, each starting with a header like
+// `goroutine 8 [chan send, 2 minutes]:`.
+func parse(dump string) []Goroutine {
+	var gs []Goroutine
+	for _, block := range strings.Split(strings.TrimSpace(dump), "\n\n") {
+		header, stack, _ := strings.Cut(block, "\n")
+		header, ok := strings.CutPrefix(header, "goroutine ")
+		if !ok {
+			continue
+		}
+		id, state, _ := strings.Cut(strings.TrimSuffix(header, ":"), " ")
+		g := Goroutine{Stack: stack}
+		g.ID, _ = strconv.Atoi(id)
+		g.State = strings.Trim(state, "[]")
+		if i := strings.Index(g.State, ", "); i > 0 && strings.HasSuffix(g.State, "minutes") {
+			g.State = g.State[:i] // the time spent waiting isn't part of the state
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
+
+// Find returns the goroutines running now that are not in before, waiting up to wait for them to exit.
+func Find(before []Goroutine, wait time.Duration) []Goroutine {
+	deadline := time.Now().Add(wait)
+	for delay := time.Millisecond; ; delay *= 2 {
+		leaked := slices.DeleteFunc(Snapshot(), func(g Goroutine) bool {
+			return slices.ContainsFunc(before, func(b Goroutine) bool { return b.ID == g.ID })
+		})
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(min(delay, time.Until(deadline), 100*time.Millisecond))
+	}
+}
+
+// Report describes leaked goroutines, with their stacks.
+func Report(leaked []Goroutine) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "leaked goroutines: %d\n", len(leaked))
+	for _, g := range leaked {
+		fmt.Fprintf(&b, "\n%s\n", g)
+	}
+	if !strings.Contains(os.Getenv("GODEBUG"), "tracebackancestors=") {
+		b.WriteString("\nRun with GODEBUG=tracebackancestors=10 to see the stacks of the goroutines that started them.\n")
+	}
+	return b.String()
+}
+
+// Check fails t if goroutines started during t are still running at its end.
+func Check(t testing.TB) {
+	t.Helper()
+	before := Snapshot()
+	t.Cleanup(func() {
+		if leaked := Find(before, Wait); len(leaked) > 0 {
+			t.Error(Report(leaked))
+		}
+	})
+}
+
+// Main runs the tests and examples of m, then fails the run if goroutines started by them are still running. It
+// doesn't return.
+func Main(m *testing.M) {
+	before := Snapshot()
+	code := m.Run()
+	if code == 0 {
+		if leaked := Find(before, Wait); len(leaked) > 0 {
+			fmt.Fprint(os.Stderr, Report(leaked))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
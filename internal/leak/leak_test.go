@@ -0,0 +1,76 @@
+package leak
+
+import (
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) { Main(m) }
+
+func Test_findLeak(t *testing.T) {
+	before := Snapshot()
+	ch := make(chan int)
+	go func() { ch <- 1 }()
+
+	leaked := Find(before, 10*time.Millisecond)
+	require.Len(t, leaked, 1)
+	g := leaked[0]
+	require.Equal(t, "chan send", g.State)
+	require.Equal(t, "github.com/juan-carvajal/go-dojo/internal/leak.Test_findLeak.func1", g.Func())
+	require.Equal(t, "github.com/juan-carvajal/go-dojo/internal/leak.Test_findLeak", g.CreatedBy())
+	require.Contains(t, Report(leaked), "leaked goroutines: 1\n\ngoroutine ")
+	require.Contains(t, Report(leaked), " [chan send]:\ngithub.com/juan-carvajal/go-dojo/internal/leak.Test_findLeak.func1()\n")
+
+	<-ch
+	require.Empty(t, Find(before, Wait))
+}
+
+func Test_findWaitsForExits(t *testing.T) {
+	before := Snapshot()
+	go time.Sleep(50 * time.Millisecond)
+	require.Empty(t, Find(before, Wait))
+}
+
+func Test_snapshotIgnoresSignals(t *testing.T) {
+	before := Snapshot()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	defer signal.Stop(c)
+	require.Empty(t, Find(before, 0))
+}
+
+func Test_parse(t *testing.T) {
+	gs := parse(`goroutine 1 [running]:
+main.main()
+	/src/main.go:22 +0x9b
+
+goroutine 8 [chan send, 2 minutes]:
+main.leak.func1()
+	/src/main.go:12 +0x1e
+created by main.leak in goroutine 7
+	/src/main.go:12 +0x67
+[originating from goroutine 7]:
+main.leak(...)
+	/src/main.go:13 +0x67
+
+goroutine 9 [chan receive (durable), synctest bubble 2]:
+main.wait()
+	/src/main.go:30 +0x1e
+created by main.main in goroutine 1
+	/src/main.go:20 +0x67
+`)
+	require.Len(t, gs, 3)
+	require.Equal(t, Goroutine{ID: 1, State: "running", Stack: "main.main()\n\t/src/main.go:22 +0x9b"}, gs[0])
+	require.Equal(t, "", gs[0].CreatedBy())
+	require.Equal(t, 8, gs[1].ID)
+	require.Equal(t, "chan send", gs[1].State)
+	require.Equal(t, "main.leak.func1", gs[1].Func())
+	require.Equal(t, "main.leak", gs[1].CreatedBy())
+	require.Contains(t, gs[1].Stack, "[originating from goroutine 7]:\nmain.leak(...)")
+	require.Equal(t, "chan receive (durable), synctest bubble 2", gs[2].State)
+	require.Equal(t, "main.main", gs[2].CreatedBy())
+}
@@ -0,0 +1,226 @@
+package lesson
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultRoots are the directories, relative to the module root, that contain lessons.
+var DefaultRoots = []string{"go-features", "protocols"}
+
+// ErrNotFound is returned by Index.Lookup when no lesson matches.
+var ErrNotFound = errors.New("lesson not found")
+
+// outputPrefix matches the comment that `go test` uses as expected output of an Example.
+var outputPrefix = regexp.MustCompile(`(?i)^[[:space:]]*(unordered )?output:`)
+
+// Index is the list of every lesson of the dojo, in package, file and declaration order.
+type Index struct {
+	Root    string // module root
+	Lessons []*Lesson
+}
+
+// FindRoot walks up from dir until it finds the directory holding go.mod.
+func FindRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// Discover parses every package under the given roots (DefaultRoots when none) of the module at root.
+// Only files that match the current build context are parsed, so files behind build tags (e.g. reference solutions)
+// stay hidden.
+func Discover(root string, roots ...string) (*Index, error) {
+	return DiscoverContext(&build.Default, root, roots...)
+}
+
+// DiscoverContext is like Discover for the files matching ctxt, e.g. those of another GOARCH.
+func DiscoverContext(ctxt *build.Context, root string, roots ...string) (*Index, error) {
+	if len(roots) == 0 {
+		roots = DefaultRoots
+	}
+	idx := &Index{Root: root}
+	for _, r := range roots {
+		err := filepath.WalkDir(filepath.Join(root, r), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if name := d.Name(); p != filepath.Join(root, r) && (strings.HasPrefix(name, ".") || name == "testdata") {
+				return filepath.SkipDir
+			}
+			lessons, err := parseDir(ctxt, root, p)
+			if err != nil {
+				return err
+			}
+			idx.Lessons = append(idx.Lessons, lessons...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+func parseDir(ctxt *build.Context, root, dir string) ([]*Lesson, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	var lessons []*Lesson
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(dir, e.Name()); err != nil || !ok {
+			continue
+		}
+		fileLessons, err := parseFile(filepath.Join(dir, e.Name()), filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, fileLessons...)
+	}
+	return lessons, nil
+}
+
+func parseFile(filename, pkg string) ([]*Lesson, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var lessons []*Lesson
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+		l := &Lesson{
+			Name:    fn.Name.Name,
+			Package: pkg,
+			File:    filename,
+			Line:    fset.Position(fn.Type.Func).Line,
+		}
+		switch {
+		case strings.HasPrefix(l.Name, "Example") && len(fn.Type.Params.List) == 0:
+			l.Kind = Example
+		case strings.HasPrefix(l.Name, "Test_"):
+			l.Kind = Test
+		default:
+			continue
+		}
+		var comments []string
+		if fn.Doc != nil {
+			l.Doc = fn.Doc.Text()
+			for _, c := range fn.Doc.List {
+				comments = append(comments, c.Text)
+			}
+		}
+		l.Meta = parseMeta(comments, l.Doc)
+		l.Source = string(src[fset.Position(fn.Pos()).Offset:fset.Position(fn.End()).Offset])
+		if l.Kind == Example {
+			findOutput(fset, f, fn, l)
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, nil
+}
+
+// findOutput locates the last comment group of the body starting with `Output:`, like `go test` does.
+func findOutput(fset *token.FileSet, f *ast.File, fn *ast.FuncDecl, l *Lesson) {
+	var last *ast.CommentGroup
+	for _, cg := range f.Comments {
+		if cg.Pos() < fn.Body.Lbrace || cg.End() > fn.Body.Rbrace {
+			continue
+		}
+		if IsOutputComment(cg.Text()) {
+			last = cg
+		}
+	}
+	if last == nil {
+		return
+	}
+	text := last.Text()
+	m := outputPrefix.FindStringSubmatchIndex(text)
+	l.HasOutput = true
+	l.Unordered = m[2] >= 0
+	l.Output = strings.TrimSpace(text[m[1]:])
+	l.outputStart = fset.Position(last.Pos()).Offset
+	l.outputEnd = fset.Position(last.End()).Offset
+}
+
+// IsOutputComment tells if the text of a comment group is the expected output of an Example.
+func IsOutputComment(text string) bool {
+	return outputPrefix.MatchString(text)
+}
+
+// Packages returns the distinct lesson packages in index order.
+func (idx *Index) Packages() []string {
+	var pkgs []string
+	seen := map[string]bool{}
+	for _, l := range idx.Lessons {
+		if !seen[l.Package] {
+			seen[l.Package] = true
+			pkgs = append(pkgs, l.Package)
+		}
+	}
+	return pkgs
+}
+
+// Lookup finds a lesson by ID (`panic.Example_panicChain2`) or by function name when it is unique across the dojo.
+func (idx *Index) Lookup(name string) (*Lesson, error) {
+	var matches []*Lesson
+	for _, l := range idx.Lessons {
+		if l.ID() == name {
+			return l, nil
+		}
+		if l.Name == name {
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, len(matches))
+	for i, l := range matches {
+		ids[i] = l.ID()
+	}
+	sort.Strings(ids)
+	return nil, fmt.Errorf("lesson %s is ambiguous, use one of: %s", name, strings.Join(ids, ", "))
+}
@@ -0,0 +1,77 @@
+package lesson
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func discoverRepo(t *testing.T) *Index {
+	t.Helper()
+	root, err := FindRoot(".")
+	require.NoError(t, err)
+	idx, err := Discover(root)
+	require.NoError(t, err)
+	return idx
+}
+
+func Test_discoverFindsExamplesAndTests(t *testing.T) {
+	idx := discoverRepo(t)
+
+	l, err := idx.Lookup("Example_panicChain2")
+	require.NoError(t, err)
+	require.Equal(t, "panic.Example_panicChain2", l.ID())
+	require.Equal(t, "go-features/panic", l.Package)
+	require.Equal(t, Example, l.Kind)
+	require.True(t, l.HasOutput)
+	require.Equal(t, "third\nsecond\nfirst\nRecovered from panic: panic first", l.Output)
+	require.Contains(t, l.Doc, "deferred functions up to the point")
+	require.Contains(t, l.Source, "func Example_panicChain2() {")
+
+	l, err = idx.Lookup("switch.Test_switchOrder")
+	require.NoError(t, err)
+	require.Equal(t, Test, l.Kind)
+
+	l, err = idx.Lookup("Example_http2_TLS")
+	require.NoError(t, err)
+	require.Equal(t, "protocols", l.Chapter())
+
+	require.Contains(t, idx.Packages(), "go-features/structs")
+}
+
+func Test_lookupErrors(t *testing.T) {
+	idx := &Index{Lessons: []*Lesson{
+		{Name: "Example_a", Package: "go-features/x"},
+		{Name: "Example_a", Package: "go-features/y"},
+	}}
+	_, err := idx.Lookup("Example_missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = idx.Lookup("Example_a")
+	require.EqualError(t, err, "lesson Example_a is ambiguous, use one of: x.Example_a, y.Example_a")
+
+	l, err := idx.Lookup("y.Example_a")
+	require.NoError(t, err)
+	require.Equal(t, "go-features/y", l.Package)
+}
+
+func Test_matches(t *testing.T) {
+	require.True(t, Matches("a\nb", "a\nb\n", false))
+	require.False(t, Matches("a\nb", "b\na", false))
+	require.True(t, Matches("a\nb", "b\na", true))
+}
+
+func Test_runCapturesOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	idx := discoverRepo(t)
+	l, err := idx.Lookup("Example_iotaSkip")
+	require.NoError(t, err)
+
+	res, err := l.Run(context.Background(), idx.Root, RunOptions{})
+	require.NoError(t, err)
+	require.True(t, res.Passed, res.Log)
+	require.Equal(t, "1\n2\n3\n8", res.Output)
+}
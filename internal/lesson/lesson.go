@@ -0,0 +1,94 @@
+// Package lesson discovers the runnable lessons of the dojo.
+//
+// A lesson is any `Example*` or `Test_*` function declared in the `_test.go` files under the lesson roots
+// (`go-features` and `protocols` by default). The doc comment of the function is the lesson prose, its body is the
+// code, and the `// Output:` comment of an Example is the expected output.
+package lesson
+
+import "strings"
+
+// Kind tells how a lesson is executed by `go test`.
+type Kind int
+
+const (
+	Example Kind = iota // Example* function, compared against its `// Output:` comment
+	Test                // Test_* function, passes or fails on its own
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Example:
+		return "example"
+	case Test:
+		return "test"
+	}
+	return "unknown"
+}
+
+// Lesson is a single Example or Test function found in the dojo.
+type Lesson struct {
+	Name    string // function name, e.g. Example_panicChain2
+	Kind    Kind
+	Package string // slash separated directory relative to the module root, e.g. go-features/panic
+	File    string // absolute path of the file declaring the lesson
+	Line    int    // line of the func keyword
+	Doc     string // doc comment text, without the comment markers
+	Source  string // function declaration, without its doc comment
+
+	// Output is the expected output of an Example (empty for tests). HasOutput is false for Examples without an
+	// `// Output:` comment, which `go test` compiles but never runs.
+	Output    string
+	HasOutput bool
+	Unordered bool
+
+	Meta Meta
+
+	// byte offsets of the output comment group inside File, used to rewrite it when running the lesson.
+	outputStart, outputEnd int
+}
+
+// Short is the package path without the `go-features/` prefix: `panic`, `concurrency/exercises`, `protocols`.
+func (l *Lesson) Short() string {
+	return ShortPackage(l.Package)
+}
+
+// ID uniquely identifies a lesson across the dojo, e.g. `panic.Example_panicChain2`.
+func (l *Lesson) ID() string {
+	return l.Short() + "." + l.Name
+}
+
+// Summary is the first sentence of the doc comment.
+func (l *Lesson) Summary() string {
+	return FirstSentence(l.Doc)
+}
+
+// FirstSentence returns the first sentence of a doc comment, on a single line.
+func FirstSentence(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return doc
+}
+
+// ShortPackage trims the `go-features/` prefix from a package directory.
+func ShortPackage(pkg string) string {
+	if rest, ok := strings.CutPrefix(pkg, "go-features/"); ok {
+		return rest
+	}
+	return pkg
+}
+
+// IsExercise tells if the lesson is a grading test of an exercise track.
+func (l *Lesson) IsExercise() bool {
+	return strings.HasSuffix(l.Package, "/exercises")
+}
+
+// Chapter is the top level package a lesson belongs to, e.g. `concurrency` for `concurrency/exercises`.
+func (l *Lesson) Chapter() string {
+	short := l.Short()
+	if i := strings.Index(short, "/"); i >= 0 {
+		return short[:i]
+	}
+	return short
+}
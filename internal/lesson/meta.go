@@ -0,0 +1,244 @@
+package lesson
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Difficulty of a lesson, used to order the learning path.
+type Difficulty int
+
+const (
+	Unrated Difficulty = iota
+	Beginner
+	Intermediate
+	Advanced
+)
+
+var difficulties = []string{"unrated", "beginner", "intermediate", "advanced"}
+
+func (d Difficulty) String() string {
+	if int(d) < len(difficulties) {
+		return difficulties[d]
+	}
+	return "unknown"
+}
+
+// Tags lessons can be labelled with. Keeping a closed list catches typos that would split a tag in two.
+var Tags = []string{
+	"basics",      // first contact with a feature
+	"concurrency", // goroutines, channels and synchronization
+	"http",        // net/http and the protocols on top of it
+	"memory",      // layout, allocation and aliasing
+	"pitfall",     // surprising behavior that bites in production
+	"runtime",     // behavior implemented by the runtime rather than the compiler
+	"security",    // attacks and hardening
+	"spec",        // behavior defined by the language specification
+	"testing",     // testing and tooling techniques
+}
+
+// Reference is an external resource of a lesson.
+type Reference struct {
+	Title string
+	URL   string
+}
+
+// Meta is the machine-readable metadata of a lesson, declared with `//dojo:meta` directives at the end of its doc
+// comment:
+//
+//	// Example_panicChain2 shows how ...
+//	//
+//	//dojo:meta difficulty=intermediate minutes=5 tags=pitfall,runtime
+//	//dojo:meta requires=Example_panicChain
+//
+// Keys are difficulty (beginner, intermediate or advanced), minutes, tags (see Tags) and requires, a comma separated
+// list of prerequisite lessons given by ID, or by name for lessons of the same package. References are not declared
+// but collected from the doc links (`[Title]: URL`) and bare URLs of the doc comment.
+type Meta struct {
+	Difficulty Difficulty
+	Minutes    int
+	Tags       []string
+	Requires   []string
+	References []Reference
+
+	errs []error // invalid directives, reported by Index.Validate
+}
+
+// HasTag tells if the lesson is labelled with tag.
+func (m *Meta) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, tag)
+}
+
+const metaDirective = "//dojo:meta"
+
+var (
+	docLink = regexp.MustCompile(`(?m)^\[([^\]]+)\]: (\S+)$`)
+	bareURL = regexp.MustCompile(`https?://[^\s()<>]+[^\s()<>.,:;'"]`)
+)
+
+// parseMeta reads the directives of the raw doc comment lines and the references of the doc text.
+func parseMeta(comments []string, doc string) Meta {
+	var m Meta
+	for _, c := range comments {
+		rest, ok := strings.CutPrefix(c, metaDirective)
+		if !ok {
+			continue
+		}
+		if rest != "" && rest[0] != ' ' {
+			m.errs = append(m.errs, fmt.Errorf("unknown directive %q", c))
+			continue
+		}
+		for _, field := range strings.Fields(rest) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || value == "" {
+				m.errs = append(m.errs, fmt.Errorf("malformed %s field %q, want key=value", metaDirective, field))
+				continue
+			}
+			m.set(key, value)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, l := range docLink.FindAllStringSubmatch(doc, -1) {
+		seen[l[2]] = true
+		m.References = append(m.References, Reference{Title: l[1], URL: l[2]})
+	}
+	for _, u := range bareURL.FindAllString(doc, -1) {
+		if !seen[u] {
+			seen[u] = true
+			m.References = append(m.References, Reference{Title: u, URL: u})
+		}
+	}
+	return m
+}
+
+func (m *Meta) set(key, value string) {
+	switch key {
+	case "difficulty":
+		i := slices.Index(difficulties, value)
+		if i <= 0 {
+			m.errs = append(m.errs, fmt.Errorf("unknown difficulty %q", value))
+			return
+		}
+		m.Difficulty = Difficulty(i)
+	case "minutes":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			m.errs = append(m.errs, fmt.Errorf("minutes must be a positive number, got %q", value))
+			return
+		}
+		m.Minutes = n
+	case "tags":
+		for _, t := range strings.Split(value, ",") {
+			if !slices.Contains(Tags, t) {
+				m.errs = append(m.errs, fmt.Errorf("unknown tag %q", t))
+				continue
+			}
+			m.Tags = append(m.Tags, t)
+		}
+	case "requires":
+		m.Requires = append(m.Requires, strings.Split(value, ",")...)
+	default:
+		m.errs = append(m.errs, fmt.Errorf("unknown %s key %q", metaDirective, key))
+	}
+}
+
+// Prerequisites resolves the lessons required by l.
+func (idx *Index) Prerequisites(l *Lesson) ([]*Lesson, error) {
+	var reqs []*Lesson
+	for _, name := range l.Meta.Requires {
+		req, err := idx.Lookup(l.Short() + "." + name)
+		if errors.Is(err, ErrNotFound) {
+			req, err = idx.Lookup(name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("prerequisite %s: %w", name, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// Validate checks the metadata of every lesson: directives must be well-formed, lessons outside exercise tracks must
+// be rated, and prerequisites must exist and not form a cycle.
+func (idx *Index) Validate() error {
+	var errs []error
+	for _, l := range idx.Lessons {
+		for _, err := range l.Meta.errs {
+			errs = append(errs, fmt.Errorf("%s: %w", l.ID(), err))
+		}
+		if l.Meta.Difficulty == Unrated && !l.IsExercise() {
+			errs = append(errs, fmt.Errorf("%s: missing %s difficulty", l.ID(), metaDirective))
+		}
+		if _, err := idx.Prerequisites(l); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", l.ID(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	_, err := idx.Path()
+	return err
+}
+
+// Path orders the lessons so that every lesson comes after its prerequisites. Among the lessons whose prerequisites
+// are met, easier lessons come first, then the index order is kept.
+// The grading tests of exercise tracks are not part of the path.
+func (idx *Index) Path() ([]*Lesson, error) {
+	var lessons []*Lesson
+	for _, l := range idx.Lessons {
+		if !l.IsExercise() {
+			lessons = append(lessons, l)
+		}
+	}
+	pos := make(map[*Lesson]int, len(lessons))
+	for i, l := range lessons {
+		pos[l] = i
+	}
+	pending := make(map[*Lesson]int, len(lessons)) // number of prerequisites not in the path yet
+	unlocks := make(map[*Lesson][]*Lesson)
+	for _, l := range lessons {
+		reqs, err := idx.Prerequisites(l)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", l.ID(), err)
+		}
+		pending[l] = len(reqs)
+		for _, r := range reqs {
+			unlocks[r] = append(unlocks[r], l)
+		}
+	}
+
+	var ready, path []*Lesson
+	for _, l := range lessons {
+		if pending[l] == 0 {
+			ready = append(ready, l)
+		}
+	}
+	for len(ready) > 0 {
+		next := slices.MinFunc(ready, func(a, b *Lesson) int {
+			return cmp.Or(cmp.Compare(a.Meta.Difficulty, b.Meta.Difficulty), cmp.Compare(pos[a], pos[b]))
+		})
+		ready = slices.DeleteFunc(ready, func(l *Lesson) bool { return l == next })
+		path = append(path, next)
+		for _, l := range unlocks[next] {
+			if pending[l]--; pending[l] == 0 {
+				ready = append(ready, l)
+			}
+		}
+	}
+	if len(path) < len(lessons) {
+		var cycle []string
+		for _, l := range lessons {
+			if pending[l] > 0 {
+				cycle = append(cycle, l.ID())
+			}
+		}
+		return nil, fmt.Errorf("prerequisites form a cycle between %s", strings.Join(cycle, ", "))
+	}
+	return path, nil
+}
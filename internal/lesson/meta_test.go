@@ -0,0 +1,81 @@
+package lesson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_metadataIsValid fails the test run when a lesson has invalid metadata or references a missing prerequisite.
+func Test_metadataIsValid(t *testing.T) {
+	idx := discoverRepo(t)
+	require.NoError(t, idx.Validate())
+}
+
+func Test_parseMeta(t *testing.T) {
+	m := parseMeta([]string{
+		"// Example_x shows something, see [Spec].",
+		"//",
+		"//dojo:meta difficulty=advanced minutes=5",
+		"//dojo:meta tags=pitfall,spec requires=Example_a,consts.Example_b",
+	}, "Example_x shows something, see https://go.dev/wiki/MethodSets.\n\n[Spec]: https://go.dev/ref/spec\n")
+	require.Empty(t, m.errs)
+	require.Equal(t, Advanced, m.Difficulty)
+	require.Equal(t, 5, m.Minutes)
+	require.Equal(t, []string{"pitfall", "spec"}, m.Tags)
+	require.True(t, m.HasTag("spec"))
+	require.Equal(t, []string{"Example_a", "consts.Example_b"}, m.Requires)
+	require.Equal(t, []Reference{
+		{Title: "Spec", URL: "https://go.dev/ref/spec"},
+		{Title: "https://go.dev/wiki/MethodSets", URL: "https://go.dev/wiki/MethodSets"},
+	}, m.References)
+}
+
+func Test_parseMetaErrors(t *testing.T) {
+	m := parseMeta([]string{
+		"//dojo:metadata difficulty=easy",
+		"//dojo:meta difficulty=easy minutes=-1 tags=pitfal owner=me broken",
+	}, "")
+	require.Len(t, m.errs, 6)
+	require.EqualError(t, m.errs[0], `unknown directive "//dojo:metadata difficulty=easy"`)
+	require.EqualError(t, m.errs[1], `unknown difficulty "easy"`)
+	require.EqualError(t, m.errs[2], `minutes must be a positive number, got "-1"`)
+	require.EqualError(t, m.errs[3], `unknown tag "pitfal"`)
+	require.EqualError(t, m.errs[4], `unknown //dojo:meta key "owner"`)
+	require.EqualError(t, m.errs[5], `malformed //dojo:meta field "broken", want key=value`)
+}
+
+func Test_validate(t *testing.T) {
+	beginner := Meta{Difficulty: Beginner}
+	idx := &Index{Lessons: []*Lesson{
+		{Name: "Example_a", Package: "go-features/x", Meta: Meta{Difficulty: Beginner, Requires: []string{"Example_missing"}}},
+		{Name: "Example_b", Package: "go-features/x"},
+		{Name: "Test_B", Package: "go-features/x/exercises"},
+		{Name: "Example_c", Package: "go-features/y", Meta: beginner},
+	}}
+	require.EqualError(t, idx.Validate(), ""+
+		"x.Example_a: prerequisite Example_missing: lesson not found: Example_missing\n"+
+		"x.Example_b: missing //dojo:meta difficulty")
+
+	idx.Lessons[0].Meta.Requires = []string{"y.Example_c"}
+	idx.Lessons[1].Meta = Meta{Difficulty: Beginner, Requires: []string{"Example_a"}}
+	idx.Lessons[3].Meta.Requires = []string{"x.Example_b"}
+	require.EqualError(t, idx.Validate(), "prerequisites form a cycle between x.Example_a, x.Example_b, y.Example_c")
+}
+
+func Test_path(t *testing.T) {
+	idx := &Index{Lessons: []*Lesson{
+		{Name: "Example_hard", Package: "go-features/x", Meta: Meta{Difficulty: Advanced}},
+		{Name: "Example_a", Package: "go-features/x", Meta: Meta{Difficulty: Intermediate, Requires: []string{"y.Example_b"}}},
+		{Name: "Test_A", Package: "go-features/x/exercises"},
+		{Name: "Example_b", Package: "go-features/y", Meta: Meta{Difficulty: Intermediate}},
+		{Name: "Example_c", Package: "go-features/y", Meta: Meta{Difficulty: Beginner}},
+	}}
+	path, err := idx.Path()
+	require.NoError(t, err)
+	var ids []string
+	for _, l := range path {
+		ids = append(ids, l.ID())
+	}
+	require.Equal(t, []string{"y.Example_c", "y.Example_b", "x.Example_a", "x.Example_hard"}, ids)
+}
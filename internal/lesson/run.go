@@ -0,0 +1,142 @@
+package lesson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sentinel replaces the expected output of an Example so that `go test` always reports what the Example printed.
+const sentinel = "dojo: capture output"
+
+var gotOutput = regexp.MustCompile(`(?s)\ngot:\n(.*)\nwant:\n` + regexp.QuoteMeta(sentinel) + `\n`)
+
+// Result is the outcome of running a lesson.
+type Result struct {
+	Output string // what the lesson printed; the verbose `go test` log for tests
+	Passed bool   // the output matched the `// Output:` comment, or the test passed
+	Log    string // raw `go test` output
+}
+
+// RunOptions tweaks how a lesson is run.
+type RunOptions struct {
+	Tags []string // extra build tags, e.g. "solution"
+	Race bool
+}
+
+// Run executes the lesson with `go test` from the module root and captures its actual output.
+//
+// Examples are run with their `// Output:` comment replaced through a `-overlay`, so the printed output is available
+// even when it matches the expectation (`go test` only shows it on failure).
+func (l *Lesson) Run(ctx context.Context, root string, opts RunOptions) (*Result, error) {
+	args := []string{"test", "-count=1", "-run", "^" + l.Name + "$"}
+	if opts.Race {
+		args = append(args, "-race")
+	}
+	if len(opts.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.Tags, ","))
+	}
+	capture := l.Kind == Example && l.HasOutput
+	if capture {
+		overlay, cleanup, err := l.overlay()
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		args = append(args, "-overlay", overlay)
+	}
+	if l.Kind == Test {
+		args = append(args, "-v")
+	}
+	args = append(args, "./"+l.Package)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	res := &Result{Log: string(out)}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	if !capture {
+		res.Output = res.Log
+		res.Passed = err == nil
+		return res, nil
+	}
+	m := gotOutput.FindStringSubmatch(res.Log)
+	if m == nil {
+		// The example did not get to compare its output (build failure, unrecovered panic, ...).
+		res.Output = res.Log
+		return res, nil
+	}
+	res.Output = m[1]
+	res.Passed = l.Matches(res.Output)
+	return res, nil
+}
+
+// Matches compares an output with the expected output of the lesson, the same way `go test` does.
+func (l *Lesson) Matches(got string) bool {
+	return Matches(l.Output, got, l.Unordered)
+}
+
+// Matches compares two outputs ignoring surrounding spaces; unordered outputs are compared as sorted lines.
+func Matches(want, got string, unordered bool) bool {
+	want, got = strings.TrimSpace(want), strings.TrimSpace(got)
+	if !unordered {
+		return want == got
+	}
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range w {
+		w[i] = strings.TrimSpace(w[i])
+	}
+	for i := range g {
+		g[i] = strings.TrimSpace(g[i])
+	}
+	slices.Sort(w)
+	slices.Sort(g)
+	return slices.Equal(w, g)
+}
+
+// overlay writes a copy of the lesson file whose `// Output:` comment expects the sentinel, and the `-overlay` JSON
+// pointing `go test` at it.
+func (l *Lesson) overlay() (string, func(), error) {
+	src, err := os.ReadFile(l.File)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp("", "dojo-overlay")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	var buf bytes.Buffer
+	buf.Write(src[:l.outputStart])
+	buf.WriteString("// Output:\n// " + sentinel)
+	buf.Write(src[l.outputEnd:])
+	replaced := filepath.Join(dir, filepath.Base(l.File))
+	if err := os.WriteFile(replaced, buf.Bytes(), 0o644); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	cfg, err := json.Marshal(map[string]map[string]string{"Replace": {l.File: replaced}})
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	overlay := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlay, cfg, 0o644); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return overlay, cleanup, nil
+}
@@ -0,0 +1,121 @@
+// Package progress records what each learner did in the dojo.
+//
+// The store is a flat JSON Lines file, `$XDG_STATE_HOME/go-dojo/progress.jsonl` by default, where every lesson run,
+// quiz attempt and exercise grade is appended as one event. Being append-only, it can be shared by several dojo
+// processes and survives being edited or truncated by hand.
+package progress
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Kind is what a learner did.
+type Kind string
+
+const (
+	Run      Kind = "run"      // ran a lesson
+	Quiz     Kind = "quiz"     // predicted the output of an Example
+	Exercise Kind = "exercise" // graded an exercise
+)
+
+// Event is a line of the store.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Learner string    `json:"learner"`
+	Kind    Kind      `json:"kind"`
+	Lesson  string    `json:"lesson"`  // lesson ID, or <track>/exercises.<Exercise> for exercises
+	Chapter string    `json:"chapter"` // top level package, e.g. panic
+	Passed  bool      `json:"passed"`
+	Score   float64   `json:"score,omitempty"` // fraction of the output predicted by a quiz
+}
+
+// Store is the progress file.
+type Store struct {
+	Path string
+	now  func() time.Time
+}
+
+// DefaultPath is progress.jsonl in the go-dojo directory of $XDG_STATE_HOME, ~/.local/state when unset.
+func DefaultPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "progress.jsonl"), nil
+}
+
+// StateDir is the go-dojo directory of $XDG_STATE_HOME, ~/.local/state/go-dojo when unset.
+func StateDir() (string, error) {
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		state = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(state, "go-dojo"), nil
+}
+
+// Open returns the store at path. The file is only created on the first Record.
+func Open(path string) *Store {
+	return &Store{Path: path, now: time.Now}
+}
+
+// Record appends an event, stamping it with the current time when it has none.
+func (s *Store) Record(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = s.now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Events returns the events of a learner, oldest first. Every learner's events are returned when learner is empty.
+func (s *Store) Events(learner string) ([]Event, error) {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.Path, n, err)
+		}
+		if learner == "" || e.Learner == learner {
+			events = append(events, e)
+		}
+	}
+	return events, sc.Err()
+}
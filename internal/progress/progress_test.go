@@ -0,0 +1,108 @@
+package progress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+func Test_defaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	p, err := DefaultPath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.FromSlash("/state/go-dojo/progress.jsonl"), p)
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/dojo")
+	p, err = DefaultPath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.FromSlash("/home/dojo/.local/state/go-dojo/progress.jsonl"), p)
+}
+
+func Test_recordAndReadEvents(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "nested", "progress.jsonl"))
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	events, err := s.Events("")
+	require.NoError(t, err)
+	require.Empty(t, events, "a missing file has no events")
+
+	require.NoError(t, s.Record(Event{Learner: "ana", Kind: Run, Lesson: "panic.Example_panicChain", Passed: true}))
+	require.NoError(t, s.Record(Event{Learner: "bob", Kind: Quiz, Lesson: "consts.Example_iotaSkip", Score: 0.5}))
+
+	events, err = s.Events("ana")
+	require.NoError(t, err)
+	require.Equal(t, []Event{{Time: now, Learner: "ana", Kind: Run, Lesson: "panic.Example_panicChain", Passed: true}}, events)
+
+	events, err = s.Events("")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	require.NoError(t, os.WriteFile(s.Path, []byte("{nope\n"), 0o644))
+	_, err = s.Events("")
+	require.ErrorContains(t, err, "progress.jsonl:1")
+}
+
+func testItems(t *testing.T) []Item {
+	intermediate := lesson.Meta{Difficulty: lesson.Intermediate}
+	idx := &lesson.Index{Lessons: []*lesson.Lesson{
+		{Name: "Example_a", Package: "go-features/panic", Meta: intermediate},
+		{Name: "Example_b", Package: "go-features/panic", Meta: lesson.Meta{Difficulty: lesson.Advanced}},
+		{Name: "Test_SafeCall", Package: "go-features/panic/exercises"},
+		{Name: "Example_c", Package: "go-features/consts", Meta: intermediate},
+	}}
+	tracks := []*kata.Track{{
+		Name:      "panic",
+		Package:   "go-features/panic/exercises",
+		Exercises: []*kata.Exercise{{Name: "SafeCall", Doc: "SafeCall calls f. More."}},
+	}}
+	items, err := Items(idx, tracks)
+	require.NoError(t, err)
+	return items
+}
+
+func Test_items(t *testing.T) {
+	require.Equal(t, []Item{
+		{ID: "panic.Example_a", Chapter: "panic", Kind: Run},
+		{ID: "consts.Example_c", Chapter: "consts", Kind: Run},
+		{ID: "panic.Example_b", Chapter: "panic", Kind: Run},
+		{ID: "panic/exercises.SafeCall", Chapter: "panic", Kind: Exercise, Summary: "calls f."},
+	}, testItems(t), "easier lessons first, exercises after the last lesson of their chapter")
+}
+
+func Test_reportAndNext(t *testing.T) {
+	items := testItems(t)
+	last := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Kind: Run, Lesson: "panic.Example_a", Chapter: "panic", Passed: true, Time: last.Add(-time.Hour)},
+		{Kind: Quiz, Lesson: "panic.Example_b", Chapter: "panic", Score: 0.5, Time: last},
+		{Kind: Exercise, Lesson: "panic/exercises.SafeCall", Chapter: "panic", Passed: true},
+	}
+
+	report := Report(items, events)
+	require.Equal(t, []*Chapter{
+		{Name: "panic", Lessons: 2, LessonsDone: 1, Exercises: 1, ExercisesDone: 1, LastActivity: last},
+		{Name: "consts", Lessons: 1},
+	}, report)
+	require.False(t, report[0].Done())
+
+	next, ok := Next(items, events)
+	require.True(t, ok)
+	require.Equal(t, "consts.Example_c", next.ID)
+
+	events = append(events, Event{Kind: Run, Lesson: "consts.Example_c", Passed: true})
+	next, ok = Next(items, events)
+	require.True(t, ok)
+	require.Equal(t, "panic.Example_b", next.ID, "an imperfect quiz does not complete a lesson")
+
+	events = append(events, Event{Kind: Quiz, Lesson: "panic.Example_b", Passed: true, Score: 1})
+	_, ok = Next(items, events)
+	require.False(t, ok)
+}
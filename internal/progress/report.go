@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/internal/kata"
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+// Item is something a learner can complete: a lesson or an exercise.
+type Item struct {
+	ID      string
+	Chapter string
+	Kind    Kind // Run for lessons, Exercise for exercises
+	Summary string
+}
+
+// ExerciseID identifies an exercise the same way lessons are identified, e.g. concurrency/exercises.SendNTimes.
+func ExerciseID(t *kata.Track, e *kata.Exercise) string {
+	return lesson.ShortPackage(t.Package) + "." + e.Name
+}
+
+// Items lists what can be completed, following the learning path of the index: lessons come after their
+// prerequisites, and the exercises of a chapter right after its last lesson.
+func Items(idx *lesson.Index, tracks []*kata.Track) ([]Item, error) {
+	path, err := idx.Path()
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	added := map[string]bool{}
+	addExercises := func(chapter string) {
+		for _, t := range tracks {
+			if t.Name != chapter || added[t.Package] {
+				continue
+			}
+			added[t.Package] = true
+			for _, e := range t.Exercises {
+				summary := strings.TrimPrefix(lesson.FirstSentence(e.Doc), e.Name+" ")
+				items = append(items, Item{ID: ExerciseID(t, e), Chapter: t.Name, Kind: Exercise, Summary: summary})
+			}
+		}
+	}
+	last := map[string]int{} // index in path of the last lesson of each chapter
+	for i, l := range path {
+		last[l.Chapter()] = i
+	}
+	for i, l := range path {
+		summary := strings.TrimPrefix(l.Summary(), l.Name+" ")
+		items = append(items, Item{ID: l.ID(), Chapter: l.Chapter(), Kind: Run, Summary: summary})
+		if last[l.Chapter()] == i {
+			addExercises(l.Chapter())
+		}
+	}
+	for _, t := range tracks {
+		addExercises(t.Name) // tracks of chapters without lessons
+	}
+	return items, nil
+}
+
+// Completed returns the IDs of the items a learner passed at least once: a successful run, a perfect quiz or a
+// passing exercise.
+func Completed(events []Event) map[string]bool {
+	done := map[string]bool{}
+	for _, e := range events {
+		if e.Passed {
+			done[e.Lesson] = true
+		}
+	}
+	return done
+}
+
+// Chapter is the completion of a top level package.
+type Chapter struct {
+	Name          string
+	Lessons       int
+	LessonsDone   int
+	Exercises     int
+	ExercisesDone int
+	LastActivity  time.Time
+}
+
+// Done tells if every lesson and exercise of the chapter is completed.
+func (c *Chapter) Done() bool {
+	return c.LessonsDone == c.Lessons && c.ExercisesDone == c.Exercises
+}
+
+// Report computes the completion of every chapter, in the order of the items.
+func Report(items []Item, events []Event) []*Chapter {
+	done := Completed(events)
+	var chapters []*Chapter
+	byName := map[string]*Chapter{}
+	for _, it := range items {
+		c, ok := byName[it.Chapter]
+		if !ok {
+			c = &Chapter{Name: it.Chapter}
+			byName[it.Chapter] = c
+			chapters = append(chapters, c)
+		}
+		switch it.Kind {
+		case Exercise:
+			c.Exercises++
+			if done[it.ID] {
+				c.ExercisesDone++
+			}
+		default:
+			c.Lessons++
+			if done[it.ID] {
+				c.LessonsDone++
+			}
+		}
+	}
+	for _, e := range events {
+		if c, ok := byName[e.Chapter]; ok && e.Time.After(c.LastActivity) {
+			c.LastActivity = e.Time
+		}
+	}
+	return chapters
+}
+
+// Next recommends the first item that is not completed yet, false when everything is.
+func Next(items []Item, events []Event) (Item, bool) {
+	done := Completed(events)
+	for _, it := range items {
+		if !done[it.ID] {
+			return it, true
+		}
+	}
+	return Item{}, false
+}
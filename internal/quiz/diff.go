@@ -0,0 +1,69 @@
+package quiz
+
+// Op is the kind of a line in a diff.
+type Op int
+
+const (
+	Equal   Op = iota // line both predicted and printed
+	Missing           // line printed by the lesson but not predicted
+	Extra             // line predicted but never printed
+)
+
+func (o Op) String() string {
+	switch o {
+	case Missing:
+		return "-"
+	case Extra:
+		return "+"
+	}
+	return " "
+}
+
+// DiffLine is a line of a line-level diff.
+type DiffLine struct {
+	Op   Op
+	Text string
+}
+
+// Diff computes a line-level diff between the actual lines (want) and the predicted ones (got), based on their
+// longest common subsequence.
+func Diff(want, got []string) []DiffLine {
+	// lcs[i][j] is the length of the LCS of want[i:] and got[j:].
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(want) && j < len(got) {
+		switch {
+		case want[i] == got[j]:
+			diff = append(diff, DiffLine{Equal, want[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Missing, want[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Extra, got[j]})
+			j++
+		}
+	}
+	for ; i < len(want); i++ {
+		diff = append(diff, DiffLine{Missing, want[i]})
+	}
+	for ; j < len(got); j++ {
+		diff = append(diff, DiffLine{Extra, got[j]})
+	}
+	return diff
+}
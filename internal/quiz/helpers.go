@@ -0,0 +1,163 @@
+package quiz
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+// decl is a package-level declaration of the package of a lesson.
+type decl struct {
+	src     string          // with its doc comment
+	defines []string        // the names it declares, the type of its receiver for a method
+	uses    map[string]bool // the identifiers it refers to, selectors aside
+}
+
+// helpers returns the source of the package-level declarations of its package the Example l refers to, directly or
+// through one another, in the order of their files: the output may well come from them. Methods come with their
+// type. It returns "" for a lesson without a file, as in tests.
+func helpers(l *lesson.Lesson) (string, error) {
+	if l.File == "" {
+		return "", nil
+	}
+	dir := filepath.Dir(l.File)
+	fset := token.NewFileSet()
+	head, err := parser.ParseFile(fset, l.File, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var decls []*decl
+	var example *decl
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, e.Name()); err != nil || !ok {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return "", err
+		}
+		if f.Name.Name != head.Name.Name {
+			continue // the external test package, or the other way around
+		}
+		for _, n := range f.Decls {
+			d := &decl{uses: identifiers(n)}
+			start := n.Pos()
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Doc != nil {
+					start = n.Doc.Pos()
+				}
+				if n.Recv != nil {
+					d.defines = []string{receiverType(n.Recv.List[0].Type)}
+				} else {
+					d.defines = []string{n.Name.Name}
+				}
+				if path == l.File && n.Recv == nil && n.Name.Name == l.Name {
+					example = d
+					continue
+				}
+			case *ast.GenDecl:
+				if n.Tok == token.IMPORT {
+					continue
+				}
+				if n.Doc != nil {
+					start = n.Doc.Pos()
+				}
+				for _, spec := range n.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						d.defines = append(d.defines, spec.Name.Name)
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							d.defines = append(d.defines, name.Name)
+						}
+					}
+				}
+			}
+			d.src = string(src[fset.Position(start).Offset:fset.Position(n.End()).Offset])
+			decls = append(decls, d)
+		}
+	}
+	if example == nil {
+		return "", nil
+	}
+
+	used := make(map[*decl]bool)
+	for queue := []*decl{example}; len(queue) > 0; queue = queue[1:] {
+		for _, d := range decls {
+			if used[d] {
+				continue
+			}
+			for _, name := range d.defines {
+				if queue[0].uses[name] {
+					used[d] = true
+					queue = append(queue, d)
+					break
+				}
+			}
+		}
+	}
+	var out []string
+	for _, d := range decls {
+		if used[d] {
+			out = append(out, d.src)
+		}
+	}
+	return strings.Join(out, "\n\n"), nil
+}
+
+// identifiers returns the identifiers n refers to, leaving out the selected ones of selector expressions: fmt.Println
+// doesn't use a Println of the package.
+func identifiers(n ast.Node) map[string]bool {
+	ids := make(map[string]bool)
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			ids[n.Name] = true
+		}
+		return true
+	}
+	ast.Inspect(n, visit)
+	return ids
+}
+
+// receiverType returns the name of the type of a method receiver: T for T, *T, T[K] and *T[K].
+func receiverType(x ast.Expr) string {
+	for {
+		switch t := x.(type) {
+		case *ast.StarExpr:
+			x = t.X
+		case *ast.IndexExpr:
+			x = t.X
+		case *ast.IndexListExpr:
+			x = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
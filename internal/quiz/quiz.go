@@ -0,0 +1,169 @@
+// Package quiz turns Examples into "predict the output" exercises.
+//
+// The code shown to the learner has its `// Output:` comment removed, as well as every inline comment that explains
+// a line (`fmt.Println(len(s7), cap(s7), s7) // 3 4 [3 4 88]`), since those usually give the answer away. The hidden
+// comments are kept as hints and revealed once the learner has answered.
+//
+// The Example is followed by the package-level functions, types and variables it uses, whose code often prints part
+// of the output.
+package quiz
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+// ErrNoOutput is returned for lessons that have no expected output to predict.
+var ErrNoOutput = errors.New("lesson has no output to predict")
+
+// Hint is an inline comment hidden from the quiz code.
+type Hint struct {
+	Line    int    // 1-based line in Quiz.Code
+	Code    string // the line of code the comment explains, trimmed
+	Comment string // the comment, including its `//` marker
+}
+
+// Quiz is an Example ready to be answered.
+type Quiz struct {
+	Lesson *lesson.Lesson
+	Code   string
+	Hints  []Hint
+}
+
+// New prepares the quiz of an Example lesson.
+func New(l *lesson.Lesson) (*Quiz, error) {
+	if l.Kind != lesson.Example || !l.HasOutput {
+		return nil, ErrNoOutput
+	}
+	src := l.Source
+	h, err := helpers(l)
+	if err != nil {
+		return nil, err
+	}
+	if h != "" {
+		src += "\n\n" + h
+	}
+	code, hints, err := strip(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Quiz{Lesson: l, Code: code, Hints: hints}, nil
+}
+
+// strip removes the output comment and the trailing comments of the declarations in src.
+func strip(src string) (string, []Hint, error) {
+	const header = "package p\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", header+src, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	lines := strings.Split(src, "\n")
+	remove := make(map[int]bool)  // 0-based lines to drop entirely
+	trailing := make(map[int]int) // 0-based line -> column where its trailing comment starts
+
+	var output *ast.CommentGroup
+	for _, cg := range f.Comments {
+		if lesson.IsOutputComment(cg.Text()) {
+			output = cg
+		}
+	}
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			pos := fset.Position(c.Pos())
+			line := pos.Line - 2 // header line and 1-based lines
+			if cg == output {
+				for l := line; l <= fset.Position(c.End()).Line-2; l++ {
+					remove[l] = true
+				}
+				// and the blank lines separating it from the code
+				for l := line - 1; l >= 0 && strings.TrimSpace(lines[l]) == ""; l-- {
+					remove[l] = true
+				}
+				continue
+			}
+			col := pos.Column - 1
+			if strings.TrimSpace(lines[line][:col]) != "" {
+				if _, ok := trailing[line]; !ok {
+					trailing[line] = col
+				}
+			}
+		}
+	}
+
+	var out []string
+	var hints []Hint
+	for i, line := range lines {
+		if remove[i] {
+			continue
+		}
+		if col, ok := trailing[i]; ok {
+			code := strings.TrimRight(line[:col], " \t")
+			hints = append(hints, Hint{Line: len(out) + 1, Code: strings.TrimSpace(code), Comment: line[col:]})
+			line = code
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), hints, nil
+}
+
+// Answer is the graded prediction of a learner.
+type Answer struct {
+	Diff    []DiffLine
+	Correct int // predicted lines that match the actual output
+	Total   int // lines in the longest of the prediction and the actual output
+}
+
+// Score is the fraction of lines predicted correctly, 1 for a perfect answer.
+func (a *Answer) Score() float64 {
+	if a.Total == 0 {
+		return 1
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+// Perfect tells if the prediction matched the actual output exactly.
+func (a *Answer) Perfect() bool {
+	return a.Correct == a.Total
+}
+
+// Grade compares the predicted lines with the actual output of the lesson.
+func (q *Quiz) Grade(predicted []string, actual string) *Answer {
+	want := splitLines(actual)
+	got := make([]string, 0, len(predicted))
+	for _, p := range predicted {
+		got = append(got, strings.TrimSpace(p))
+	}
+	for len(got) > 0 && got[len(got)-1] == "" {
+		got = got[:len(got)-1]
+	}
+	if q.Lesson.Unordered {
+		slices.Sort(want)
+		slices.Sort(got)
+	}
+	a := &Answer{Diff: Diff(want, got), Total: max(len(want), len(got))}
+	for _, d := range a.Diff {
+		if d.Op == Equal {
+			a.Correct++
+		}
+	}
+	return a
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
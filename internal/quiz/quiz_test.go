@@ -0,0 +1,114 @@
+package quiz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/lesson"
+)
+
+const exampleSource = `func Example_x() {
+	s := []int{1} // a one-element slice
+	// A full line comment stays.
+	fmt.Println(len(s)) // 1
+
+	// Output:
+	// 1
+}`
+
+func Test_newStripsOutputAndInlineComments(t *testing.T) {
+	q, err := New(&lesson.Lesson{Kind: lesson.Example, HasOutput: true, Output: "1", Source: exampleSource})
+	require.NoError(t, err)
+	require.Equal(t, `func Example_x() {
+	s := []int{1}
+	// A full line comment stays.
+	fmt.Println(len(s))
+}`, q.Code)
+	require.Equal(t, []Hint{
+		{Line: 2, Code: "s := []int{1}", Comment: "// a one-element slice"},
+		{Line: 4, Code: "fmt.Println(len(s))", Comment: "// 1"},
+	}, q.Hints)
+}
+
+func Test_newShowsHelpers(t *testing.T) {
+	const src = `package p
+
+import "fmt"
+
+const unused = 1
+
+// greeter greets in its language.
+type greeter struct{ lang string }
+
+func (g greeter) greet() string { return hello[g.lang] + "!" }
+
+var hello = map[string]string{"es": "hola"}
+
+func newGreeter() greeter { return greeter{lang: "es"} }
+
+func Example_x() {
+	fmt.Println(newGreeter().greet()) // hola!
+
+	// Output:
+	// hola!
+}
+
+func Example_unused() {
+	fmt.Println(unused)
+}
+`
+	file := filepath.Join(t.TempDir(), "x_test.go")
+	require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+	source := src[strings.Index(src, "func Example_x"):strings.Index(src, "\n\nfunc Example_unused")]
+	q, err := New(&lesson.Lesson{Name: "Example_x", Kind: lesson.Example, HasOutput: true, Output: "hola!",
+		File: file, Source: source})
+	require.NoError(t, err)
+	require.Equal(t, `func Example_x() {
+	fmt.Println(newGreeter().greet())
+}
+
+// greeter greets in its language.
+type greeter struct{ lang string }
+
+func (g greeter) greet() string { return hello[g.lang] + "!" }
+
+var hello = map[string]string{"es": "hola"}
+
+func newGreeter() greeter { return greeter{lang: "es"} }`, q.Code)
+	require.Equal(t, []Hint{{Line: 2, Code: "fmt.Println(newGreeter().greet())", Comment: "// hola!"}}, q.Hints)
+}
+
+func Test_newRequiresOutput(t *testing.T) {
+	_, err := New(&lesson.Lesson{Kind: lesson.Test, Source: exampleSource})
+	require.ErrorIs(t, err, ErrNoOutput)
+}
+
+func Test_grade(t *testing.T) {
+	q := &Quiz{Lesson: &lesson.Lesson{}}
+	a := q.Grade([]string{"third", "first", "second", ""}, "third\nsecond\nfirst\n")
+	require.Equal(t, []DiffLine{
+		{Equal, "third"},
+		{Missing, "second"},
+		{Equal, "first"},
+		{Extra, "second"},
+	}, a.Diff)
+	require.Equal(t, 2, a.Correct)
+	require.Equal(t, 3, a.Total)
+	require.False(t, a.Perfect())
+
+	q.Lesson.Unordered = true
+	a = q.Grade([]string{"b", "a"}, "a\nb")
+	require.True(t, a.Perfect())
+	require.Equal(t, 1.0, a.Score())
+}
+
+func Test_gradeEmptyOutput(t *testing.T) {
+	q := &Quiz{Lesson: &lesson.Lesson{}}
+	a := q.Grade(nil, "")
+	require.True(t, a.Perfect())
+	require.Equal(t, 1.0, a.Score())
+}
@@ -0,0 +1,109 @@
+// Package review schedules the quizzes of a learner with the SM-2 spaced repetition algorithm.
+//
+// The schedule is not stored: it is replayed from the quiz events of the learner's progress file, so it always agrees
+// with the answers actually given, and a quiz taken outside of a review session counts as a review too.
+//
+// Every quiz answer is turned into an SM-2 quality from 0 to 5. Only a perfect prediction is a 5; otherwise the
+// fraction of lines predicted correctly maps to 0-3, so that mostly right answers keep the item going with a shorter
+// interval and a lower ease, while poor answers start it over the next day.
+//
+// See https://super-memory.com/english/ol/sm2.htm.
+package review
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+const (
+	// InitialEase is the ease factor of a new item.
+	InitialEase = 2.5
+	// MinEase is the lowest ease factor, items below it would be shown too often.
+	MinEase = 1.3
+	// PassingQuality is the lowest quality that counts as remembered.
+	PassingQuality = 3
+
+	day = 24 * time.Hour
+)
+
+// Item is the review state of a lesson.
+type Item struct {
+	Lesson   string  // lesson ID
+	Reps     int     // successful reviews in a row
+	Lapses   int     // reviews below PassingQuality
+	Interval int     // days until the next review
+	Ease     float64 // SM-2 easiness factor
+	Last     time.Time
+	Due      time.Time
+}
+
+// Quality maps the score of a quiz, the fraction of lines predicted correctly, to an SM-2 quality.
+func Quality(score float64) int {
+	if score >= 1 {
+		return 5
+	}
+	return int(math.Max(0, score) * 4)
+}
+
+// Review updates the item with the quality of an answer given at the given time.
+func (it *Item) Review(quality int, at time.Time) {
+	if it.Ease == 0 {
+		it.Ease = InitialEase
+	}
+	if quality >= PassingQuality {
+		switch it.Reps {
+		case 0:
+			it.Interval = 1
+		case 1:
+			it.Interval = 6
+		default:
+			it.Interval = int(math.Round(float64(it.Interval) * it.Ease))
+		}
+		it.Reps++
+	} else {
+		it.Reps = 0
+		it.Lapses++
+		it.Interval = 1
+	}
+	miss := float64(5 - quality)
+	it.Ease = max(MinEase, it.Ease+0.1-miss*(0.08+miss*0.02))
+	it.Last = at
+	it.Due = at.Add(time.Duration(it.Interval) * day)
+}
+
+// Schedule replays the quiz events, oldest first, and returns the items sorted by due date.
+func Schedule(events []progress.Event) []*Item {
+	byLesson := map[string]*Item{}
+	var items []*Item
+	for _, e := range events {
+		if e.Kind != progress.Quiz {
+			continue
+		}
+		it, ok := byLesson[e.Lesson]
+		if !ok {
+			it = &Item{Lesson: e.Lesson}
+			byLesson[e.Lesson] = it
+			items = append(items, it)
+		}
+		it.Review(Quality(e.Score), e.Time)
+	}
+	slices.SortStableFunc(items, func(a, b *Item) int {
+		return cmp.Or(a.Due.Compare(b.Due), cmp.Compare(a.Lesson, b.Lesson))
+	})
+	return items
+}
+
+// Due returns the items due at now, most overdue first.
+func Due(items []*Item, now time.Time) []*Item {
+	var due []*Item
+	for _, it := range items {
+		if !it.Due.After(now) {
+			due = append(due, it)
+		}
+	}
+	return due
+}
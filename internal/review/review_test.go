@@ -0,0 +1,64 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/internal/progress"
+)
+
+func Test_quality(t *testing.T) {
+	require.Equal(t, 5, Quality(1))
+	require.Equal(t, 3, Quality(0.8))
+	require.Equal(t, 2, Quality(0.5))
+	require.Equal(t, 0, Quality(0))
+}
+
+func Test_review(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	it := &Item{Lesson: "consts.Example_iotaSkip"}
+
+	var intervals []int
+	at := start
+	for range 4 {
+		it.Review(5, at)
+		intervals = append(intervals, it.Interval)
+		at = it.Due
+	}
+	require.Equal(t, []int{1, 6, 16, 45}, intervals, "ease grows by 0.1 on every perfect answer")
+	require.InDelta(t, 2.9, it.Ease, 1e-9)
+
+	it.Review(1, at)
+	require.Equal(t, 0, it.Reps)
+	require.Equal(t, 1, it.Lapses)
+	require.Equal(t, 1, it.Interval)
+	require.InDelta(t, 2.36, it.Ease, 1e-9)
+	require.Equal(t, at.Add(24*time.Hour), it.Due)
+
+	for range 10 {
+		it.Review(0, at)
+	}
+	require.Equal(t, MinEase, it.Ease)
+}
+
+func Test_scheduleAndDue(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := []progress.Event{
+		{Time: day1, Kind: progress.Quiz, Lesson: "structs.Example_structMemoryAlignment", Score: 0.25},
+		{Time: day1, Kind: progress.Quiz, Lesson: "consts.Example_iotaSkip", Score: 1, Passed: true},
+		{Time: day1, Kind: progress.Run, Lesson: "panic.Example_panicChain", Passed: true},
+		{Time: day1.Add(24 * time.Hour), Kind: progress.Quiz, Lesson: "consts.Example_iotaSkip", Score: 1, Passed: true},
+	}
+	items := Schedule(events)
+	require.Len(t, items, 2)
+	require.Equal(t, "structs.Example_structMemoryAlignment", items[0].Lesson)
+	require.Equal(t, day1.Add(24*time.Hour), items[0].Due)
+	require.Equal(t, "consts.Example_iotaSkip", items[1].Lesson)
+	require.Equal(t, day1.Add(7*24*time.Hour), items[1].Due)
+
+	require.Empty(t, Due(items, day1))
+	require.Len(t, Due(items, day1.Add(2*24*time.Hour)), 1)
+	require.Len(t, Due(items, day1.Add(7*24*time.Hour)), 2)
+}
@@ -0,0 +1,252 @@
+package protocols
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/juan-carvajal/go-dojo/protocols/conntrace"
+)
+
+// reuseSummary prints how many requests went over new or reused connections.
+func reuseSummary(label string, rec *conntrace.Recorder) {
+	var reused int
+	for _, r := range rec.Requests() {
+		if r.Reused {
+			reused++
+		}
+	}
+	fmt.Printf("%s: %d requests, %d connections, %d reused\n", label, len(rec.Requests()), rec.Conns(), reused)
+}
+
+// Example_connReuseBody shows what it takes for net/http to put a connection back in its pool. An HTTP/1.1
+// connection carries one response at a time: the next request can only use it once the previous body was read to
+// the end. Closing a body before its end closes the connection: recent Go versions read what is left for you, but
+// only up to 256 KiB and 50ms, the 1 MiB body here is too large. A body neither read nor closed keeps its connection
+// busy forever, a leak.
+//
+// Read the body to EOF, even when the content does not matter (io.Copy(io.Discard, resp.Body)), then close it.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,pitfall requires=Example_http1_1
+func Example_connReuseBody() {
+	body := strings.Repeat("x", 1<<20)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer testServer.Close()
+
+	for _, c := range []struct {
+		label string
+		done  func(*http.Response)
+	}{
+		{"read and closed", func(resp *http.Response) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}},
+		{"closed unread", func(resp *http.Response) {
+			resp.Body.Close()
+		}},
+		{"never closed", func(resp *http.Response) {}},
+	} {
+		rec := conntrace.New(nil)
+		client := &http.Client{Transport: rec.Transport(&http.Transport{})}
+		for range 3 {
+			resp, err := client.Get(testServer.URL)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			c.done(resp)
+		}
+		reuseSummary(c.label, rec)
+		client.CloseIdleConnections()
+	}
+	testServer.CloseClientConnections() // the connections of the leaked bodies
+	// Output:
+	// read and closed: 3 requests, 1 connections, 2 reused
+	// closed unread: 3 requests, 3 connections, 0 reused
+	// never closed: 3 requests, 3 connections, 0 reused
+}
+
+// Example_connReuseClientPerRequest shows the pool belongs to the Transport, not to the request. A client created
+// for every request, with its own Transport, starts with an empty pool: each request pays for a new connection, and
+// the idle connections left behind stay open until the server times them out.
+//
+// Create clients once and share them, they are safe for concurrent use. A client without Transport uses
+// http.DefaultTransport, shared by the whole program.
+//
+//dojo:meta difficulty=beginner minutes=5 tags=http,pitfall requires=Example_connReuseBody
+func Example_connReuseClientPerRequest() {
+	testServer := httptest.NewServer(http.HandlerFunc(getRequestProtocol))
+	defer testServer.Close()
+
+	get := func(client *http.Client) {
+		resp, err := client.Get(testServer.URL)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	rec := conntrace.New(nil)
+	for range 3 {
+		client := &http.Client{Transport: rec.Transport(&http.Transport{})}
+		get(client)
+		defer client.CloseIdleConnections()
+	}
+	reuseSummary("client per request", rec)
+
+	rec = conntrace.New(nil)
+	client := &http.Client{Transport: rec.Transport(&http.Transport{})}
+	defer client.CloseIdleConnections()
+	for range 3 {
+		get(client)
+	}
+	reuseSummary("shared client", rec)
+	// Output:
+	// client per request: 3 requests, 3 connections, 0 reused
+	// shared client: 3 requests, 1 connections, 2 reused
+}
+
+// burst sends n concurrent GETs to url and waits for their responses. Against a [barrier] handler, no request can
+// wait for the connection of another one.
+func burst(client *http.Client, url string, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			resp, err := client.Get(url)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		})
+	}
+	wg.Wait()
+}
+
+// barrier returns a handler answering getRequestProtocol once n requests arrived, for every group of n requests.
+func barrier(n int) http.Handler {
+	var mu sync.Mutex
+	arrived := 0
+	release := make(chan struct{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		wait := release
+		if arrived++; arrived == n {
+			arrived = 0
+			close(release)
+			release = make(chan struct{})
+		}
+		mu.Unlock()
+		<-wait
+		getRequestProtocol(w, r)
+	})
+}
+
+// Example_connReuseMaxIdleConnsPerHost shows the pool of HTTP/1.1 connections. Ten concurrent requests need ten
+// connections, since each carries one request at a time. Once they are done, the Transport keeps at most
+// MaxIdleConnsPerHost of them idle per host and closes the others, and that limit defaults to 2
+// (http.DefaultMaxIdleConnsPerHost), even though MaxIdleConns, for all hosts, is 100 in http.DefaultTransport.
+//
+// A second burst of ten requests reuses the two idle connections and dials eight more: a service calling another
+// one with more concurrency than MaxIdleConnsPerHost keeps opening and closing connections, piling up sockets in
+// TIME_WAIT. Raise the limit to the expected concurrency, MaxConnsPerHost caps the connections, idle or not.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency,http,pitfall requires=Example_connReuseBody
+func Example_connReuseMaxIdleConnsPerHost() {
+	const concurrent = 10
+	testServer := httptest.NewServer(barrier(concurrent))
+	defer testServer.Close()
+
+	for _, idle := range []int{0, concurrent} {
+		rec := conntrace.New(nil)
+		client := &http.Client{Transport: rec.Transport(&http.Transport{MaxIdleConnsPerHost: idle})}
+		burst(client, testServer.URL, concurrent)
+		burst(client, testServer.URL, concurrent)
+		reuseSummary(fmt.Sprintf("MaxIdleConnsPerHost=%d", idle), rec)
+		client.CloseIdleConnections()
+	}
+	// Output:
+	// MaxIdleConnsPerHost=0: 20 requests, 18 connections, 2 reused
+	// MaxIdleConnsPerHost=10: 20 requests, 10 connections, 10 reused
+}
+
+// Example_connReuseHTTP2 sends the same bursts of ten concurrent requests over HTTP/2: all of them share one TCP
+// connection, each on its own stream, and MaxIdleConnsPerHost does not matter. A first request establishes the
+// connection, otherwise concurrent requests to a new host may each dial one before any of them learns that the
+// server speaks HTTP/2.
+//
+// One connection is also a single point of failure and of congestion: one lost TCP packet stalls all the streams,
+// and a server limits the concurrent streams per connection (SETTINGS_MAX_CONCURRENT_STREAMS, 250 for net/http).
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency,http requires=Example_connReuseMaxIdleConnsPerHost,Example_http2_TLS
+func Example_connReuseHTTP2() {
+	const concurrent = 10
+	mux := http.NewServeMux()
+	mux.Handle("/", barrier(concurrent))
+	mux.HandleFunc("/warm-up", getRequestProtocol)
+	testServer := httptest.NewUnstartedServer(mux)
+	testServer.EnableHTTP2 = true
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	rec := conntrace.New(nil)
+	client := &http.Client{Transport: rec.Transport(testServer.Client().Transport)}
+	burst(client, testServer.URL+"/warm-up", 1)
+	burst(client, testServer.URL, concurrent)
+	burst(client, testServer.URL, concurrent)
+	reuseSummary("HTTP/2", rec)
+
+	protos := map[string]int{}
+	for _, r := range rec.Requests() {
+		protos[r.Proto]++
+	}
+	fmt.Println(protos)
+	// Output:
+	// HTTP/2: 21 requests, 1 connections, 20 reused
+	// map[HTTP/2.0:21]
+}
+
+// Example_connReuseTrace prints what httptrace reports for each request: a new connection pays for the TCP connect
+// and the TLS handshake, a reused one comes from the idle pool after some idle time. Timings vary from run to run,
+// so they are left out of the output: run `dojo run Example_connReuseTrace` after replacing nil by os.Stdout in
+// conntrace.New to see them.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=http requires=Example_connReuseBody
+func Example_connReuseTrace() {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(getRequestProtocol))
+	defer testServer.Close()
+
+	rec := conntrace.New(nil)
+	client := &http.Client{Transport: rec.Transport(testServer.Client().Transport)}
+	defer client.CloseIdleConnections()
+	for range 2 {
+		resp, err := client.Get(testServer.URL)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	for _, r := range rec.Requests() {
+		var phases []string
+		if r.Connect > 0 {
+			phases = append(phases, "connect")
+		}
+		if r.TLS > 0 {
+			phases = append(phases, "tls")
+		}
+		fmt.Printf("#%d conn=%d reused=%t idle=%t phases=[%s]\n", r.ID, r.Conn, r.Reused, r.WasIdle, strings.Join(phases, " "))
+	}
+	// Output:
+	// #1 conn=1 reused=false idle=false phases=[connect tls]
+	// #2 conn=1 reused=true idle=true phases=[]
+}
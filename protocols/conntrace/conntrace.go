@@ -0,0 +1,204 @@
+// Package conntrace records how an HTTP client gets its connections, for lessons to show whether connections are
+// reused: the DNS lookup, TCP connect and TLS handshake of new connections, and the idle time of reused ones.
+//
+// It is built on [net/http/httptrace]: [Recorder.Transport] attaches an [httptrace.ClientTrace] to every request
+// and records a [Request] when the round trip returns. Connections are numbered by the [net.Conn] the transport got,
+// so two requests with the same Conn went over the same TCP connection, whatever the protocol, even when in-memory
+// connections share their addresses.
+package conntrace
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request is the connection story of one request.
+type Request struct {
+	ID     int // request number, from 1 in start order
+	Method string
+	URL    string
+	Proto  string // protocol of the response, empty when the round trip failed
+	Err    error
+
+	Conn     int  // connection number, from 1 in first use order
+	Reused   bool // the connection served a previous request
+	WasIdle  bool // the connection came from the idle pool
+	IdleTime time.Duration
+
+	DNS     time.Duration // zero when there was no lookup, e.g. for IP addresses or reused connections
+	Connect time.Duration // zero for reused connections
+	TLS     time.Duration // zero for reused and cleartext connections
+	Total   time.Duration // until the response headers
+}
+
+// String formats a request like `#2 GET http://127.0.0.1:1234/ HTTP/1.1 conn=1 reused idle=1.2ms total=250µs`.
+func (r Request) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d %s %s", r.ID, r.Method, r.URL)
+	if r.Proto != "" {
+		b.WriteString(" " + r.Proto)
+	}
+	if r.Conn > 0 {
+		fmt.Fprintf(&b, " conn=%d", r.Conn)
+	}
+	switch {
+	case r.Reused && r.WasIdle:
+		fmt.Fprintf(&b, " reused idle=%s", r.IdleTime)
+	case r.Reused:
+		b.WriteString(" reused")
+	case r.Conn > 0:
+		b.WriteString(" new")
+	}
+	if r.DNS > 0 {
+		fmt.Fprintf(&b, " dns=%s", r.DNS)
+	}
+	if r.Connect > 0 {
+		fmt.Fprintf(&b, " connect=%s", r.Connect)
+	}
+	if r.TLS > 0 {
+		fmt.Fprintf(&b, " tls=%s", r.TLS)
+	}
+	fmt.Fprintf(&b, " total=%s", r.Total)
+	if r.Err != nil {
+		fmt.Fprintf(&b, " err=%q", r.Err)
+	}
+	return b.String()
+}
+
+// Recorder records the requests of the clients using its transports.
+type Recorder struct {
+	w     io.Writer
+	mu    sync.Mutex
+	next  int
+	reqs  []Request
+	conns map[net.Conn]int // connection number
+}
+
+// New returns a recorder printing each request to w when its round trip returns. w can be nil.
+func New(w io.Writer) *Recorder {
+	return &Recorder{w: w, conns: make(map[net.Conn]int)}
+}
+
+// Requests returns the requests recorded so far, in completion order.
+func (r *Recorder) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Request(nil), r.reqs...)
+}
+
+// Conns returns the number of connections used so far.
+func (r *Recorder) Conns() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.conns)
+}
+
+// Transport returns a round tripper recording the requests sent through rt, [http.DefaultTransport] when nil.
+func (r *Recorder) Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{rec: r, rt: rt}
+}
+
+type transport struct {
+	rec *Recorder
+	rt  http.RoundTripper
+}
+
+// CloseIdleConnections lets [http.Client.CloseIdleConnections] reach the wrapped transport.
+func (t *transport) CloseIdleConnections() {
+	if c, ok := t.rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.rec.mu.Lock()
+	t.rec.next++
+	rq := Request{ID: t.rec.next, Method: req.Method, URL: req.URL.String()}
+	t.rec.mu.Unlock()
+
+	// The trace hooks of a request can run on other goroutines, e.g. a dial racing with a connection returned to
+	// the pool, so they only write under mu.
+	var mu sync.Mutex
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			dnsStart = time.Now()
+			mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			rq.DNS = time.Since(dnsStart)
+			mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			mu.Lock()
+			connectStart = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			mu.Lock()
+			rq.Connect = time.Since(connectStart)
+			mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			tlsStart = time.Now()
+			mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			rq.TLS = time.Since(tlsStart)
+			mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn := t.rec.conn(info.Conn)
+			mu.Lock()
+			rq.Conn, rq.Reused, rq.WasIdle, rq.IdleTime = conn, info.Reused, info.WasIdle, info.IdleTime
+			mu.Unlock()
+		},
+	}
+	resp, err := t.rt.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+	mu.Lock()
+	rq.Total = time.Since(start)
+	rq.Err = err
+	if resp != nil {
+		rq.Proto = resp.Proto
+	}
+	done := rq
+	mu.Unlock()
+	t.rec.record(done)
+	return resp, err
+}
+
+func (r *Recorder) conn(c net.Conn) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.conns[c]
+	if !ok {
+		n = len(r.conns) + 1
+		r.conns[c] = n
+	}
+	return n
+}
+
+func (r *Recorder) record(rq Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reqs = append(r.reqs, rq)
+	if r.w != nil {
+		fmt.Fprintln(r.w, rq)
+	}
+}
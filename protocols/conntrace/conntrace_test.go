@@ -0,0 +1,86 @@
+package conntrace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer s.Close()
+
+	var log bytes.Buffer
+	rec := New(&log)
+	client := &http.Client{Transport: rec.Transport(&http.Transport{})}
+	defer client.CloseIdleConnections()
+	for range 2 {
+		resp, err := client.Get(s.URL)
+		require.NoError(t, err)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://127.0.0.1:0")
+	require.Error(t, err)
+
+	reqs := rec.Requests()
+	require.Len(t, reqs, 3)
+	require.Equal(t, 1, reqs[0].Conn)
+	require.False(t, reqs[0].Reused)
+	require.Positive(t, reqs[0].Connect)
+	require.Equal(t, 1, reqs[1].Conn)
+	require.True(t, reqs[1].Reused)
+	require.True(t, reqs[1].WasIdle)
+	require.Zero(t, reqs[1].Connect)
+	require.Equal(t, "HTTP/1.1", reqs[1].Proto)
+	require.Error(t, reqs[2].Err)
+	require.Equal(t, 1, rec.Conns())
+
+	require.Regexp(t, `^#1 GET http://127\.0\.0\.1:\d+ HTTP/1\.1 conn=1 new connect=\S+ total=\S+
+#2 GET http://127\.0\.0\.1:\d+ HTTP/1\.1 conn=1 reused idle=\S+ total=\S+
+#3 GET http://127\.0\.0\.1:0 connect=\S+ total=\S+ err=".*"
+$`, log.String())
+}
+
+// sameAddrConn is a connection with the addresses of every other one, like the in-memory ones.
+type sameAddrConn struct{ net.Conn }
+
+func (sameAddrConn) LocalAddr() net.Addr  { return &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1} }
+func (sameAddrConn) RemoteAddr() net.Addr { return &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 2} }
+
+func TestRecorderConnsWithSameAddresses(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	rec := New(nil)
+	var d net.Dialer
+	client := &http.Client{Transport: rec.Transport(&http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			c, err := d.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return sameAddrConn{c}, nil
+		},
+	})}
+	for range 2 {
+		resp, err := client.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	reqs := rec.Requests()
+	require.Len(t, reqs, 2)
+	require.Equal(t, 1, reqs[0].Conn)
+	require.Equal(t, 2, reqs[1].Conn)
+	require.Equal(t, 2, rec.Conns())
+}
@@ -0,0 +1,39 @@
+// Package graceful runs an http.Server until it is told to stop, then drains it: the listener closes, so new
+// connections are refused, idle connections close, and the requests in flight get a grace period to complete.
+//
+// http.Server.Shutdown does the draining, within limits the lessons on shutdown show: it doesn't interrupt
+// handlers, nor know of the connections they hijacked, e.g. WebSockets. Servers tell those to stop with
+// http.Server.RegisterOnShutdown, or by canceling the context they give handlers with http.Server.BaseContext.
+package graceful
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serve serves srv on ln until ctx is done, typically on a signal with signal.NotifyContext, then shuts srv down,
+// waiting up to grace for the requests in flight. Connections still open after grace are closed.
+//
+// It returns nil once drained, context.DeadlineExceeded when grace ran out, or the error that stopped srv.Serve
+// before ctx was done.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, grace time.Duration) error {
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// ctx is done already: the grace period needs a context of its own.
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), grace)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		srv.Close()
+	}
+	<-served // http.ErrServerClosed, as soon as Shutdown closed the listener
+	return err
+}
@@ -0,0 +1,90 @@
+package graceful
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/protocols/memnet"
+)
+
+// serve runs Serve in the background with a handler taking d, or until its request is canceled, and returns a
+// client for it, the function stopping it and the result of Serve.
+func serve(ln *memnet.Listener, d, grace time.Duration) (*http.Client, context.CancelFunc, <-chan error) {
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(d):
+			io.WriteString(w, "done")
+		case <-r.Context().Done():
+		}
+	})}
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- Serve(ctx, srv, ln, grace) }()
+	return &http.Client{Transport: &http.Transport{DialContext: ln.DialContext}}, stop, served
+}
+
+func get(client *http.Client) (string, error) {
+	resp, err := client.Get("http://memnet/")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	return string(b), err
+}
+
+func TestServe(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ln := memnet.Listen()
+		client, stop, served := serve(ln, 5*time.Second, 10*time.Second)
+		start := time.Now()
+		inFlight := make(chan string)
+		go func() {
+			body, err := get(client)
+			require.NoError(t, err)
+			inFlight <- body
+		}()
+		synctest.Wait()
+
+		stop()
+		synctest.Wait()
+		_, err := ln.DialContext(context.Background(), "tcp", "memnet")
+		require.ErrorIs(t, err, net.ErrClosed)
+
+		require.Equal(t, "done", <-inFlight)
+		require.Equal(t, 5*time.Second, time.Since(start))
+		require.NoError(t, <-served)
+	})
+}
+
+func TestServeGraceExpired(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		client, stop, served := serve(memnet.Listen(), time.Minute, 10*time.Second)
+		start := time.Now()
+		inFlight := make(chan error)
+		go func() {
+			_, err := get(client)
+			inFlight <- err
+		}()
+		synctest.Wait()
+
+		stop()
+		require.ErrorIs(t, <-served, context.DeadlineExceeded)
+		require.Error(t, <-inFlight)
+		require.Equal(t, 10*time.Second, time.Since(start))
+	})
+}
+
+func TestServeError(t *testing.T) {
+	ln := memnet.Listen()
+	ln.Close()
+	err := Serve(context.Background(), &http.Server{}, ln, time.Second)
+	require.ErrorIs(t, err, net.ErrClosed)
+}
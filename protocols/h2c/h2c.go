@@ -0,0 +1,44 @@
+// Package h2c helps serving and calling HTTP/2 without TLS ("h2c"), e.g. behind a proxy that terminates TLS.
+//
+// There are two ways to start an h2c connection:
+//
+//   - Prior knowledge: the client knows the server speaks HTTP/2 and sends the HTTP/2 connection preface right away.
+//     Since Go 1.24, net/http supports it on both sides through [http.Protocols].
+//   - Upgrade: the client sends an HTTP/1.1 request with `Upgrade: h2c`, the server answers
+//     `101 Switching Protocols` and the response to that first request comes back as HTTP/2 on stream 1. RFC 9113
+//     deprecated this mechanism and net/http does not implement it: [UpgradeHandler] serves it with
+//     golang.org/x/net/http2/h2c and [Upgrade] is a minimal client showing each step of the exchange.
+package h2c
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	xh2c "golang.org/x/net/http2/h2c"
+)
+
+// Protocols returns the protocols of an unencrypted HTTP/2 server or client, along with HTTP/1 when http1 is set.
+//
+// A server with both accepts HTTP/1 requests and HTTP/2 prior knowledge connections on the same port. A client with
+// both uses HTTP/1: it only sends the HTTP/2 preface when HTTP/1 is disabled.
+func Protocols(http1 bool) *http.Protocols {
+	var p http.Protocols
+	p.SetHTTP1(http1)
+	p.SetUnencryptedHTTP2(true)
+	return &p
+}
+
+// Server returns a server accepting HTTP/1 and prior knowledge h2c.
+func Server(h http.Handler) *http.Server {
+	return &http.Server{Handler: h, Protocols: Protocols(true)}
+}
+
+// Client returns a client speaking h2c with prior knowledge, it can't call HTTP/1 only servers.
+func Client() *http.Client {
+	return &http.Client{Transport: &http.Transport{Protocols: Protocols(false)}}
+}
+
+// UpgradeHandler serves h over HTTP/1, HTTP/2 with prior knowledge and HTTP/1 connections upgraded to h2c.
+func UpgradeHandler(h http.Handler) http.Handler {
+	return xh2c.NewHandler(h, &http2.Server{})
+}
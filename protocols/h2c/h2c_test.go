@@ -0,0 +1,69 @@
+package h2c
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var proto = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, r.Proto) })
+
+func get(t *testing.T, client *http.Client, url string) (string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b), nil
+}
+
+func TestServerAndClient(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config = Server(proto)
+	srv.Start()
+	defer srv.Close()
+
+	got, err := get(t, Client(), srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/2.0", got)
+
+	got, err = get(t, http.DefaultClient, srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/1.1", got, "the server still speaks HTTP/1")
+}
+
+func TestClientToHTTP1Server(t *testing.T) {
+	srv := httptest.NewServer(proto)
+	defer srv.Close()
+
+	_, err := get(t, Client(), srv.URL)
+	require.Error(t, err, "the HTTP/1 server doesn't understand the HTTP/2 preface")
+}
+
+func TestUpgrade(t *testing.T) {
+	srv := httptest.NewServer(UpgradeHandler(proto))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, resp, err := Upgrade(ctx, srv.Listener.Addr().String(), "/")
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, "HTTP/1.1 101 Switching Protocols", conn.Switching)
+	require.Equal(t, uint32(1), resp.Stream)
+	require.Equal(t, "HTTP/2.0", string(resp.Body))
+
+	resp, err = conn.Get("/")
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), resp.Stream)
+	require.Equal(t, http.StatusOK, resp.Status)
+	require.Equal(t, "HTTP/2.0", string(resp.Body))
+}
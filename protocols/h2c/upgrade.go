@@ -0,0 +1,169 @@
+package h2c
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// upgradeStream is the stream on which the response to the upgraded request comes back.
+const upgradeStream = 1
+
+// Response is an HTTP/2 response read by a Conn.
+type Response struct {
+	Stream uint32
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Conn is an HTTP/1.1 connection upgraded to h2c. It sends one request at a time.
+type Conn struct {
+	Switching string // status line of the HTTP/1.1 response, e.g. `HTTP/1.1 101 Switching Protocols`
+
+	conn   net.Conn
+	host   string
+	fr     *http2.Framer
+	enc    *hpack.Encoder
+	encBuf bytes.Buffer
+	next   uint32 // next client stream, odd
+}
+
+// Upgrade sends a GET request for path to the server at addr over HTTP/1.1, asking to upgrade the connection to h2c,
+// and reads the response to it over HTTP/2. The connection stays open for more requests until closed.
+func Upgrade(ctx context.Context, addr, path string) (*Conn, *Response, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c := &Conn{conn: conn, host: addr, next: upgradeStream + 2}
+	c.enc = hpack.NewEncoder(&c.encBuf)
+	resp, err := c.upgrade(path)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return c, resp, nil
+}
+
+func (c *Conn) upgrade(path string) (*Response, error) {
+	// The settings of the client travel base64url encoded in a header: here, server push is disabled.
+	settings := []byte{0, byte(http2.SettingEnablePush), 0, 0, 0, 0}
+	fmt.Fprintf(c.conn, "GET %s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Connection: Upgrade, HTTP2-Settings\r\n"+
+		"Upgrade: h2c\r\n"+
+		"HTTP2-Settings: %s\r\n\r\n", path, c.host, base64.RawURLEncoding.EncodeToString(settings))
+
+	br := bufio.NewReader(c.conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.Switching = resp.Proto + " " + resp.Status
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("server did not upgrade: %s", resp.Status)
+	}
+
+	// From now on the connection speaks HTTP/2: the client sends the preface and its SETTINGS, as with prior
+	// knowledge, and the server answers on stream 1 as if the upgraded request had been sent on it.
+	if _, err := c.conn.Write([]byte(http2.ClientPreface)); err != nil {
+		return nil, err
+	}
+	c.fr = http2.NewFramer(c.conn, br)
+	c.fr.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+	if err := c.fr.WriteSettings(); err != nil {
+		return nil, err
+	}
+	return c.read(upgradeStream)
+}
+
+// Get sends a GET request for path on a new stream and reads its response.
+func (c *Conn) Get(path string) (*Response, error) {
+	stream := c.next
+	c.next += 2
+	c.encBuf.Reset()
+	for _, f := range []hpack.HeaderField{
+		{Name: ":method", Value: http.MethodGet},
+		{Name: ":scheme", Value: "http"},
+		{Name: ":authority", Value: c.host},
+		{Name: ":path", Value: path},
+	} {
+		if err := c.enc.WriteField(f); err != nil {
+			return nil, err
+		}
+	}
+	err := c.fr.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      stream,
+		BlockFragment: c.encBuf.Bytes(),
+		EndStream:     true,
+		EndHeaders:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.read(stream)
+}
+
+// read reads frames until the end of the response on stream, answering the SETTINGS of the server on the way.
+func (c *Conn) read(stream uint32) (*Response, error) {
+	resp := &Response{Stream: stream}
+	for {
+		f, err := c.fr.ReadFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch f := f.(type) {
+		case *http2.SettingsFrame:
+			if !f.IsAck() {
+				if err := c.fr.WriteSettingsAck(); err != nil {
+					return nil, err
+				}
+			}
+		case *http2.MetaHeadersFrame:
+			if f.StreamID != stream {
+				continue
+			}
+			resp.Header = http.Header{}
+			for _, hf := range f.RegularFields() {
+				resp.Header.Add(hf.Name, hf.Value)
+			}
+			if resp.Status, err = strconv.Atoi(f.PseudoValue("status")); err != nil {
+				return nil, err
+			}
+			if f.StreamEnded() {
+				return resp, nil
+			}
+		case *http2.DataFrame:
+			if f.StreamID != stream {
+				continue
+			}
+			resp.Body = append(resp.Body, f.Data()...)
+			if f.StreamEnded() {
+				return resp, nil
+			}
+		case *http2.RSTStreamFrame:
+			return nil, fmt.Errorf("stream %d reset: %v", f.StreamID, f.ErrCode)
+		case *http2.GoAwayFrame:
+			return nil, errors.New("connection closed by the server: " + f.ErrCode.String())
+		}
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
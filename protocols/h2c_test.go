@@ -0,0 +1,123 @@
+package protocols
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h2c"
+)
+
+// Example_h2cPriorKnowledge shows how to speak HTTP/2 without TLS since Go 1.24: both the server and the client enable
+// unencrypted HTTP/2 in their [http.Protocols]. The client sends the HTTP/2 connection preface right away, which is
+// called "prior knowledge": it has to know that the server speaks HTTP/2, there is no negotiation like TLS ALPN.
+//
+// This is what services behind a TLS terminating proxy or a gRPC sidecar use. [h2c.Server] and [h2c.Client] package
+// this setup for the later lessons.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=http requires=Example_http2_TLS
+func Example_h2cPriorKnowledge() {
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(getRequestProtocol))
+	testServer.Config.Protocols = new(http.Protocols)
+	testServer.Config.Protocols.SetHTTP1(true)
+	testServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	testServer.Start()
+	defer testServer.Close()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true) // and nothing else
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get(testServer.URL)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println("Error reading body:", err)
+		return
+	}
+	fmt.Print(string(b))
+	// Output: Request Protocol: HTTP/2.0
+}
+
+// Example_h2cClientPrefersHTTP1 shows a pitfall of [http.Protocols] on the client side: when both HTTP/1 and
+// unencrypted HTTP/2 are enabled, http:// URLs are requested with HTTP/1, the client never tries h2c. Only a client
+// that disables HTTP/1 uses prior knowledge.
+//
+// On the server side, enabling both is fine: the server tells them apart by the first bytes of the connection.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=http,pitfall requires=Example_h2cPriorKnowledge
+func Example_h2cClientPrefersHTTP1() {
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(getRequestProtocol))
+	testServer.Config = h2c.Server(testServer.Config.Handler)
+	testServer.Start()
+	defer testServer.Close()
+
+	for _, http1 := range []bool{true, false} {
+		client := &http.Client{Transport: &http.Transport{Protocols: h2c.Protocols(http1)}}
+		resp, err := client.Get(testServer.URL)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			fmt.Println("Error reading body:", err)
+			return
+		}
+		fmt.Printf("HTTP1=%t UnencryptedHTTP2=true: %s", http1, b)
+	}
+	// Output:
+	// HTTP1=true UnencryptedHTTP2=true: Request Protocol: HTTP/1.1
+	// HTTP1=false UnencryptedHTTP2=true: Request Protocol: HTTP/2.0
+}
+
+// Example_h2cUpgrade shows the HTTP/1.1 `Upgrade: h2c` dance: the client sends a regular HTTP/1.1 request with
+//
+//	Connection: Upgrade, HTTP2-Settings
+//	Upgrade: h2c
+//	HTTP2-Settings: <base64url encoded SETTINGS frame payload>
+//
+// and the server answers `101 Switching Protocols`. Then both sides speak HTTP/2 on the same connection: the client
+// sends the connection preface and the response to the upgraded request comes back on stream 1.
+//
+// The server replays the upgraded request to the handler as an HTTP/2 request on stream 1, although it was sent with
+// HTTP/1.1. The next requests of the connection are plain HTTP/2 ones, on streams 3, 5 and so on.
+//
+// RFC 9113 deprecated this mechanism, net/http doesn't implement it and its servers ignore the Upgrade header. The
+// server here uses golang.org/x/net/http2/h2c, and [h2c.Upgrade] is a client doing each step by hand.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http,pitfall requires=Example_h2cPriorKnowledge
+func Example_h2cUpgrade() {
+	testServer := httptest.NewServer(h2c.UpgradeHandler(http.HandlerFunc(getRequestProtocol)))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, resp, err := h2c.Upgrade(ctx, testServer.Listener.Addr().String(), "/")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer conn.Close()
+	fmt.Println(conn.Switching)
+	fmt.Printf("stream %d, status %d: %s", resp.Stream, resp.Status, resp.Body)
+
+	resp, err = conn.Get("/")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("stream %d, status %d: %s", resp.Stream, resp.Status, resp.Body)
+	// Output:
+	// HTTP/1.1 101 Switching Protocols
+	// stream 1, status 200: Request Protocol: HTTP/2.0
+	// stream 3, status 200: Request Protocol: HTTP/2.0
+}
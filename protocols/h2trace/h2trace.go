@@ -0,0 +1,317 @@
+// Package h2trace records the HTTP/2 frames exchanged by a server, decoded, for lessons to show what r.Proto hides:
+// the connection preface, SETTINGS, streams multiplexed on a connection, flow control and so on.
+//
+// The tracer sits between the TLS layer and the HTTP/2 server: [Attach] hands the decrypted connections of an
+// httptest server to an http2.Server through a wrapper that decodes the bytes read (sent by the client) and written
+// (sent by the server) with an http2.Framer and an HPACK decoder per direction. Cleartext servers can be traced with
+// [Trace.Listener] instead.
+package h2trace
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// Direction tells who sent a frame.
+type Direction int
+
+const (
+	ClientToServer Direction = iota
+	ServerToClient
+)
+
+func (d Direction) String() string {
+	if d == ClientToServer {
+		return "C→S"
+	}
+	return "S→C"
+}
+
+// Frame is a decoded frame.
+type Frame struct {
+	Conn    int // connection number, from 1 in accept order
+	Dir     Direction
+	Preface bool // the client connection preface, which is not a frame, Type is meaningless
+	Type    http2.FrameType
+	Flags   http2.Flags
+	Stream  uint32
+	Length  uint32              // payload length
+	Headers []hpack.HeaderField // decoded header block of HEADERS frames, CONTINUATIONs included
+	Details string              // decoded payload of the other frame types
+	Raw     []byte              // the frame, header included, as sent on the wire
+}
+
+// String formats a frame like `C→S HEADERS stream=1 END_STREAM|END_HEADERS :method=GET ...`.
+func (f Frame) String() string {
+	if f.Preface {
+		return fmt.Sprintf("%s PREFACE %q", f.Dir, f.Raw)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s stream=%d len=%d", f.Dir, f.Type, f.Stream, f.Length)
+	if flags := flagNames(f.Type, f.Flags); flags != "" {
+		b.WriteString(" " + flags)
+	}
+	for _, h := range f.Headers {
+		fmt.Fprintf(&b, " %s=%s", h.Name, h.Value)
+	}
+	if f.Details != "" {
+		b.WriteString(" " + f.Details)
+	}
+	return b.String()
+}
+
+func flagNames(t http2.FrameType, flags http2.Flags) string {
+	var names []string
+	for bit := http2.Flags(1); bit != 0 && bit <= flags; bit <<= 1 {
+		if !flags.Has(bit) {
+			continue
+		}
+		switch {
+		case bit == http2.FlagDataEndStream && (t == http2.FrameData || t == http2.FrameHeaders):
+			names = append(names, "END_STREAM")
+		case bit == http2.FlagSettingsAck && (t == http2.FrameSettings || t == http2.FramePing):
+			names = append(names, "ACK")
+		case bit == http2.FlagHeadersEndHeaders && (t == http2.FrameHeaders || t == http2.FrameContinuation):
+			names = append(names, "END_HEADERS")
+		case bit == http2.FlagHeadersPadded && (t == http2.FrameData || t == http2.FrameHeaders):
+			names = append(names, "PADDED")
+		case bit == http2.FlagHeadersPriority && t == http2.FrameHeaders:
+			names = append(names, "PRIORITY")
+		default:
+			names = append(names, fmt.Sprintf("0x%x", uint8(bit)))
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Trace collects the frames of every traced connection, and logs them as they come when it has a writer.
+type Trace struct {
+	mu     sync.Mutex
+	w      io.Writer
+	frames []Frame
+	conns  int
+}
+
+// New returns a trace logging the frames to w, which may be nil.
+func New(w io.Writer) *Trace {
+	return &Trace{w: w}
+}
+
+// Frames returns the frames recorded so far. Frames of a connection and direction are in order; the order between
+// directions is the one the server read and wrote them in.
+func (t *Trace) Frames() []Frame {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Frame(nil), t.frames...)
+}
+
+// Filter returns the frames recorded so far that keep returns true for.
+func (t *Trace) Filter(keep func(Frame) bool) []Frame {
+	var frames []Frame
+	for _, f := range t.Frames() {
+		if keep(f) {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// Conns is the number of traced connections.
+func (t *Trace) Conns() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conns
+}
+
+func (t *Trace) record(f Frame) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frames = append(t.frames, f)
+	if t.w != nil {
+		fmt.Fprintf(t.w, "#%d %s\n", f.Conn, f)
+	}
+}
+
+// Wrap traces the server side of an HTTP/2 connection: the bytes it reads come from the client.
+func (t *Trace) Wrap(c net.Conn) net.Conn {
+	t.mu.Lock()
+	t.conns++
+	n := t.conns
+	t.mu.Unlock()
+	return &conn{
+		Conn: c,
+		in:   newDecoder(t, n, ClientToServer),
+		out:  newDecoder(t, n, ServerToClient),
+	}
+}
+
+// Listener traces every connection accepted by l, for cleartext HTTP/2 servers.
+func (t *Trace) Listener(l net.Listener) net.Listener {
+	return &listener{Listener: l, t: t}
+}
+
+type listener struct {
+	net.Listener
+	t *Trace
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.t.Wrap(c), nil
+}
+
+// Attach makes the unstarted server s serve HTTP/2 over TLS with h2, the defaults when nil, through the trace. Start
+// it with StartTLS. The connections are served by h2.ServeConn, out of reach of s.Shutdown, which sends them no
+// GOAWAY: trace cleartext HTTP/2 with Listener to see a shutdown.
+func Attach(s *httptest.Server, t *Trace, h2 *http2.Server) {
+	s.EnableHTTP2 = true
+	if s.Config.TLSNextProto == nil {
+		s.Config.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	if h2 == nil {
+		h2 = &http2.Server{}
+	}
+	s.Config.TLSNextProto[http2.NextProtoTLS] = func(srv *http.Server, c *tls.Conn, h http.Handler) {
+		h2.ServeConn(t.Wrap(c), &http2.ServeConnOpts{BaseConfig: srv, Handler: h})
+	}
+}
+
+type conn struct {
+	net.Conn
+	in, out *decoder
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.in.feed(p[:n])
+	return n, err
+}
+
+// Write records the frames before sending them, so that they are in the trace by the time the client gets them.
+func (c *conn) Write(p []byte) (int, error) {
+	c.out.feed(p)
+	return c.Conn.Write(p)
+}
+
+// decoder decodes the frames of one direction of a connection as their bytes go through.
+type decoder struct {
+	t       *Trace
+	conn    int
+	dir     Direction
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	preface int // bytes of the client preface still expected
+	fr      *http2.Framer
+	hpack   *hpack.Decoder
+	fields  []hpack.HeaderField
+	headers []Frame // HEADERS frame and the CONTINUATIONs so far, waiting for END_HEADERS to be recorded together
+	broken  bool    // stop decoding after an error
+}
+
+func newDecoder(t *Trace, conn int, dir Direction) *decoder {
+	d := &decoder{t: t, conn: conn, dir: dir}
+	if dir == ClientToServer {
+		d.preface = len(http2.ClientPreface)
+	}
+	d.fr = http2.NewFramer(nil, &d.buf)
+	d.hpack = hpack.NewDecoder(4096, func(f hpack.HeaderField) { d.fields = append(d.fields, f) })
+	return d
+}
+
+const frameHeaderLen = 9
+
+func (d *decoder) feed(p []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.broken {
+		return
+	}
+	d.buf.Write(p)
+	if d.preface > 0 {
+		if d.buf.Len() < d.preface {
+			return
+		}
+		d.t.record(Frame{Conn: d.conn, Dir: d.dir, Preface: true, Length: uint32(d.preface), Raw: bytes.Clone(d.buf.Next(d.preface))})
+		d.preface = 0
+	}
+	for d.buf.Len() >= frameHeaderLen {
+		b := d.buf.Bytes()
+		length := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		if len(b) < frameHeaderLen+length {
+			return
+		}
+		raw := bytes.Clone(b[:frameHeaderLen+length])
+		f, err := d.fr.ReadFrame()
+		if err != nil {
+			d.broken = true
+			d.t.record(Frame{Conn: d.conn, Dir: d.dir, Details: "undecodable: " + err.Error(), Raw: raw})
+			return
+		}
+		d.decode(f, raw)
+	}
+}
+
+func (d *decoder) decode(f http2.Frame, raw []byte) {
+	h := f.Header()
+	fr := Frame{Conn: d.conn, Dir: d.dir, Type: h.Type, Flags: h.Flags, Stream: h.StreamID, Length: h.Length, Raw: raw}
+	switch f := f.(type) {
+	case *http2.HeadersFrame:
+		d.fields = nil
+		if _, err := d.hpack.Write(f.HeaderBlockFragment()); err != nil {
+			fr.Details = "hpack: " + err.Error()
+		}
+		if !f.HeadersEnded() {
+			d.headers = []Frame{fr}
+			return
+		}
+		d.hpack.Close()
+		fr.Headers = d.fields
+	case *http2.ContinuationFrame:
+		if _, err := d.hpack.Write(f.HeaderBlockFragment()); err != nil {
+			fr.Details = "hpack: " + err.Error()
+		}
+		if d.headers == nil {
+			break
+		}
+		d.headers = append(d.headers, fr)
+		if !f.HeadersEnded() {
+			return
+		}
+		d.hpack.Close()
+		d.headers[0].Headers = d.fields
+		for _, fr := range d.headers {
+			d.t.record(fr)
+		}
+		d.headers = nil
+		return
+	case *http2.SettingsFrame:
+		var settings []string
+		f.ForeachSetting(func(s http2.Setting) error {
+			settings = append(settings, fmt.Sprintf("%s=%d", s.ID, s.Val))
+			return nil
+		})
+		fr.Details = strings.Join(settings, " ")
+	case *http2.WindowUpdateFrame:
+		fr.Details = fmt.Sprintf("increment=%d", f.Increment)
+	case *http2.PingFrame:
+		fr.Details = fmt.Sprintf("data=%x", f.Data)
+	case *http2.GoAwayFrame:
+		fr.Details = fmt.Sprintf("last_stream=%d code=%s", f.LastStreamID, f.ErrCode)
+	case *http2.RSTStreamFrame:
+		fr.Details = fmt.Sprintf("code=%s", f.ErrCode)
+	}
+	d.t.record(fr)
+}
@@ -0,0 +1,74 @@
+package h2trace
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestDecodeSplitWritesAndContinuation(t *testing.T) {
+	var wire bytes.Buffer
+	wire.WriteString(http2.ClientPreface)
+	fr := http2.NewFramer(&wire, nil)
+	require.NoError(t, fr.WriteSettings(http2.Setting{ID: http2.SettingEnablePush, Val: 0}))
+
+	var block bytes.Buffer
+	enc := hpack.NewEncoder(&block)
+	require.NoError(t, enc.WriteField(hpack.HeaderField{Name: ":method", Value: "GET"}))
+	require.NoError(t, enc.WriteField(hpack.HeaderField{Name: ":path", Value: "/"}))
+	split := block.Len() / 2
+	require.NoError(t, fr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: block.Bytes()[:split], EndStream: true}))
+	require.NoError(t, fr.WriteContinuation(1, true, block.Bytes()[split:]))
+	require.NoError(t, fr.WriteData(1, true, []byte("hi")))
+
+	var log bytes.Buffer
+	trace := New(&log)
+	d := newDecoder(trace, 1, ClientToServer)
+	for _, b := range wire.Bytes() { // one byte at a time, like the slowest network
+		d.feed([]byte{b})
+	}
+
+	frames := trace.Frames()
+	require.Len(t, frames, 5)
+	require.True(t, frames[0].Preface)
+	require.Equal(t, []hpack.HeaderField{{Name: ":method", Value: "GET"}, {Name: ":path", Value: "/"}}, frames[2].Headers)
+	require.Equal(t, `#1 C→S PREFACE "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+#1 C→S SETTINGS stream=0 len=6 ENABLE_PUSH=0
+#1 C→S HEADERS stream=1 len=`+strconv.Itoa(split)+` END_STREAM :method=GET :path=/
+#1 C→S CONTINUATION stream=1 len=`+strconv.Itoa(block.Len()-split)+` END_HEADERS
+#1 C→S DATA stream=1 len=2 END_STREAM
+`, log.String())
+}
+
+func TestDecodeContinuations(t *testing.T) {
+	var wire bytes.Buffer
+	fr := http2.NewFramer(&wire, nil)
+	var block bytes.Buffer
+	enc := hpack.NewEncoder(&block)
+	require.NoError(t, enc.WriteField(hpack.HeaderField{Name: ":status", Value: "200"}))
+	require.NoError(t, enc.WriteField(hpack.HeaderField{Name: "x-long", Value: strings.Repeat("a", 30)}))
+	b := block.Bytes()
+	require.NoError(t, fr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: b[:10]}))
+	require.NoError(t, fr.WriteContinuation(1, false, b[10:20]))
+	require.NoError(t, fr.WriteContinuation(1, true, b[20:]))
+	require.NoError(t, fr.WriteData(1, true, nil))
+
+	trace := New(nil)
+	newDecoder(trace, 1, ServerToClient).feed(wire.Bytes())
+
+	var got []string
+	for _, f := range trace.Frames() {
+		got = append(got, f.String())
+	}
+	require.Equal(t, []string{
+		"S→C HEADERS stream=1 len=10 :status=200 x-long=" + strings.Repeat("a", 30),
+		"S→C CONTINUATION stream=1 len=10",
+		"S→C CONTINUATION stream=1 len=" + strconv.Itoa(len(b)-20) + " END_HEADERS",
+		"S→C DATA stream=1 len=0 END_STREAM",
+	}, got, "in wire order, with the whole header block on the HEADERS frame")
+}
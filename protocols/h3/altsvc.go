@@ -0,0 +1,103 @@
+package h3
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// AltSvc is a round tripper sending requests with Fallback until a response advertises HTTP/3 for its origin in an
+// Alt-Svc header (RFC 7838), and with HTTP3 afterwards. A failing HTTP/3 request is not retried with Fallback.
+//
+// Only the `h3` protocol and the `clear` value are understood, and the max age (ma) is ignored: alternatives are
+// kept for the lifetime of the round tripper.
+type AltSvc struct {
+	Fallback http.RoundTripper
+	HTTP3    http.RoundTripper
+
+	mu   sync.Mutex
+	alts map[string]string // origin host:port -> alternative host:port
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (a *AltSvc) RoundTrip(req *http.Request) (*http.Response, error) {
+	origin := authority(req.URL)
+	a.mu.Lock()
+	alt, ok := a.alts[origin]
+	a.mu.Unlock()
+	if ok {
+		r := req.Clone(req.Context())
+		r.URL.Host = alt
+		if r.Host == "" {
+			r.Host = req.URL.Host
+		}
+		return a.HTTP3.RoundTrip(r)
+	}
+
+	resp, err := a.Fallback.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if v := resp.Header.Values("Alt-Svc"); len(v) > 0 {
+		a.mu.Lock()
+		if a.alts == nil {
+			a.alts = make(map[string]string)
+		}
+		if alt, ok := h3Alternative(strings.Join(v, ","), origin); ok {
+			a.alts[origin] = alt
+		} else {
+			delete(a.alts, origin)
+		}
+		a.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// Alternative returns the HTTP/3 alternative known for origin (host:port, with the port even when it is the default
+// one of the scheme).
+func (a *AltSvc) Alternative(origin string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alt, ok := a.alts[origin]
+	return alt, ok
+}
+
+// authority returns the host:port of u, the port defaulting to the one of its scheme: Alt-Svc alternatives like
+// `h3=":443"` are relative to it, and https://example.com and https://example.com:443 are the same origin.
+func authority(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// h3Alternative returns the authority of the first h3 alternative of an Alt-Svc header value, resolved against
+// origin: `h3=":443"` keeps the host of origin.
+func h3Alternative(value, origin string) (string, bool) {
+	for _, alt := range strings.Split(value, ",") {
+		proto, rest, ok := strings.Cut(strings.TrimSpace(alt), "=")
+		if !ok || proto != "h3" {
+			continue
+		}
+		authority, _, _ := strings.Cut(rest, ";")
+		authority = strings.Trim(strings.TrimSpace(authority), `"`)
+		host, port, err := net.SplitHostPort(authority)
+		if err != nil {
+			continue
+		}
+		if host == "" {
+			host, _, err = net.SplitHostPort(origin)
+			if err != nil {
+				continue
+			}
+		}
+		return net.JoinHostPort(host, port), true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package h3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestH3Alternative(t *testing.T) {
+	for _, tc := range []struct {
+		value, want string
+		ok          bool
+	}{
+		{value: `h3=":443"; ma=2592000`, want: "example.com:443", ok: true},
+		{value: `h3-29=":8443", h3="alt.example.com:443"`, want: "alt.example.com:443", ok: true},
+		{value: `h2="alt.example.com:443"`},
+		{value: `clear`},
+		{value: `h3=443`},
+	} {
+		got, ok := h3Alternative(tc.value, "example.com:8080")
+		require.Equal(t, tc.ok, ok, tc.value)
+		require.Equal(t, tc.want, got, tc.value)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestAltSvcDefaultPort(t *testing.T) {
+	for _, tc := range []struct {
+		url, origin, alt string
+	}{
+		{url: "https://example.com/", origin: "example.com:443", alt: "example.com:443"},
+		{url: "https://example.com:8443/", origin: "example.com:8443", alt: "example.com:443"},
+		{url: "http://example.com/", origin: "example.com:80", alt: "example.com:443"},
+	} {
+		var got *http.Request
+		a := &AltSvc{
+			Fallback: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{Header: http.Header{"Alt-Svc": {`h3=":443"`}}}, nil
+			}),
+			HTTP3: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req
+				return &http.Response{}, nil
+			}),
+		}
+		for range 2 {
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			_, err = a.RoundTrip(req)
+			require.NoError(t, err)
+		}
+		alt, ok := a.Alternative(tc.origin)
+		require.True(t, ok, tc.url)
+		require.Equal(t, tc.alt, alt, tc.url)
+		require.NotNil(t, got, "%s: the second request uses HTTP/3", tc.url)
+		require.Equal(t, tc.alt, got.URL.Host, tc.url)
+	}
+}
@@ -0,0 +1,169 @@
+// Package h3 runs HTTP/3 servers and clients over QUIC on the loopback interface, for lessons that work offline.
+//
+// HTTP/3 maps HTTP semantics onto QUIC, a transport running over UDP that brings its own TLS 1.3 handshake, streams
+// and loss recovery. net/http does not implement it, this package builds on github.com/quic-go/quic-go:
+//
+//   - [NewServer] listens on a random 127.0.0.1 UDP port with a self-signed certificate, like
+//     [net/http/httptest.NewTLSServer] does on TCP.
+//   - [Server.Client] trusts that certificate and keeps TLS session tickets, so that a second connection can
+//     resume the first one and send its request in 0-RTT.
+//   - [AltSvc] is a round tripper that switches to HTTP/3 once an HTTP/1 or HTTP/2 response advertised it in an
+//     Alt-Svc header, the way browsers discover HTTP/3.
+package h3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Server is an HTTP/3 server listening on 127.0.0.1 UDP.
+type Server struct {
+	// URL is the base URL of the server, https://127.0.0.1:port.
+	URL string
+	// Addr is the UDP address the server listens on.
+	Addr *net.UDPAddr
+	// HTTP3 is the underlying server.
+	HTTP3 *http3.Server
+
+	cert  tls.Certificate
+	roots *x509.CertPool
+	conns atomic.Int64
+	udp   *net.UDPConn
+	ln    *quic.EarlyListener
+	done  chan struct{}
+}
+
+type connKey struct{}
+
+// NewServer starts an HTTP/3 server serving h. The server accepts 0-RTT data from resumed connections and lets
+// clients migrate their connections to another address. It panics when it can't listen, like
+// [net/http/httptest.NewServer].
+func NewServer(h http.Handler) *Server {
+	cert, roots, err := selfSigned()
+	if err != nil {
+		panic(fmt.Sprintf("h3: generating a certificate: %v", err))
+	}
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		panic(fmt.Sprintf("h3: failed to listen on a port: %v", err))
+	}
+	s := &Server{
+		Addr:  udp.LocalAddr().(*net.UDPAddr),
+		cert:  cert,
+		roots: roots,
+		udp:   udp,
+		done:  make(chan struct{}),
+	}
+	s.URL = "https://" + s.Addr.String()
+	s.HTTP3 = &http3.Server{
+		Handler: h,
+		ConnContext: func(ctx context.Context, c *quic.Conn) context.Context {
+			s.conns.Add(1)
+			return context.WithValue(ctx, connKey{}, c)
+		},
+	}
+	s.ln, err = quic.ListenEarly(udp, http3.ConfigureTLSConfig(s.TLSConfig()), &quic.Config{Allow0RTT: true})
+	if err != nil {
+		udp.Close()
+		panic(fmt.Sprintf("h3: %v", err))
+	}
+	go func() {
+		defer close(s.done)
+		s.HTTP3.ServeListener(s.ln)
+	}()
+	return s
+}
+
+// TLSConfig returns a server TLS configuration with the certificate of s, for a TCP server of the same origin.
+func (s *Server) TLSConfig() *tls.Config {
+	return &tls.Config{Certificates: []tls.Certificate{s.cert}}
+}
+
+// ClientTLSConfig returns a client TLS configuration trusting the certificate of s, with a session cache for
+// resumption.
+func (s *Server) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		RootCAs:            s.roots,
+		NextProtos:         []string{http3.NextProtoH3},
+		ClientSessionCache: tls.NewLRUClientSessionCache(8),
+	}
+}
+
+// Transport returns an HTTP/3 transport trusting the certificate of s.
+func (s *Server) Transport() *http3.Transport {
+	return &http3.Transport{TLSClientConfig: s.ClientTLSConfig()}
+}
+
+// Client returns a client speaking HTTP/3 to s. Its transport is a [*http3.Transport].
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: s.Transport()}
+}
+
+// SetQUICHeaders adds the Alt-Svc header announcing s to h, e.g. `h3=":50123"; ma=2592000`.
+func (s *Server) SetQUICHeaders(h http.Header) error {
+	return s.HTTP3.SetQUICHeaders(h)
+}
+
+// Conns returns the number of QUIC connections s accepted.
+func (s *Server) Conns() int {
+	return int(s.conns.Load())
+}
+
+// Close closes the listener and the connections of s, and waits for the server to stop.
+func (s *Server) Close() {
+	s.HTTP3.Close()
+	s.ln.Close()
+	s.udp.Close()
+	<-s.done
+}
+
+// Conn returns the QUIC connection r came in on, or nil when r was not served by a [Server].
+func Conn(r *http.Request) *quic.Conn {
+	c, _ := r.Context().Value(connKey{}).(*quic.Conn)
+	return c
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and a pool trusting it.
+func selfSigned() (tls.Certificate, *x509.CertPool, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"go-dojo"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots, nil
+}
@@ -0,0 +1,108 @@
+package h3
+
+import (
+	"net"
+	"sync"
+)
+
+// NAT relays the UDP datagrams of a client to a server like a NAT router: the server sees them coming from an
+// outside address of the NAT rather than from the client. [NAT.Rebind] moves to a new outside address, like a NAT
+// whose mapping expired, or a phone moving from Wi-Fi to cellular: the client keeps sending to the same address and
+// is not told anything, while the server sees its packets coming from elsewhere.
+//
+// Datagrams the server still sends to the previous outside address are lost, as with a real NAT: QUIC sends them
+// again. The NAT relays a single client, the last one that sent a datagram.
+type NAT struct {
+	inside *net.UDPConn
+	server *net.UDPAddr
+
+	mu      sync.Mutex
+	client  net.Addr
+	outside *net.UDPConn
+	wg      sync.WaitGroup
+}
+
+// NewNAT starts a NAT relaying to server.
+func NewNAT(server *net.UDPAddr) (*NAT, error) {
+	inside, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	n := &NAT{inside: inside, server: server}
+	if err := n.Rebind(); err != nil {
+		inside.Close()
+		return nil, err
+	}
+	n.wg.Go(n.relayOut)
+	return n, nil
+}
+
+// Addr returns the address clients send to instead of the server.
+func (n *NAT) Addr() *net.UDPAddr {
+	return n.inside.LocalAddr().(*net.UDPAddr)
+}
+
+// Outside returns the current outside address, the one the server sees.
+func (n *NAT) Outside() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.outside.LocalAddr().String()
+}
+
+// Rebind sends the next datagrams of the client from a new outside address, and closes the previous one.
+func (n *NAT) Rebind() error {
+	out, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	if n.outside != nil {
+		n.outside.Close()
+	}
+	n.outside = out
+	n.mu.Unlock()
+	n.wg.Go(func() { n.relayIn(out) })
+	return nil
+}
+
+// Close stops relaying.
+func (n *NAT) Close() {
+	n.inside.Close()
+	n.mu.Lock()
+	n.outside.Close()
+	n.mu.Unlock()
+	n.wg.Wait()
+}
+
+// relayOut forwards the datagrams of the client to the server, from the current outside address.
+func (n *NAT) relayOut() {
+	buf := make([]byte, 64<<10)
+	for {
+		size, client, err := n.inside.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		n.mu.Lock()
+		n.client = client
+		out := n.outside
+		n.mu.Unlock()
+		out.WriteTo(buf[:size], n.server)
+	}
+}
+
+// relayIn forwards the datagrams the server sends to out back to the client.
+func (n *NAT) relayIn(out *net.UDPConn) {
+	buf := make([]byte, 64<<10)
+	for {
+		size, _, err := out.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		n.mu.Lock()
+		client := n.client
+		n.mu.Unlock()
+		if client != nil {
+			n.inside.WriteTo(buf[:size], client)
+		}
+	}
+}
@@ -12,6 +12,8 @@ func getRequestProtocol(w http.ResponseWriter, r *http.Request) {
 }
 
 // Example_http1_1 demonstrates handling an HTTP/1.1 request and printing the protocol version.
+//
+//dojo:meta difficulty=beginner minutes=3 tags=basics,http
 func Example_http1_1() {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		getRequestProtocol(w, req)
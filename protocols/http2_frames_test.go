@@ -0,0 +1,194 @@
+package protocols
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h2trace"
+)
+
+// frameSummary prints a frame without the values that change between runs and Go versions: settings, window
+// increments, lengths and regular headers.
+func frameSummary(f h2trace.Frame) string {
+	if f.Preface {
+		return fmt.Sprintf("%s PREFACE", f.Dir)
+	}
+	s := fmt.Sprintf("%s %s stream=%d", f.Dir, f.Type, f.Stream)
+	for _, h := range f.Headers {
+		if h.IsPseudo() && h.Name != ":authority" {
+			s += fmt.Sprintf(" %s=%s", h.Name, h.Value)
+		}
+	}
+	if f.Flags.Has(http2.FlagDataEndStream) && (f.Type == http2.FrameData || f.Type == http2.FrameHeaders) {
+		s += " END_STREAM"
+	}
+	return s
+}
+
+// Example_http2ConnectionPreface shows the first bytes of an HTTP/2 connection, once TLS negotiated "h2" with ALPN.
+// The client sends a fixed 24 bytes preface, chosen so that an HTTP/1 server fails on it, followed by its SETTINGS
+// frame. The server starts with its own SETTINGS. Each side acknowledges the SETTINGS of the other (ACK frames are
+// left out here, as they can come at any point) and enlarges the connection flow control window with a
+// WINDOW_UPDATE on stream 0, the connection itself.
+//
+// The request then takes stream 1: a HEADERS frame whose HPACK encoded pseudo headers replace the HTTP/1 request line,
+// and END_STREAM since a GET has no body. The response comes back on the same stream as HEADERS, then DATA.
+//
+// Run `dojo run Example_http2ConnectionPreface` after replacing nil by os.Stdout in h2trace.New to see every frame
+// decoded, settings included.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http requires=Example_http2_TLS
+func Example_http2ConnectionPreface() {
+	trace := h2trace.New(nil)
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(getRequestProtocol))
+	h2trace.Attach(testServer, trace, nil)
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	resp, err := testServer.Client().Get(testServer.URL)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	preface := trace.Filter(func(f h2trace.Frame) bool { return f.Preface })[0]
+	fmt.Printf("% x\n%q\n", preface.Raw, preface.Raw)
+	frames := trace.Frames()
+	for _, dir := range []h2trace.Direction{h2trace.ClientToServer, h2trace.ServerToClient} {
+		for _, f := range frames {
+			if f.Dir == dir && !(f.Type == http2.FrameSettings && f.Flags.Has(http2.FlagSettingsAck)) {
+				fmt.Println(frameSummary(f))
+			}
+		}
+	}
+	// Output:
+	// 50 52 49 20 2a 20 48 54 54 50 2f 32 2e 30 0d 0a 0d 0a 53 4d 0d 0a 0d 0a
+	// "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+	// C→S PREFACE
+	// C→S SETTINGS stream=0
+	// C→S WINDOW_UPDATE stream=0
+	// C→S HEADERS stream=1 :method=GET :path=/ :scheme=https END_STREAM
+	// S→C SETTINGS stream=0
+	// S→C WINDOW_UPDATE stream=0
+	// S→C HEADERS stream=1 :status=200
+	// S→C DATA stream=1 END_STREAM
+}
+
+// Example_http2Multiplexing shows requests sharing a single connection, each on its own stream. The handler waits
+// until the three requests have arrived before answering any of them: with HTTP/1.1, this would take three
+// connections, here streams 3, 5 and 7 of the same connection are in flight at the same time. Client streams are odd,
+// stream 1 was taken by a first request that established the connection.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=concurrency,http requires=Example_http2ConnectionPreface
+func Example_http2Multiplexing() {
+	const concurrent = 3
+	var arrived sync.WaitGroup
+	arrived.Add(concurrent)
+	trace := h2trace.New(nil)
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/wait" {
+			arrived.Done()
+			arrived.Wait()
+		}
+		getRequestProtocol(w, r)
+	}))
+	h2trace.Attach(testServer, trace, nil)
+	testServer.StartTLS()
+	defer testServer.Close()
+	client := testServer.Client()
+
+	get := func(path string) {
+		resp, err := client.Get(testServer.URL + path)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	get("/")
+	var wg sync.WaitGroup
+	for range concurrent {
+		wg.Go(func() { get("/wait") })
+	}
+	wg.Wait()
+
+	var streams []uint32
+	for _, f := range trace.Frames() {
+		if f.Dir == h2trace.ClientToServer && f.Type == http2.FrameHeaders {
+			streams = append(streams, f.Stream)
+		}
+	}
+	slices.Sort(streams)
+	fmt.Println("connections:", trace.Conns())
+	fmt.Println("request streams:", streams)
+	// Output:
+	// connections: 1
+	// request streams: [1 3 5 7]
+}
+
+// Example_http2FlowControl shows how HTTP/2 flow control keeps a fast sender from flooding a slow receiver. Each side
+// announces in its SETTINGS how many bytes it accepts per stream (INITIAL_WINDOW_SIZE), here 64 KiB on the server.
+// The client can't send more DATA than that window until the server grants more with WINDOW_UPDATE frames, which it
+// does as the handler consumes the body.
+//
+// 65535 bytes is also the window every stream starts with before the SETTINGS of the peer arrive, and the client does
+// not wait for them: a server announcing a smaller window would receive more than it allows and reset the stream with
+// FLOW_CONTROL_ERROR.
+//
+// This is per stream: a large upload on one stream does not block the others, unlike the single TCP window shared by
+// everything on an HTTP/1.1 connection.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http requires=Example_http2ConnectionPreface
+func Example_http2FlowControl() {
+	const window = 65535
+	trace := h2trace.New(nil)
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		fmt.Fprintf(w, "received %d bytes\n", n)
+	}))
+	h2trace.Attach(testServer, trace, &http2.Server{MaxUploadBufferPerStream: window})
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	body := bytes.NewReader([]byte(strings.Repeat("x", 300_000)))
+	resp, err := testServer.Client().Post(testServer.URL, "text/plain", body)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println("Error reading body:", err)
+		return
+	}
+	fmt.Print(string(b))
+
+	var largest uint32
+	updates := 0
+	for _, f := range trace.Frames() {
+		switch {
+		case f.Dir == h2trace.ClientToServer && f.Type == http2.FrameData:
+			largest = max(largest, f.Length)
+		case f.Dir == h2trace.ServerToClient && f.Type == http2.FrameWindowUpdate && f.Stream == 1:
+			updates++
+		}
+	}
+	fmt.Println("largest DATA frame fits in the window:", largest <= window)
+	fmt.Println("server granted more window to stream 1:", updates > 0)
+	// Output:
+	// received 300000 bytes
+	// largest DATA frame fits in the window: true
+	// server granted more window to stream 1: true
+}
@@ -8,6 +8,8 @@ import (
 )
 
 // Example_http2_without_TLS demonstrates how HTTP 2 will not be enabled with default if not using TLS.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=http,pitfall requires=Example_http1_1
 func Example_http2_without_TLS() {
 	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		getRequestProtocol(w, req)
@@ -32,6 +34,8 @@ func Example_http2_without_TLS() {
 }
 
 // Example_http2_TLS demonstrates how to enable HTTP 2 using httptest server package.
+//
+//dojo:meta difficulty=intermediate minutes=3 tags=http requires=Example_http2_without_TLS
 func Example_http2_TLS() {
 	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		getRequestProtocol(w, req)
@@ -0,0 +1,44 @@
+//go:build !race
+
+package protocols
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h3"
+)
+
+// Example_http3ZeroRTT shows 0-RTT resumption. A new QUIC connection costs one round trip before the client can send
+// its request (TCP and TLS 1.3 together cost two). After a first connection, the server gives the client a session
+// ticket. A second connection presenting that ticket can carry the request in its very first packets, encrypted
+// with keys derived from the previous session: zero round trips.
+//
+// 0-RTT data can be replayed by an attacker capturing the packets, and the server can't tell. quic-go only sends a
+// request in 0-RTT when the method says so, http3.MethodGet0RTT (`GET_0RTT`), which goes on the wire as a GET: keep
+// it for requests that are safe to repeat.
+//
+// This file is left out of `go test -race`: quic-go reads the transport parameters of the server while the 0-RTT
+// request opens its stream, without synchronization, and the race detector reports it once in a while.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http,security requires=Example_http3
+func Example_http3ZeroRTT() {
+	server := h3.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s, 0-RTT: %t\n", r.Method, r.Proto, h3.Conn(r).ConnectionState().Used0RTT)
+	}))
+	defer server.Close()
+	client := server.Client()
+	transport := client.Transport.(*http3.Transport)
+	defer transport.Close()
+
+	printBody(client, http.MethodGet, server.URL)
+	transport.CloseIdleConnections()
+	printBody(client, http3.MethodGet0RTT, server.URL)
+	fmt.Println("connections:", server.Conns())
+	// Output:
+	// GET HTTP/3.0, 0-RTT: false
+	// GET HTTP/3.0, 0-RTT: true
+	// connections: 2
+}
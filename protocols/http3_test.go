@@ -0,0 +1,148 @@
+package protocols
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h3"
+)
+
+// printBody prints the body of the response to a method request of url, or the error.
+func printBody(client *http.Client, method, url string) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println("Error reading body:", err)
+		return
+	}
+	fmt.Print(string(b))
+}
+
+// Example_http3 serves getRequestProtocol over HTTP/3. There is no TCP connection: the request travels in QUIC
+// packets over UDP, and the TLS 1.3 handshake is part of the QUIC handshake, so QUIC is always encrypted. ALPN
+// negotiates "h3" like it negotiates "h2" for HTTP/2.
+//
+// net/http does not speak HTTP/3, the [h3] package builds the server and the client on quic-go, on 127.0.0.1 with a
+// self-signed certificate like httptest.NewTLSServer.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=http requires=Example_http2_TLS
+func Example_http3() {
+	server := h3.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getRequestProtocol(w, r)
+		fmt.Fprintf(w, "ALPN: %s\n", r.TLS.NegotiatedProtocol)
+	}))
+	defer server.Close()
+	client := server.Client()
+	defer client.Transport.(*http3.Transport).Close()
+
+	printBody(client, http.MethodGet, server.URL)
+	// Output:
+	// Request Protocol: HTTP/3.0
+	// ALPN: h3
+}
+
+// Example_http3AltSvc shows how a client discovers HTTP/3. A URL does not tell which protocol a server speaks and
+// HTTP/3 runs over UDP, so clients start with HTTP/1.1 or HTTP/2 over TCP. The server advertises HTTP/3 in an
+// Alt-Svc response header, `h3=":port"` meaning "the same host, this UDP port". Later requests to the origin go
+// over HTTP/3, still with the Host of the origin.
+//
+// Both servers present the same certificate, the alternative must be trusted for the origin. Browsers also keep the
+// TCP connection as a fallback when UDP is blocked, [h3.AltSvc] does not.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http requires=Example_http3
+func Example_http3AltSvc() {
+	server := h3.NewServer(http.HandlerFunc(getRequestProtocol))
+	defer server.Close()
+	h2Server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.SetQUICHeaders(w.Header())
+		getRequestProtocol(w, r)
+	}))
+	h2Server.TLS = server.TLSConfig()
+	h2Server.EnableHTTP2 = true
+	h2Server.StartTLS()
+	defer h2Server.Close()
+
+	h2Transport := h2Server.Client().Transport.(*http.Transport)
+	h2Transport.TLSClientConfig.RootCAs = server.ClientTLSConfig().RootCAs
+	h3Transport := server.Transport()
+	defer h3Transport.Close()
+	altSvc := &h3.AltSvc{Fallback: h2Transport, HTTP3: h3Transport}
+	client := &http.Client{Transport: altSvc}
+
+	printBody(client, http.MethodGet, h2Server.URL)
+	alt, _ := altSvc.Alternative(strings.TrimPrefix(h2Server.URL, "https://"))
+	fmt.Println("alternative is the HTTP/3 server:", alt == server.Addr.String())
+	printBody(client, http.MethodGet, h2Server.URL)
+	// Output:
+	// Request Protocol: HTTP/2.0
+	// alternative is the HTTP/3 server: true
+	// Request Protocol: HTTP/3.0
+}
+
+// Example_http3ConnectionMigration shows a connection surviving a change of client address, like a phone moving from
+// Wi-Fi to cellular or a NAT router forgetting its mapping. A TCP connection is identified by its addresses and
+// ports and breaks. A QUIC connection is identified by connection IDs carried in every packet: when packets of a
+// known connection come from a new address, the server checks the client is really there (PATH_CHALLENGE and
+// PATH_RESPONSE frames) and moves the connection to that address, without a new handshake.
+//
+// Here the client goes through an [h3.NAT] that changes its outside port mid connection and drops what the server
+// still sends to the old one. The server keeps using the old path until the new one is validated, QUIC retransmits
+// what was lost meanwhile. Clients can also migrate on purpose, with
+// quic.Conn.AddPath, Path.Probe and Path.Switch in quic-go.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http requires=Example_http3
+func Example_http3ConnectionMigration() {
+	server := h3.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, r.RemoteAddr)
+	}))
+	defer server.Close()
+	nat, err := h3.NewNAT(server.Addr)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer nat.Close()
+	client := server.Client()
+	defer client.Transport.(*http3.Transport).Close()
+
+	remoteAddr := func() string {
+		resp, err := client.Get("https://" + nat.Addr().String())
+		if err != nil {
+			return err.Error()
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return strings.TrimSpace(string(b))
+	}
+	fmt.Println("server sees the first port:", remoteAddr() == nat.Outside())
+
+	if err := nat.Rebind(); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	// The response to this request only comes back once the server moved to the new port: what it sends to the old
+	// one is lost, and it sends nothing but the PATH_CHALLENGE to the new one before validating it. The request itself
+	// may have been handled before the move, the next one is handled after.
+	remoteAddr()
+	fmt.Println("server follows to the second port:", remoteAddr() == nat.Outside())
+	fmt.Println("connections:", server.Conns())
+	// Output:
+	// server sees the first port: true
+	// server follows to the second port: true
+	// connections: 1
+}
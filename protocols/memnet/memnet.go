@@ -0,0 +1,71 @@
+// Package memnet is an in-memory network for net/http clients and servers, to run them in a testing/synctest
+// bubble: real sockets wait on the operating system, which the fake clock of the bubble knows nothing about, while
+// the connections of [net.Pipe] wait on channels and timers of the bubble, deadlines included.
+//
+//	ln := memnet.Listen()
+//	srv := &http.Server{Handler: h, ReadHeaderTimeout: time.Second}
+//	go srv.Serve(ln)
+//	client := &http.Client{Transport: &http.Transport{DialContext: ln.DialContext}}
+//	client.Get("http://memnet/") // any host, all dials reach ln
+//
+// Pipes have no buffer: a write blocks until the other side reads it, like a TCP connection whose buffers are full.
+package memnet
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// Listener is a net.Listener whose connections are dialed in memory with DialContext.
+type Listener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// Listen returns a new Listener.
+func Listen() *Listener {
+	return &Listener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+// Accept waits for the next connection dialed by DialContext.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections, dials in progress fail.
+func (l *Listener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr returns the address of the listener, "memnet".
+func (l *Listener) Addr() net.Addr {
+	return addr{}
+}
+
+// DialContext connects to the listener whatever network and address, for http.Transport.DialContext. It waits until
+// the connection is accepted: a listener nobody accepts from behaves like a host that doesn't answer, and the dial
+// fails when ctx is done.
+func (l *Listener) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, &net.OpError{Op: "dial", Net: "memnet", Err: net.ErrClosed}
+	case <-ctx.Done():
+		return nil, &net.OpError{Op: "dial", Net: "memnet", Err: ctx.Err()}
+	}
+}
+
+type addr struct{}
+
+func (addr) Network() string { return "memnet" }
+func (addr) String() string  { return "memnet" }
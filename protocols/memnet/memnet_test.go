@@ -0,0 +1,47 @@
+package memnet
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ln := Listen()
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			io.WriteString(conn, "hello")
+		}()
+		conn, err := ln.DialContext(context.Background(), "tcp", "anywhere:80")
+		require.NoError(t, err)
+		b, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(b))
+		conn.Close()
+
+		// Nobody accepts anymore: the dial waits for its context.
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = ln.DialContext(ctx, "tcp", "anywhere:80")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, time.Second, time.Since(start))
+
+		require.NoError(t, ln.Close())
+		require.NoError(t, ln.Close())
+		_, err = ln.Accept()
+		require.ErrorIs(t, err, net.ErrClosed)
+		_, err = ln.DialContext(context.Background(), "tcp", "anywhere:80")
+		require.ErrorIs(t, err, net.ErrClosed)
+	})
+}
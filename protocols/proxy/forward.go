@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// Forward is a forward proxy, for clients configured with http.Transport.Proxy or $HTTPS_PROXY:
+//
+//   - CONNECT requests open a tunnel to the host they name, then the proxy copies bytes both ways. For https://
+//     URLs the client speaks TLS with the origin through the tunnel: the proxy only sees the host and port.
+//   - Requests with an absolute URL, for http:// URLs, are forwarded like a reverse proxy would, and the proxy sees
+//     everything.
+type Forward struct {
+	Transport http.RoundTripper                                                 // http.DefaultTransport when nil
+	Dial      func(ctx context.Context, network, addr string) (net.Conn, error) // for tunnels, net.Dialer when nil
+
+	once    sync.Once
+	forward *httputil.ReverseProxy
+}
+
+func (f *Forward) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodConnect:
+		f.tunnel(w, r)
+	case r.URL.IsAbs():
+		f.once.Do(func() {
+			f.forward = &httputil.ReverseProxy{
+				Rewrite:   func(*httputil.ProxyRequest) {}, // the URL of the request is the target
+				Transport: f.Transport,
+			}
+		})
+		f.forward.ServeHTTP(w, r)
+	default:
+		http.Error(w, "not a proxy request", http.StatusBadRequest)
+	}
+}
+
+// tunnel dials the host of a CONNECT request and relays bytes between it and the client until either side closes.
+func (f *Forward) tunnel(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 1 {
+		// HTTP/2 CONNECT runs on a stream, not a connection to hijack.
+		http.Error(w, "CONNECT over "+r.Proto+" is not supported", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	dial := f.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	upstream, err := dial(r.Context(), "tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(conn, upstream)
+		conn.Close() // unblocks the copy below when the origin closes first
+	}()
+	// The client may have sent the first bytes of the tunnel, e.g. its TLS ClientHello, with the CONNECT request:
+	// they are in the buffer of the server.
+	io.Copy(upstream, brw.Reader)
+	upstream.Close()
+	<-done
+}
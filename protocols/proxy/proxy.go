@@ -0,0 +1,84 @@
+// Package proxy builds reverse and forward proxies for the lessons on proxies, out of httputil.ReverseProxy and a
+// CONNECT tunnel, along with middlewares to see each hop a request goes through.
+//
+// A reverse proxy stands in front of servers: clients think they talk to the origin, the proxy picks a backend and
+// sends it a new request. A forward proxy stands in front of clients, which know they talk to a proxy: they send it
+// absolute URLs, `GET http://example.com/ HTTP/1.1`, or ask for a tunnel with `CONNECT example.com:443 HTTP/1.1`
+// and speak TLS through it, end to end with the origin.
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// Middleware wraps a handler to do something before, after or instead of it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with mws, the first one outermost: Chain(h, a, b) serves a request with a, then b, then h.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Via adds the hop to the Via header of requests, as proxies should (RFC 9110, section 7.6.3): the protocol the
+// request came with, and the name of the proxy, e.g. `Via: 2.0 edge, 1.1 internal` after two proxies.
+func Via(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Add("Via", fmt.Sprintf("%d.%d %s", r.ProtoMajor, r.ProtoMinor, name))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Log writes the request line of each request to w, prefixed with name, e.g. `edge: GET / HTTP/2.0`.
+func Log(w io.Writer, name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s: %s %s %s\n", name, r.Method, r.RequestURI, r.Proto)
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// Reverse returns a reverse proxy sending requests to target, with a Rewrite function rather than a Director:
+//
+//   - The X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers are set from the request the proxy got,
+//     and those sent by the client are dropped, whoever it is: they can't be trusted.
+//   - The Host header is the one of target.
+//   - Hop-by-hop headers are removed before rewrite runs, a client can't make the proxy drop the headers it adds.
+//
+// The transport is http.DefaultTransport: HTTP/1.1 to http:// targets, whatever the protocol of the request.
+func Reverse(target *url.URL) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+	}
+}
+
+// ReverseBehindProxy is Reverse for a proxy behind another one it trusts, e.g. an internal router behind an edge
+// load balancer: it keeps the X-Forwarded-Host and X-Forwarded-Proto headers set by the proxy in front, and appends
+// the address of that proxy to its X-Forwarded-For. Reverse would replace them all with what it sees, the address
+// and protocol of the proxy in front rather than of the client.
+func ReverseBehindProxy(target *url.URL) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Header["X-Forwarded-For"] = pr.In.Header["X-Forwarded-For"]
+			pr.SetXForwarded()
+			for _, h := range []string{"X-Forwarded-Host", "X-Forwarded-Proto"} {
+				if v := pr.In.Header.Get(h); v != "" {
+					pr.Out.Header.Set(h, v)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	var log bytes.Buffer
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+		fmt.Fprint(w, strings.Join(r.Header.Values("Via"), ", "))
+	}), mw("a"), Log(&log, "test"), Via("p1"), Via("p2"), mw("b"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+	require.Equal(t, []string{"a", "b", "handler"}, order)
+	require.Equal(t, "1.1 p1, 1.1 p2", w.Body.String())
+	require.Equal(t, "test: GET /x HTTP/1.1\n", log.String())
+}
+
+func TestReverseBehindProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Forwarded-Host"),
+			r.Header.Get("X-Forwarded-Proto"))
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	for _, c := range []struct {
+		name  string
+		proxy http.Handler
+		want  string
+	}{
+		{"Reverse", Reverse(target), "127.0.0.1|front|http"},
+		{"ReverseBehindProxy", ReverseBehindProxy(target), "10.0.0.1, 127.0.0.1|example.com|https"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			front := httptest.NewServer(c.proxy)
+			defer front.Close()
+			req, _ := http.NewRequest(http.MethodGet, front.URL, nil)
+			req.Host = "front"
+			req.Header.Set("X-Forwarded-For", "10.0.0.1")
+			req.Header.Set("X-Forwarded-Host", "example.com")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			resp, err := front.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			require.Equal(t, c.want, string(b))
+		})
+	}
+}
+
+func TestForward(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "origin")
+	}))
+	defer origin.Close()
+	forward := httptest.NewServer(&Forward{})
+	defer forward.Close()
+
+	resp, err := http.Get(forward.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// A tunnel by hand: CONNECT, then an HTTP/1.1 request to the origin through it.
+	conn, err := net.Dial("tcp", forward.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	addr := origin.Listener.Addr().String()
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", addr, addr)
+	br := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: %s\r\n\r\n", addr)
+	resp, err = http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	b, _ := io.ReadAll(resp.Body)
+	require.Equal(t, "origin", string(b))
+
+	proxyURL, _ := url.Parse(forward.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err = client.Get("http://" + addr)
+	require.NoError(t, err)
+	b, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, "origin", string(b))
+}
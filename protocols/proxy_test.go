@@ -0,0 +1,328 @@
+package protocols
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h2c"
+	"github.com/juan-carvajal/go-dojo/protocols/proxy"
+	"github.com/juan-carvajal/go-dojo/protocols/ws"
+)
+
+// hostNames replaces the addresses of test servers with names in the output of a lesson, their ports change.
+func hostNames(servers map[string]*httptest.Server) *strings.Replacer {
+	var oldnew []string
+	for name, s := range servers {
+		oldnew = append(oldnew, s.Listener.Addr().String(), name)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// printHeaders prints the given headers of r, or "-" for those missing.
+func printHeaders(r *http.Request, names *strings.Replacer, keys ...string) {
+	for _, k := range keys {
+		v := strings.Join(r.Header.Values(k), ", ")
+		if k == "Host" {
+			v = r.Host
+		}
+		if v == "" {
+			v = "-"
+		}
+		fmt.Printf("  %s: %s\n", k, names.Replace(v))
+	}
+}
+
+// Example_proxyChain shows a request going through two reverse proxies, each hop with its own protocol: the client
+// speaks HTTP/2 over TLS to the edge proxy, which terminates TLS and speaks HTTP/1.1 to the internal proxy, which
+// speaks h2c to the backend. Each hop is a new request: r.Proto changes, and so does the Host header.
+//
+// What the backend knows of the original request comes from headers the proxies add:
+//
+//   - Via lists the hops and their protocol, added here by the proxy.Via middleware.
+//   - X-Forwarded-For lists the addresses of the client and the proxies, X-Forwarded-Host the host the client asked
+//     for, X-Forwarded-Proto whether it used TLS. RFC 7239 standardized them as Forwarded, which few use.
+//
+// The edge uses proxy.Reverse, which drops the X-Forwarded-* headers of the client: anyone can send them. The
+// internal proxy uses proxy.ReverseBehindProxy, which keeps those of the edge: with proxy.Reverse it would tell the
+// backend the request came from the edge, over plain HTTP.
+//
+//dojo:meta difficulty=intermediate minutes=15 tags=http requires=Example_http2_TLS,Example_h2cPriorKnowledge
+func Example_proxyChain() {
+	var names *strings.Replacer
+	backend := httptest.NewUnstartedServer(proxy.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		printHeaders(r, names, "Host", "Via", "X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Proto")
+	}), proxy.Log(os.Stdout, "backend")))
+	backend.Config.Protocols = h2c.Protocols(true)
+	backend.Start()
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	toBackend := proxy.ReverseBehindProxy(backendURL)
+	toBackend.Transport = &http.Transport{Protocols: h2c.Protocols(false)}
+	internal := httptest.NewServer(proxy.Chain(toBackend, proxy.Log(os.Stdout, "internal"), proxy.Via("internal")))
+	defer internal.Close()
+
+	internalURL, _ := url.Parse(internal.URL)
+	edge := httptest.NewUnstartedServer(proxy.Chain(proxy.Reverse(internalURL),
+		proxy.Log(os.Stdout, "edge"), proxy.Via("edge")))
+	edge.EnableHTTP2 = true
+	edge.StartTLS()
+	defer edge.Close()
+	names = hostNames(map[string]*httptest.Server{"backend": backend, "internal": internal, "edge": edge})
+
+	req, _ := http.NewRequest(http.MethodGet, edge.URL+"/hello", nil)
+	req.Header.Set("X-Forwarded-For", "6.6.6.6") // a lie, dropped by the edge
+	resp, err := edge.Client().Do(req)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	resp.Body.Close()
+	fmt.Println("client:", resp.Proto, resp.Status)
+	// Output:
+	// edge: GET /hello HTTP/2.0
+	// internal: GET /hello HTTP/1.1
+	// backend: GET /hello HTTP/2.0
+	//   Host: backend
+	//   Via: 2.0 edge, 1.1 internal
+	//   X-Forwarded-For: 127.0.0.1, 127.0.0.1
+	//   X-Forwarded-Host: edge
+	//   X-Forwarded-Proto: https
+	// client: HTTP/2.0 200 OK
+}
+
+// Example_proxyRewriteVsDirector shows why httputil.ReverseProxy got a Rewrite hook in Go 1.20, next to Director.
+// A proxy authenticating its clients here tells the backend who they are in an X-User header. With Director:
+//
+//   - The client's own X-Forwarded-For is kept, and the proxy appends to it: the backend can't tell the lie from
+//     the truth unless it only trusts the last address.
+//   - Hop-by-hop headers are removed after Director, including those the client lists in its Connection header:
+//     `Connection: X-User` makes the proxy drop the header it just set, e.g. to pass as anonymous, or to strip a
+//     header another proxy relies on.
+//
+// Rewrite runs after hop-by-hop headers are removed, and its incoming X-Forwarded-* headers are dropped unless it
+// keeps them on purpose.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,security,pitfall requires=Example_proxyChain
+func Example_proxyRewriteVsDirector() {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("X-Forwarded-For %q, X-User %q\n", r.Header.Get("X-Forwarded-For"), r.Header.Get("X-User"))
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	director := httputil.NewSingleHostReverseProxy(target)
+	setUser := director.Director
+	director.Director = func(r *http.Request) {
+		setUser(r)
+		r.Header.Set("X-User", "guest")
+	}
+	rewrite := &httputil.ReverseProxy{Rewrite: func(pr *httputil.ProxyRequest) {
+		pr.SetURL(target)
+		pr.SetXForwarded()
+		pr.Out.Header.Set("X-User", "guest")
+	}}
+
+	for _, p := range []struct {
+		name  string
+		proxy *httputil.ReverseProxy
+	}{{"Director", director}, {"Rewrite", rewrite}} {
+		front := httptest.NewServer(p.proxy)
+		req, _ := http.NewRequest(http.MethodGet, front.URL, nil)
+		req.Header.Set("X-Forwarded-For", "6.6.6.6")
+		req.Header.Set("Connection", "X-User")
+		fmt.Print(p.name, ": ")
+		resp, err := front.Client().Do(req)
+		if err != nil {
+			fmt.Println("Error:", err)
+		} else {
+			resp.Body.Close()
+		}
+		front.Close()
+	}
+	// Output:
+	// Director: X-Forwarded-For "6.6.6.6, 127.0.0.1", X-User ""
+	// Rewrite: X-Forwarded-For "127.0.0.1", X-User "guest"
+}
+
+// Example_proxyHopByHop shows hop-by-hop headers: they describe a single connection, not the request, and a proxy
+// removes them rather than forwarding them, both in requests and responses. RFC 9110 lists Connection,
+// Proxy-Connection, Keep-Alive, TE, Transfer-Encoding and Upgrade, plus any header named in Connection; net/http
+// also removes Proxy-Authenticate and Proxy-Authorization, meant for the proxy itself.
+//
+// TE survives when it is exactly "trailers", which gRPC needs, and Upgrade when the proxy passes it through on
+// purpose, see Example_proxyWebSocket.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,spec requires=Example_proxyChain
+func Example_proxyHopByHop() {
+	keys := []string{"Connection", "Keep-Alive", "Proxy-Authorization", "Te", "X-Conn-Debug", "X-End-To-End"}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("backend got:")
+		printHeaders(r, strings.NewReplacer(), keys...)
+		w.Header().Set("Connection", "X-Conn-Debug")
+		w.Header().Set("X-Conn-Debug", "conn 42")
+		w.Header().Set("X-End-To-End", "yes")
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+	front := httptest.NewServer(proxy.Reverse(target))
+	defer front.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL, nil)
+	req.Header.Set("Connection", "X-Conn-Debug")
+	req.Header.Set("Keep-Alive", "timeout=5")
+	req.Header.Set("Proxy-Authorization", "Basic c2VjcmV0")
+	req.Header.Set("Te", "trailers")
+	req.Header.Set("X-Conn-Debug", "conn 7")
+	req.Header.Set("X-End-To-End", "yes")
+	resp, err := front.Client().Do(req)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	resp.Body.Close()
+	fmt.Println("client got:")
+	for _, k := range []string{"X-Conn-Debug", "X-End-To-End"} {
+		fmt.Printf("  %s: %s\n", k, cmp.Or(resp.Header.Get(k), "-"))
+	}
+	// Output:
+	// backend got:
+	//   Connection: -
+	//   Keep-Alive: -
+	//   Proxy-Authorization: -
+	//   Te: trailers
+	//   X-Conn-Debug: -
+	//   X-End-To-End: yes
+	// client got:
+	//   X-Conn-Debug: -
+	//   X-End-To-End: yes
+}
+
+// Example_proxyWebSocket shows a WebSocket connection through a reverse proxy. Upgrade and Connection are hop-by-hop
+// headers, yet httputil.ReverseProxy passes `Upgrade: websocket` through: it sends the handshake to the backend, and
+// on `101 Switching Protocols` it hijacks the client connection and copies bytes both ways, as a tunnel.
+//
+// Only the handshake is HTTP: the proxy adds X-Forwarded-For to it as to any request, and the frames after it go
+// through untouched. Over an HTTP/2 front connection, this needs the extended CONNECT of RFC 8441 instead.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http requires=Example_webSocket,Example_proxyHopByHop
+func Example_proxyWebSocket() {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("backend: %s %s, Upgrade: %s, X-Forwarded-For: %s\n", r.Method, r.Proto, r.Header.Get("Upgrade"),
+			r.Header.Get("X-Forwarded-For"))
+		conn, err := ws.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(op, append([]byte("echo "), msg...))
+		}
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+	front := httptest.NewServer(proxy.Reverse(target))
+	defer front.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, resp, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(front.URL, "http"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer conn.Close()
+	fmt.Println("client:", resp.Status)
+	for _, msg := range []string{"hello", "world"} {
+		conn.WriteMessage(ws.OpText, []byte(msg))
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		fmt.Printf("client: %s\n", b)
+	}
+	conn.WriteClose(ws.CloseNormal, "")
+	conn.ReadMessage()
+	// Output:
+	// backend: GET HTTP/1.1, Upgrade: websocket, X-Forwarded-For: 127.0.0.1
+	// client: 101 Switching Protocols
+	// client: echo hello
+	// client: echo world
+}
+
+// Example_proxyConnect shows a forward proxy, the kind clients are configured with through http.Transport.Proxy or
+// $HTTP_PROXY and $HTTPS_PROXY. For an http:// URL the client sends the proxy the absolute URL in the request
+// line, and the proxy sees and forwards the whole request. For an https:// URL the client asks for a tunnel with
+// `CONNECT host:port`, then runs its TLS handshake with the origin through it: the proxy only sees the host, and
+// the client and the origin even negotiate HTTP/2 with ALPN past it.
+//
+// Corporate proxies that inspect HTTPS terminate TLS instead, with certificates of a CA installed on the machines.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,security requires=Example_proxyChain,Example_tlsALPN
+func Example_proxyConnect() {
+	origin := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Proto, cmp.Or(r.Header.Get("Via"), "without Via"))
+	}))
+	origin.EnableHTTP2 = true
+	origin.StartTLS()
+	defer origin.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Proto, cmp.Or(r.Header.Get("Via"), "without Via"))
+	}))
+	defer plain.Close()
+
+	forward := httptest.NewUnstartedServer(nil) // listening already, its address is known
+	names := hostNames(map[string]*httptest.Server{"origin": origin, "plain": plain, "proxy": forward})
+	logged := proxy.Log(replacingWriter{os.Stdout, names}, "proxy")
+	forward.Config.Handler = proxy.Chain(&proxy.Forward{}, logged, proxy.Via("proxy"))
+	forward.Start()
+	defer forward.Close()
+
+	proxyURL, _ := url.Parse(forward.URL)
+	transport := origin.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+	for _, target := range []string{plain.URL + "/page", origin.URL + "/page"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("client: %s, origin got %s\n", resp.Proto, b)
+	}
+	// Output:
+	// proxy: GET http://plain/page HTTP/1.1
+	// client: HTTP/1.1, origin got HTTP/1.1 1.1 proxy
+	// proxy: CONNECT origin HTTP/1.1
+	// client: HTTP/2.0, origin got HTTP/2.0 without Via
+}
+
+// replacingWriter replaces the addresses of test servers in what it writes, see hostNames.
+type replacingWriter struct {
+	w     io.Writer
+	names *strings.Replacer
+}
+
+func (rw replacingWriter) Write(p []byte) (int, error) {
+	if _, err := rw.names.WriteString(rw.w, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
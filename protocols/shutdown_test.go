@@ -0,0 +1,364 @@
+package protocols
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/juan-carvajal/go-dojo/protocols/h2c"
+	"github.com/juan-carvajal/go-dojo/protocols/h2trace"
+	"github.com/juan-carvajal/go-dojo/protocols/ws"
+)
+
+// blockingHandler answers "done" to requests for /slow once release is closed, after closing arrived, and "hello"
+// to the others.
+func blockingHandler(arrived, release chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(arrived)
+			<-release
+			io.WriteString(w, "done")
+			return
+		}
+		io.WriteString(w, "hello")
+	}
+}
+
+// getAsync sends a GET request in the background, and reports the response or the end of the error, without the
+// URL and addresses.
+func getAsync(client *http.Client, url string) <-chan string {
+	result := make(chan string, 1)
+	go func() {
+		resp, err := client.Get(url)
+		if err != nil {
+			msg := err.Error()
+			result <- "error: " + msg[strings.LastIndex(msg, ": ")+2:]
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		result <- fmt.Sprintf("%s %s %q, close=%t", resp.Proto, resp.Status, b, resp.Close)
+	}()
+	return result
+}
+
+// Example_shutdown shows http.Server.Shutdown draining a server: it closes the listener first, new connections are
+// refused, then closes connections as they become idle, and returns once none is left. The request in flight
+// completes, with `Connection: close` so that the client doesn't send another one on its connection. The hooks of
+// RegisterOnShutdown run when Shutdown starts, the listener already closed.
+//
+// Serve, ListenAndServe and the like return http.ErrServerClosed as soon as Shutdown is called: a main function
+// returning then would exit without waiting for the requests in flight. It has to wait for Shutdown to return, as
+// graceful.Serve does, see cmd/graceful for a server draining on SIGTERM.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,concurrency requires=Example_connReuseBody
+func Example_shutdown() {
+	arrived, release := make(chan struct{}), make(chan struct{})
+	testServer := httptest.NewServer(blockingHandler(arrived, release))
+	defer testServer.Close()
+	srv := testServer.Config
+	shuttingDown := make(chan struct{})
+	srv.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	slow := getAsync(testServer.Client(), testServer.URL+"/slow")
+	<-arrived
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+
+	<-shuttingDown
+	_, err := net.Dial("tcp", testServer.Listener.Addr().String())
+	fmt.Println("new connection refused:", errors.Is(err, syscall.ECONNREFUSED))
+	select {
+	case err := <-shutdown:
+		fmt.Println("Shutdown returned early:", err)
+	case <-time.After(100 * time.Millisecond):
+		fmt.Println("Shutdown waits for /slow")
+	}
+
+	close(release)
+	fmt.Println("in flight:", <-slow)
+	fmt.Println("Shutdown:", <-shutdown)
+	// Output:
+	// new connection refused: true
+	// Shutdown waits for /slow
+	// in flight: HTTP/1.1 200 OK "done", close=true
+	// Shutdown: <nil>
+}
+
+// Example_shutdownTimeout shows what Shutdown does to a handler that doesn't return: nothing. Shutdown waits for it
+// until its context is done, then returns the error of the context, the handler still running and its client still
+// waiting. The context of requests isn't canceled by Shutdown, only by Close, which closes every connection: the
+// client gets an error, the handler sees its context canceled, if it looks.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,concurrency,pitfall requires=Example_shutdown
+func Example_shutdownTimeout() {
+	handlerErr := make(chan error, 1)
+	arrived := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-r.Context().Done()
+		handlerErr <- r.Context().Err()
+	}))
+	defer testServer.Close()
+	srv := testServer.Config
+
+	stuck := getAsync(testServer.Client(), testServer.URL)
+	<-arrived
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	fmt.Println("Shutdown:", srv.Shutdown(ctx))
+	select {
+	case err := <-handlerErr:
+		fmt.Println("handler returned:", err)
+	default:
+		fmt.Println("handler still running")
+	}
+
+	srv.Close()
+	fmt.Println("handler:", <-handlerErr)
+	fmt.Println("client:", <-stuck)
+	// Output:
+	// Shutdown: context deadline exceeded
+	// handler still running
+	// handler: context canceled
+	// client: error: EOF
+}
+
+// Example_shutdownHijacked shows Shutdown ignoring hijacked connections, here a WebSocket: once hijacked, a
+// connection belongs to the handler, and the server forgets it. Shutdown returns right away, and the WebSocket keeps
+// working after it. Example_registerOnShutdown closes them.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,pitfall requires=Example_shutdown,Example_webSocket
+func Example_shutdownHijacked() {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ws.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(op, msg)
+		}
+	}))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, _, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(testServer.URL, "http"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	err = testServer.Config.Shutdown(ctx)
+	fmt.Println("Shutdown:", err, "in less than a second:", time.Since(start) < time.Second)
+
+	conn.WriteMessage(ws.OpText, []byte("still there?"))
+	_, msg, err := conn.ReadMessage()
+	fmt.Printf("after Shutdown: %q %v\n", msg, err)
+	conn.WriteClose(ws.CloseNormal, "")
+	// Output:
+	// Shutdown: <nil> in less than a second: true
+	// after Shutdown: "still there?" <nil>
+}
+
+// Example_registerOnShutdown closes the WebSockets of a server on shutdown: the hooks registered with
+// RegisterOnShutdown run when Shutdown starts, one goroutine each, here sending a close frame with status 1001, going
+// away, to every WebSocket. The handlers then end as their client acknowledges.
+//
+// Shutdown waits neither for the hooks nor for the handlers of hijacked connections: the server waits for them
+// itself, here with a WaitGroup.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,concurrency requires=Example_shutdownHijacked
+func Example_registerOnShutdown() {
+	var (
+		mu      sync.Mutex
+		sockets = map[*ws.Conn]bool{}
+		wg      sync.WaitGroup
+		closed  = make(chan error, 1)
+	)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ws.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		defer wg.Done()
+		mu.Lock()
+		sockets[conn] = true
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(sockets, conn)
+			mu.Unlock()
+			conn.Close()
+		}()
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			conn.WriteMessage(op, msg)
+		}
+	}))
+	defer testServer.Close()
+	srv := testServer.Config
+	srv.RegisterOnShutdown(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for conn := range sockets {
+			conn.WriteClose(ws.CloseGoingAway, "shutting down")
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, _, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(testServer.URL, "http"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer conn.Close()
+	conn.WriteMessage(ws.OpText, []byte("hello"))
+	conn.ReadMessage()
+
+	fmt.Println("Shutdown:", srv.Shutdown(ctx))
+	_, _, err = conn.ReadMessage()
+	fmt.Println("client:", err)
+	wg.Wait()
+	fmt.Println("server:", <-closed)
+	// Output:
+	// Shutdown: <nil>
+	// client: ws: closed with status 1001 "shutting down"
+	// server: ws: closed with status 1001 ""
+}
+
+type serverNameKey struct{}
+
+// Example_baseContext shows the context of requests deriving from http.Server.BaseContext: its values reach every
+// handler, and canceling it cancels every request in flight. Shutdown waits for a streaming handler that runs until
+// its client goes away, forever for some clients: canceling the base context when Shutdown starts, from a
+// RegisterOnShutdown hook, ends the stream. context.Cause tells the handler why.
+//
+// Canceling the base context cancels all the requests in flight, not only the streams, and Shutdown no longer
+// waits for them to complete. A server with both can cancel a context of its own, given to the streams only.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,concurrency requires=Example_shutdownTimeout,Example_serverSentEvents
+func Example_baseContext() {
+	errShutdown := errors.New("server shutting down")
+	base, cancel := context.WithCancelCause(context.WithValue(context.Background(), serverNameKey{}, "dojo"))
+	defer cancel(nil)
+	handlerDone := make(chan struct{})
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(handlerDone)
+		fmt.Fprintf(w, "streaming from %s\n", r.Context().Value(serverNameKey{}))
+		http.NewResponseController(w).Flush()
+		<-r.Context().Done()
+		fmt.Println("handler:", r.Context().Err(), "cause:", context.Cause(r.Context()))
+	}))
+	srv := testServer.Config
+	srv.BaseContext = func(net.Listener) context.Context { return base }
+	srv.RegisterOnShutdown(func() { cancel(errShutdown) })
+	testServer.Start()
+	defer testServer.Close()
+
+	resp, err := testServer.Client().Get(testServer.URL)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	line := make([]byte, len("streaming from dojo\n"))
+	io.ReadFull(resp.Body, line)
+	fmt.Printf("client: %q\n", line)
+
+	fmt.Println("Shutdown:", srv.Shutdown(context.Background()))
+	<-handlerDone
+	rest, err := io.ReadAll(resp.Body)
+	fmt.Printf("client: %q %v\n", rest, err)
+	// Output:
+	// client: "streaming from dojo\n"
+	// handler: context canceled cause: server shutting down
+	// Shutdown: <nil>
+	// client: "" <nil>
+}
+
+// Example_shutdownHTTP2 shows the shutdown of an HTTP/2 connection, where requests are streams of a connection the
+// server can't close after any of them. Shutdown makes the server send a GOAWAY frame with the last stream it will
+// process, here 3: the client sends no new request on the connection, and the streams up to that one complete
+// before the server closes it. GOAWAY replaces the `Connection: close` of HTTP/1.1, which HTTP/2 doesn't have. The
+// next request of the client needs a new connection, refused here.
+//
+// The GOAWAY may still be on its way when the client sends that request: it then goes on stream 5 of the connection,
+// which the server ignores, being above 3, and the client retries it on a new connection once it reads the GOAWAY.
+// The trace leaves out such streams, as they may or may not be there.
+//
+// The connection is cleartext HTTP/2, traced by h2trace.Listener: net/http serves it as it serves HTTP/2 over TLS,
+// while the servers h2trace.Attach makes don't hear of Shutdown.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http requires=Example_shutdown,Example_http2ConnectionPreface,Example_h2cPriorKnowledge
+func Example_shutdownHTTP2() {
+	arrived, release := make(chan struct{}), make(chan struct{})
+	trace := h2trace.New(nil)
+	testServer := httptest.NewUnstartedServer(blockingHandler(arrived, release))
+	testServer.Config.Protocols = h2c.Protocols(false)
+	testServer.Listener = trace.Listener(testServer.Listener)
+	testServer.Start()
+	defer testServer.Close()
+	client := h2c.Client()
+	goAway := func(f h2trace.Frame) bool { return f.Type == http2.FrameGoAway }
+
+	fmt.Println(<-getAsync(client, testServer.URL))
+	slow := getAsync(client, testServer.URL+"/slow")
+	<-arrived
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- testServer.Config.Shutdown(context.Background()) }()
+	for len(trace.Filter(goAway)) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fmt.Println(<-getAsync(client, testServer.URL))
+	close(release)
+	fmt.Println(<-slow)
+	fmt.Println("Shutdown:", <-shutdown)
+	var lastStream uint32
+	fmt.Sscanf(trace.Filter(goAway)[0].Details, "last_stream=%d", &lastStream)
+	for _, f := range trace.Filter(func(f h2trace.Frame) bool {
+		if f.Dir == h2trace.ClientToServer && f.Stream > lastStream {
+			return false // sent before the client read the GOAWAY, or not at all
+		}
+		return f.Type == http2.FrameHeaders || f.Type == http2.FrameData || goAway(f)
+	}) {
+		fmt.Println(strings.TrimSpace(frameSummary(f) + " " + f.Details))
+	}
+	// Output:
+	// HTTP/2.0 200 OK "hello", close=false
+	// error: connection refused
+	// HTTP/2.0 200 OK "done", close=false
+	// Shutdown: <nil>
+	// C→S PREFACE
+	// C→S HEADERS stream=1 :method=GET :path=/ :scheme=http END_STREAM
+	// S→C HEADERS stream=1 :status=200
+	// S→C DATA stream=1 END_STREAM
+	// C→S HEADERS stream=3 :method=GET :path=/slow :scheme=http END_STREAM
+	// S→C GOAWAY stream=0 last_stream=3 code=NO_ERROR
+	// S→C HEADERS stream=3 :status=200
+	// S→C DATA stream=3 END_STREAM
+}
@@ -0,0 +1,420 @@
+package protocols
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/protocols/memnet"
+	"github.com/juan-carvajal/go-dojo/protocols/proxy"
+)
+
+// echoRequest answers with what the server made of the request: method, path, body and the X-A header.
+func echoRequest(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	fmt.Fprintf(w, "%s %s body=%q", r.Method, r.URL.Path, b)
+	if err != nil {
+		fmt.Fprintf(w, " err=%q", err)
+	}
+	if v, ok := r.Header["X-A"]; ok {
+		fmt.Fprintf(w, " X-A=%q", v)
+	}
+}
+
+// rawCase is a request sent byte for byte, and what the server answers.
+type rawCase struct {
+	name   string
+	server func(*http.Server)
+	raw    string
+	want   []string // the responses in order, then "open" or "closed" for the connection
+}
+
+// run sends the bytes of c to a server answering with echoRequest, over an in-memory connection in a synctest
+// bubble: the server keeping the connection open shows as a read timeout, on the fake clock.
+func (c rawCase) run(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ln := memnet.Listen()
+		srv := &http.Server{Handler: http.HandlerFunc(echoRequest), ErrorLog: log.New(io.Discard, "", 0)}
+		if c.server != nil {
+			c.server(srv)
+		}
+		go srv.Serve(ln)
+		defer func() {
+			srv.Close()
+			time.Sleep(time.Second) // for connections closed after an error, which linger 500ms
+		}()
+
+		conn, err := ln.DialContext(t.Context(), "tcp", "memnet")
+		require.NoError(t, err)
+		defer conn.Close()
+		go io.WriteString(conn, c.raw) // pipes have no buffer, and the server may stop reading early
+
+		var got []string
+		br := bufio.NewReader(conn)
+		for {
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			resp, err := http.ReadResponse(br, nil)
+			if err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					got = append(got, "open")
+				} else {
+					got = append(got, "closed")
+				}
+				break
+			}
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				got = append(got, resp.Status+": "+string(b))
+			} else {
+				got = append(got, resp.Status)
+			}
+		}
+		require.Equal(t, c.want, got)
+	})
+}
+
+// Test_requestFraming shows how net/http finds where a request body ends, and so where the next request on the
+// connection starts. Request smuggling exploits a proxy and a backend disagreeing on it: the proxy sees one request,
+// the backend sees a second one hidden in the body of the first, see Example_requestSmuggling.
+//
+// Content-Length gives the length, Transfer-Encoding: chunked frames the body in chunks. net/http rejects the
+// ambiguous cases, duplicate Content-Length values that differ, a value that is not a plain number, a
+// Transfer-Encoding other than chunked alone or given twice, and closes the connection after those errors. Some
+// cases it accepts:
+//
+//   - Transfer-Encoding with Content-Length: the length is ignored, as RFC 9112 says. The RFC also says the server
+//     must then close the connection, net/http keeps it open and reads the next request: a proxy trusting the
+//     Content-Length would desync from it.
+//   - Identical duplicate Content-Length values, and `Chunked` in any case.
+//   - Transfer-Encoding in an HTTP/1.0 request is ignored, and the connection closed.
+//
+// Errors in the chunks themselves, e.g. a chunk longer than its size or a bare LF ending a line, come too late for
+// a status: the handler gets the error reading the body.
+//
+//dojo:meta difficulty=advanced minutes=20 tags=http,security,spec,testing requires=Example_chunkedEncoding
+func Test_requestFraming(t *testing.T) {
+	const post = "POST / HTTP/1.1\r\nHost: x\r\n"
+	for _, c := range []rawCase{
+		{
+			name: "Content-Length",
+			raw:  post + "Content-Length: 5\r\n\r\nhello",
+			want: []string{`200 OK: POST / body="hello"`, "open"},
+		},
+		{
+			name: "pipelined requests",
+			raw:  "GET /a HTTP/1.1\r\nHost: x\r\n\r\nGET /b HTTP/1.1\r\nHost: x\r\n\r\n",
+			want: []string{`200 OK: GET /a body=""`, `200 OK: GET /b body=""`, "open"},
+		},
+		{
+			name: "duplicate Content-Length, different",
+			raw:  post + "Content-Length: 5\r\nContent-Length: 6\r\n\r\nhello!",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "duplicate Content-Length, same",
+			raw:  post + "Content-Length: 5\r\nContent-Length: 5\r\n\r\nhello",
+			want: []string{`200 OK: POST / body="hello"`, "open"},
+		},
+		{
+			name: "Content-Length list",
+			raw:  post + "Content-Length: 5, 5\r\n\r\nhello",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "Content-Length with a sign",
+			raw:  post + "Content-Length: +5\r\n\r\nhello",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "chunked",
+			raw:  post + "Transfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{`200 OK: POST / body="hello"`, "open"},
+		},
+		{
+			name: "chunked, with an extension",
+			raw:  post + "Transfer-Encoding: chunked\r\n\r\n5;name=value\r\nhello\r\n0\r\n\r\n",
+			want: []string{`200 OK: POST / body="hello"`, "open"},
+		},
+		{
+			name: "Chunked",
+			raw:  post + "Transfer-Encoding: Chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{`200 OK: POST / body="hello"`, "open"},
+		},
+		{
+			name: "Transfer-Encoding and Content-Length",
+			raw: post + "Content-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n" +
+				"GET /smuggled HTTP/1.1\r\nHost: x\r\n\r\n",
+			want: []string{`200 OK: POST / body="hello"`, `200 OK: GET /smuggled body=""`, "open"},
+		},
+		{
+			name: "Transfer-Encoding twice",
+			raw:  post + "Transfer-Encoding: chunked\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{"501 Not Implemented", "closed"},
+		},
+		{
+			name: "Transfer-Encoding list",
+			raw:  post + "Transfer-Encoding: gzip, chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{"501 Not Implemented", "closed"},
+		},
+		{
+			name: "Transfer-Encoding unknown",
+			raw:  post + "Transfer-Encoding: xchunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{"501 Not Implemented", "closed"},
+		},
+		{
+			name: "Transfer-Encoding in HTTP/1.0",
+			raw:  "POST / HTTP/1.0\r\nHost: x\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{`200 OK: POST / body=""`, "closed"},
+		},
+		{
+			name: "chunk longer than its size",
+			raw:  post + "Transfer-Encoding: chunked\r\n\r\n5\r\nhelloXX\r\n0\r\n\r\n",
+			want: []string{`200 OK: POST / body="hello" err="malformed chunked encoding"`, "closed"},
+		},
+		{
+			name: "chunk lines ending with bare LF",
+			raw:  post + "Transfer-Encoding: chunked\r\n\r\n5\nhello\n0\n\n",
+			want: []string{`200 OK: POST / body="" err="chunked line ends with bare LF"`, "closed"},
+		},
+	} {
+		t.Run(c.name, c.run)
+	}
+}
+
+// Test_headerParsing shows how net/http parses request headers, and what it rejects: a header name with a space,
+// including before the colon, where a lenient parser could read `Transfer-Encoding : chunked` as
+// Transfer-Encoding while net/http refuses it; control characters in values; a missing or duplicate Host; header
+// lines starting with a space; headers larger than Server.MaxHeaderBytes, 1 MiB by default, plus 4 KiB of slack.
+//
+// It accepts two obsolete forms that RFC 9112 lets servers reject: lines folded on the next line starting with a
+// space, joined with a space, and lines ending with a bare LF instead of CRLF. A proxy seeing them differently could
+// disagree with the backend on the headers of a request.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,security,spec,testing requires=Test_requestFraming
+func Test_headerParsing(t *testing.T) {
+	const get = "GET / HTTP/1.1\r\nHost: x\r\n"
+	for _, c := range []rawCase{
+		{
+			name: "obsolete line folding",
+			raw:  get + "X-A: 1\r\n 2\r\n\r\n",
+			want: []string{`200 OK: GET / body="" X-A=["1 2"]`, "open"},
+		},
+		{
+			name: "bare LF",
+			raw:  "GET / HTTP/1.1\nHost: x\nX-A: 1\n\n",
+			want: []string{`200 OK: GET / body="" X-A=["1"]`, "open"},
+		},
+		{
+			name: "bare CR",
+			raw:  get + "X-A: 1\r2\r\n\r\n",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "NUL in a value",
+			raw:  get + "X-A: a\x00b\r\n\r\n",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "space in a name",
+			raw:  get + "X A: 1\r\n\r\n",
+			want: []string{"400 Bad Request: invalid header name", "closed"},
+		},
+		{
+			name: "space before the colon",
+			raw:  "POST / HTTP/1.1\r\nHost: x\r\nTransfer-Encoding : chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+			want: []string{"400 Bad Request: invalid header name", "closed"},
+		},
+		{
+			name: "space before the first header",
+			raw:  "GET / HTTP/1.1\r\n Host: x\r\n\r\n",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name: "missing Host",
+			raw:  "GET / HTTP/1.1\r\n\r\n",
+			want: []string{"400 Bad Request: missing required Host header", "closed"},
+		},
+		{
+			name: "duplicate Host",
+			raw:  get + "Host: y\r\n\r\n",
+			want: []string{"400 Bad Request", "closed"},
+		},
+		{
+			name:   "headers over MaxHeaderBytes",
+			server: func(s *http.Server) { s.MaxHeaderBytes = 1024 },
+			raw:    get + "X-A: " + strings.Repeat("a", 1024+4096) + "\r\n\r\n",
+			want:   []string{"431 Request Header Fields Too Large", "closed"},
+		},
+		{
+			name:   "headers within the slack of MaxHeaderBytes",
+			server: func(s *http.Server) { s.MaxHeaderBytes = 1024 },
+			raw:    get + "X-A: " + strings.Repeat("a", 2048) + "\r\n\r\n",
+			want:   []string{`200 OK: GET / body="" X-A=["` + strings.Repeat("a", 2048) + `"]`, "open"},
+		},
+	} {
+		t.Run(c.name, c.run)
+	}
+}
+
+// naiveProxy is a front proxy framing requests with Content-Length alone, as some old or hand-written proxies did:
+// it ignores Transfer-Encoding and forwards the bytes of each request as they came, on a single connection to the
+// backend shared by all clients, then relays one response.
+type naiveProxy struct {
+	mu      sync.Mutex
+	backend net.Conn
+	br      *bufio.Reader
+}
+
+func (p *naiveProxy) serve(client net.Conn) {
+	defer client.Close()
+	tp := textproto.NewReader(bufio.NewReader(client))
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		header, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, n)
+		if _, err := io.ReadFull(tp.R, body); err != nil {
+			return
+		}
+
+		var req strings.Builder
+		req.WriteString(line + "\r\n")
+		for k, vs := range header {
+			for _, v := range vs {
+				req.WriteString(k + ": " + v + "\r\n")
+			}
+		}
+		req.WriteString("\r\n")
+		req.Write(body)
+
+		p.mu.Lock()
+		io.WriteString(p.backend, req.String())
+		resp, err := http.ReadResponse(p.br, nil)
+		if err == nil {
+			resp.Write(client)
+		}
+		p.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Example_requestSmuggling shows a CL.TE request smuggling attack: the front proxy frames requests with
+// Content-Length, the backend with Transfer-Encoding. The attacker sends both headers, with a body that is a
+// complete chunked body, `0\r\n\r\n`, followed by the start of another request:
+//
+//	POST / HTTP/1.1
+//	Content-Length: 42
+//	Transfer-Encoding: chunked
+//
+//	0
+//
+//	GET /admin HTTP/1.1
+//	X-Ignore:␣
+//
+// The proxy forwards the 42 bytes as the body of the POST. The backend, a net/http server, reads the chunked body
+// up to `0\r\n\r\n`, answers the POST, and waits for the rest of the next request, `GET /admin` with a header left
+// open. The next request the proxy forwards on that connection, from a victim, completes it: its request line
+// becomes the value of X-Ignore, and the victim gets the answer to GET /admin, with its own cookies if it had any.
+//
+// net/http as a front proxy, proxy.Reverse here, isn't fooled: it parses each request, chunked wins over
+// Content-Length, and sends a new request to the backend with its own framing. The leftovers stay on the connection
+// of the attacker. Request smuggling takes two parsers that disagree, Test_requestFraming shows how net/http parses.
+//
+//dojo:meta difficulty=advanced minutes=20 tags=http,security,pitfall requires=Test_requestFraming,Example_proxyChain
+func Example_requestSmuggling() {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+	}))
+	defer backend.Close()
+
+	smuggled := "GET /admin HTTP/1.1\r\nX-Ignore: "
+	attack := fmt.Sprintf("POST / HTTP/1.1\r\nHost: x\r\nContent-Length: %d\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n%s",
+		len("0\r\n\r\n"+smuggled), smuggled)
+	victim := "GET /account HTTP/1.1\r\nHost: x\r\n\r\n"
+
+	// The naive proxy, on its own listener.
+	naiveLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer naiveLn.Close()
+	backendConn, err := net.Dial("tcp", backend.Listener.Addr().String())
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer backendConn.Close()
+	naive := &naiveProxy{backend: backendConn, br: bufio.NewReader(backendConn)}
+	go func() {
+		for {
+			conn, err := naiveLn.Accept()
+			if err != nil {
+				return
+			}
+			go naive.serve(conn)
+		}
+	}()
+
+	target, _ := url.Parse(backend.URL)
+	reverse := httptest.NewServer(proxy.Reverse(target))
+	defer reverse.Close()
+
+	for _, front := range []struct {
+		name string
+		addr string
+	}{
+		{"naive proxy", naiveLn.Addr().String()},
+		{"proxy.Reverse", reverse.Listener.Addr().String()},
+	} {
+		fmt.Println(front.name + ":")
+		for _, c := range []struct{ who, raw string }{{"attacker", attack}, {"victim", victim}} {
+			conn, err := net.Dial("tcp", front.addr)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			io.WriteString(conn, c.raw)
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				fmt.Println("Error:", err)
+				conn.Close()
+				return
+			}
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			conn.Close()
+			fmt.Printf("  %s sent %s, got %q\n", c.who, strings.Fields(c.raw)[1], b)
+		}
+	}
+	// Output:
+	// naive proxy:
+	//   attacker sent /, got "POST /"
+	//   victim sent /account, got "GET /admin"
+	// proxy.Reverse:
+	//   attacker sent /, got "POST /"
+	//   victim sent /account, got "GET /account"
+}
@@ -0,0 +1,194 @@
+// Package sse implements Server-Sent Events, the text/event-stream format of the HTML standard: a server keeps a
+// response open and writes events to it, separated by blank lines, and the client reconnects when the stream ends,
+// telling the server the ID of the last event it got in a Last-Event-ID header.
+//
+//	id: 1
+//	event: price
+//	data: {"sym":"GOOG","px":1}
+//
+// Browsers implement the client side as EventSource, [Client] is the same for Go.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of event streams.
+const ContentType = "text/event-stream"
+
+// DefaultRetry is the delay before reconnecting until the server sets one with a retry field, as in browsers.
+const DefaultRetry = 3 * time.Second
+
+// Event is a server-sent event.
+type Event struct {
+	ID    string        // last event ID, kept by the client across events until the server sends another one
+	Type  string        // event type, "message" when empty
+	Data  string        // data, lines joined with \n
+	Retry time.Duration // reconnection delay, written when positive; read by Reader.Retry, even without data
+}
+
+// WriteTo writes e to w in the event stream format: one data line per line of e.Data, then a blank line.
+func (e Event) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Type != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Type)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for line := range strings.SplitSeq(e.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteByte('\n')
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Reader reads events from an event stream.
+type Reader struct {
+	br     *bufio.Reader
+	lastID string
+	retry  time.Duration
+}
+
+// NewReader returns a Reader reading events from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// Next returns the next event. Comments, lines starting with a colon, and blocks without data are skipped, as is an
+// event cut by the end of the stream: Next then returns io.EOF.
+func (r *Reader) Next() (Event, error) {
+	var (
+		e    Event
+		data strings.Builder
+	)
+	for {
+		line, err := r.br.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if data.Len() == 0 {
+				e = Event{}
+				continue
+			}
+			e.ID = r.lastID
+			e.Data = strings.TrimSuffix(data.String(), "\n")
+			return e, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			e.Type = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				r.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// Retry returns the reconnection delay last set by the server with a retry field, zero if none.
+func (r *Reader) Retry() time.Duration {
+	return r.retry
+}
+
+// Client receives the events of an event stream, reconnecting like EventSource when the stream ends or fails.
+type Client struct {
+	HTTP        *http.Client  // http.DefaultClient when nil
+	URL         string        // URL of the stream
+	LastEventID string        // sent in the Last-Event-ID header of reconnections, updated as events arrive
+	Retry       time.Duration // delay before reconnecting, DefaultRetry when zero, updated by retry fields
+}
+
+// Subscribe calls handle with each event until handle returns false, ctx is done or the server answers
+// 204 No Content, its way of telling the client to stop reconnecting. Other statuses than 200 and other content
+// types than text/event-stream are fatal.
+func (c *Client) Subscribe(ctx context.Context, handle func(Event) bool) error {
+	if c.Retry == 0 {
+		c.Retry = DefaultRetry
+	}
+	for {
+		done, err := c.stream(ctx, handle)
+		if done || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Retry):
+		}
+	}
+}
+
+// stream reads a single connection, it reports whether to stop reconnecting.
+func (c *Client) stream(ctx context.Context, handle func(Event) bool) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Accept", ContentType)
+	req.Header.Set("Cache-Control", "no-cache")
+	if c.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.LastEventID)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() != nil, ctx.Err()
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return true, nil
+	default:
+		return true, fmt.Errorf("sse: unexpected status %s", resp.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != ContentType {
+		return true, fmt.Errorf("sse: unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	r := NewReader(resp.Body)
+	for {
+		e, err := r.Next()
+		if r.Retry() > 0 {
+			c.Retry = r.Retry()
+		}
+		if err != nil {
+			// The stream ended or broke: reconnect, unless canceled.
+			return ctx.Err() != nil, ctx.Err()
+		}
+		c.LastEventID = e.ID
+		if !handle(e) {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Event{ID: "7", Type: "price", Data: "a\nb", Retry: 1500 * time.Millisecond}.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "id: 7\nevent: price\nretry: 1500\ndata: a\ndata: b\n\n", buf.String())
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader(": comment\n" +
+		"retry: 10\n\n" +
+		"id: 1\r\ndata: first\r\ndata:second\r\n\r\n" +
+		"event: tick\ndata\n\n" +
+		"id\ndata: no id\n\n" +
+		"data: cut"))
+	var got []Event
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, e)
+	}
+	require.Equal(t, []Event{
+		{ID: "1", Data: "first\nsecond"},
+		{ID: "1", Type: "tick", Data: ""},
+		{ID: "", Data: "no id"},
+	}, got)
+	require.Equal(t, 10*time.Millisecond, r.Retry())
+}
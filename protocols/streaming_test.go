@@ -0,0 +1,388 @@
+package protocols
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/protocols/sse"
+	"github.com/juan-carvajal/go-dojo/protocols/ws"
+)
+
+// rawGet sends a GET request for path to addr over a raw TCP connection and returns the response as it came on the
+// wire, split between the head and the body.
+func rawGet(addr, path string) (head, body string, err error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return "", "", err
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nConnection: close\r\n\r\n", path, addr)
+	b, err := io.ReadAll(conn)
+	if err != nil {
+		return "", "", err
+	}
+	head, body, _ = strings.Cut(string(b), "\r\n\r\n")
+	return head, body, nil
+}
+
+// Example_chunkedEncoding shows how an HTTP/1.1 response announces its length. A handler that writes little and
+// returns gets a Content-Length: net/http buffers the first 2 KiB of the body, and knows the whole body when the
+// handler returns. A handler that flushes, or writes more, sends the headers before knowing the length: the body
+// goes out with `Transfer-Encoding: chunked`, each chunk prefixed with its size in hexadecimal, and a chunk of size 0
+// ends it.
+//
+// Chunks are a framing of the wire, not messages: proxies may merge or split them, and the Go client decodes them
+// transparently, with resp.ContentLength -1 and resp.TransferEncoding ["chunked"]. HTTP/2 and HTTP/3 have no chunked
+// encoding, their DATA frames carry the body.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http requires=Example_http1_1
+func Example_chunkedEncoding() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/buffered", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello, ")
+		io.WriteString(w, "world")
+	})
+	mux.HandleFunc("/flushed", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello, ")
+		http.NewResponseController(w).Flush()
+		io.WriteString(w, "world")
+	})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	for _, path := range []string{"/buffered", "/flushed"} {
+		head, body, err := rawGet(testServer.Listener.Addr().String(), path)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		fmt.Println(path)
+		for line := range strings.SplitSeq(head, "\r\n") {
+			if strings.HasPrefix(line, "Content-Length") || strings.HasPrefix(line, "Transfer-Encoding") {
+				fmt.Println(" ", line)
+			}
+		}
+		fmt.Printf("  body %q\n", body)
+	}
+	// Output:
+	// /buffered
+	//   Content-Length: 12
+	//   body "hello, world"
+	// /flushed
+	//   Transfer-Encoding: chunked
+	//   body "7\r\nhello, \r\n5\r\nworld\r\n0\r\n\r\n"
+}
+
+// Example_responseController shows a pitfall of streaming handlers behind middlewares. The ResponseWriter of
+// net/http implements http.Flusher, but a middleware wrapping it, e.g. to record the status code, hides it: the type
+// assertion w.(http.Flusher) fails, and the response is buffered.
+//
+// http.ResponseController, since Go 1.20, calls Flush, Hijack, SetReadDeadline, SetWriteDeadline and
+// EnableFullDuplex through such wrappers, as long as they have an Unwrap method returning the ResponseWriter they
+// wrap. Otherwise it returns an error matching http.ErrNotSupported rather than failing silently.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,pitfall requires=Example_chunkedEncoding
+func Example_responseController() {
+	report := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		err := http.NewResponseController(w).Flush()
+		fmt.Fprintf(w, "http.Flusher %t, ResponseController.Flush: %v", ok, err)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/plain", report)
+	mux.Handle("/wrapped", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report.ServeHTTP(&statusRecorder{ResponseWriter: w}, r)
+	}))
+	mux.Handle("/unwrapped", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report.ServeHTTP(&unwrappingStatusRecorder{statusRecorder{ResponseWriter: w}}, r)
+	}))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	for _, path := range []string{"/plain", "/wrapped", "/unwrapped"} {
+		resp, err := http.Get(testServer.URL + path)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("%s: %s\n", path, b)
+	}
+	// Output:
+	// /plain: http.Flusher true, ResponseController.Flush: <nil>
+	// /wrapped: http.Flusher false, ResponseController.Flush: feature not supported
+	// /unwrapped: http.Flusher false, ResponseController.Flush: <nil>
+}
+
+// statusRecorder is a typical middleware ResponseWriter, recording the status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// unwrappingStatusRecorder lets http.ResponseController reach the ResponseWriter it wraps.
+type unwrappingStatusRecorder struct {
+	statusRecorder
+}
+
+func (w *unwrappingStatusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Example_serverSentEvents shows Server-Sent Events: the server keeps the response open, with the text/event-stream
+// content type, and writes events separated by blank lines, flushing each one. Here it ends the stream after two
+// events, as a server restarting or a proxy timing out would.
+//
+// The client, sse.Client here, EventSource in browsers, reconnects after the delay set by the `retry` field and
+// sends the ID of the last event it got in a Last-Event-ID header, so that the server resumes where it left off.
+// The server stops the reconnections with a 204 No Content.
+//
+//dojo:meta difficulty=intermediate minutes=15 tags=http requires=Example_responseController
+func Example_serverSentEvents() {
+	events := []string{"a", "b", "c", "d"}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last := r.Header.Get("Last-Event-ID")
+		fmt.Printf("server: connection with Last-Event-ID %q\n", last)
+		start, _ := strconv.Atoi(last)
+		if start == len(events) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", sse.ContentType)
+		rc := http.NewResponseController(w)
+		io.WriteString(w, "retry: 10\n\n") // reconnect after 10ms
+		for i := start; i < min(start+2, len(events)); i++ {
+			sse.Event{ID: strconv.Itoa(i + 1), Data: events[i]}.WriteTo(w)
+			rc.Flush()
+		}
+	}))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client := &sse.Client{URL: testServer.URL}
+	err := client.Subscribe(ctx, func(e sse.Event) bool {
+		fmt.Printf("client: event %s %q\n", e.ID, e.Data)
+		return true
+	})
+	fmt.Println("client: done", err)
+	// Output:
+	// server: connection with Last-Event-ID ""
+	// client: event 1 "a"
+	// client: event 2 "b"
+	// server: connection with Last-Event-ID "2"
+	// client: event 3 "c"
+	// client: event 4 "d"
+	// server: connection with Last-Event-ID "4"
+	// client: done <nil>
+}
+
+// echoLines answers each line of the request body with the line in upper case, flushed right away.
+func echoLines(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	if r.URL.Query().Has("duplex") {
+		rc.EnableFullDuplex()
+	}
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+	sc := bufio.NewScanner(r.Body)
+	for sc.Scan() {
+		fmt.Fprintln(w, strings.ToUpper(sc.Text()))
+		rc.Flush()
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintln(w, "error:", err)
+	}
+}
+
+// lockstep sends words one at a time in the body of a request to url, reading the answer to each before sending
+// the next one.
+func lockstep(client *http.Client, url string, words ...string) (string, error) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	resp, err := client.Post(url, "text/plain", pr)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	var out []string
+	for _, word := range words {
+		fmt.Fprintln(pw, word)
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		out = append(out, word+" -> "+strings.TrimSpace(line))
+	}
+	return resp.Proto + ": " + strings.Join(out, ", "), nil
+}
+
+// Example_fullDuplex shows a request and its response streaming at the same time: the client sends a word, reads
+// the answer of the server, sends the next word, on a single request. gRPC bidirectional streams work this way.
+//
+// HTTP/2 streams are full duplex. HTTP/1.1 servers of net/http are not by default: the first write of the response
+// reads whatever is left of the request body, so that the client isn't stuck writing a body nobody reads, and the
+// handler can't read it anymore. A client in lock step would wait forever, here the client sends its whole body at
+// once instead, to show the error the handler gets. http.ResponseController.EnableFullDuplex, since Go 1.21, turns
+// this off; some HTTP/1 clients and proxies don't support it anyway.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,pitfall requires=Example_responseController,Example_http2_TLS
+func Example_fullDuplex() {
+	h2Server := httptest.NewUnstartedServer(http.HandlerFunc(echoLines))
+	h2Server.EnableHTTP2 = true
+	h2Server.StartTLS()
+	defer h2Server.Close()
+	h1Server := httptest.NewServer(http.HandlerFunc(echoLines))
+	defer h1Server.Close()
+
+	for _, target := range []struct {
+		client *http.Client
+		url    string
+	}{
+		{h2Server.Client(), h2Server.URL},
+		{h1Server.Client(), h1Server.URL + "?duplex"},
+	} {
+		out, err := lockstep(target.client, target.url, "ping", "pong")
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		fmt.Println(out)
+	}
+
+	resp, err := h1Server.Client().Post(h1Server.URL, "text/plain", strings.NewReader("ping\npong\n"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("HTTP/1.1 without EnableFullDuplex: %s", b)
+	// Output:
+	// HTTP/2.0: ping -> PING, pong -> PONG
+	// HTTP/1.1: ping -> PING, pong -> PONG
+	// HTTP/1.1 without EnableFullDuplex: error: http: invalid Read on closed Body
+}
+
+// Example_webSocketFrames shows WebSocket frames byte by byte. The first byte holds the FIN bit, set on the last
+// frame of a message, and the opcode: 0x1 text, 0x2 binary, 0x8 close, 0x9 ping, 0xa pong. The second byte holds the
+// MASK bit and the payload length, or 126 and 127 for lengths on the next 2 or 8 bytes.
+//
+// Frames from clients are masked: a random 4 bytes key follows the length, and the payload is XORed with it. The
+// masked "Hello" and the Sec-WebSocket-Accept below are the examples of RFC 6455.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http,spec requires=Example_http1_1
+func Example_webSocketFrames() {
+	for _, c := range []struct {
+		label string
+		frame ws.Frame
+	}{
+		{"server text", ws.Frame{Fin: true, Opcode: ws.OpText, Payload: []byte("Hello")}},
+		{"client text", ws.Frame{Fin: true, Opcode: ws.OpText, Payload: []byte("Hello"),
+			Masked: true, Mask: [4]byte{0x37, 0xfa, 0x21, 0x3d}}},
+		{"first fragment", ws.Frame{Opcode: ws.OpText, Payload: []byte("Hel")}},
+		{"last fragment", ws.Frame{Fin: true, Opcode: ws.OpContinuation, Payload: []byte("lo")}},
+		{"close 1000", ws.Frame{Fin: true, Opcode: ws.OpClose, Payload: []byte{0x03, 0xe8}}},
+	} {
+		var buf bytes.Buffer
+		if err := ws.WriteFrame(&buf, c.frame); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		fmt.Printf("%-14s % x\n", c.label, buf.Bytes())
+	}
+
+	var buf bytes.Buffer
+	ws.WriteFrame(&buf, ws.Frame{Fin: true, Opcode: ws.OpBinary, Payload: make([]byte, 300)})
+	fmt.Printf("%-14s % x ...\n", "300 bytes", buf.Bytes()[:4])
+	fmt.Println("Sec-WebSocket-Accept for dGhlIHNhbXBsZSBub25jZQ==:", ws.AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+	// Output:
+	// server text    81 05 48 65 6c 6c 6f
+	// client text    81 85 37 fa 21 3d 7f 9f 4d 51 58
+	// first fragment 01 03 48 65 6c
+	// last fragment  80 02 6c 6f
+	// close 1000     88 02 03 e8
+	// 300 bytes      82 7e 01 2c ...
+	// Sec-WebSocket-Accept for dGhlIHNhbXBsZSBub25jZQ==: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=
+}
+
+// Example_webSocket shows a WebSocket connection from the handshake to the close. The client sends an HTTP/1.1 GET
+// with `Upgrade: websocket`, the server answers `101 Switching Protocols` and takes the connection over from
+// net/http with Hijack. From then on both sides send messages whenever they want.
+//
+// Pings are answered by pongs with the same payload, to check the peer is alive and keep idle connections open
+// through proxies and NATs. The connection ends with a closing handshake: one side sends a close frame with a
+// status code, the other answers with its own, then the TCP connection is closed. A connection closed without it
+// is an abnormal closure, 1006 in browsers.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http requires=Example_webSocketFrames
+func Example_webSocket() {
+	closed := make(chan error, 1)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ws.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			conn.WriteMessage(op, msg)
+		}
+	}))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, resp, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(testServer.URL, "http"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer conn.Close()
+	fmt.Printf("%s %s, Upgrade: %s\n", resp.Proto, resp.Status, resp.Header.Get("Upgrade"))
+
+	conn.OnPong = func(payload []byte) { fmt.Printf("client: pong %q\n", payload) }
+	conn.Ping([]byte("still there?"))
+	conn.WriteMessage(ws.OpText, []byte("hello"))
+	op, msg, err := conn.ReadMessage()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("client: %s message %q\n", op, msg)
+
+	conn.WriteClose(ws.CloseNormal, "bye")
+	_, _, err = conn.ReadMessage()
+	var closeErr *ws.CloseError
+	if errors.As(err, &closeErr) {
+		fmt.Println("server:", <-closed)
+		fmt.Println("client:", err)
+	}
+	// Output:
+	// HTTP/1.1 101 Switching Protocols, Upgrade: websocket
+	// client: pong "still there?"
+	// client: text message "hello"
+	// server: ws: closed with status 1000 "bye"
+	// client: ws: closed with status 1000 ""
+}
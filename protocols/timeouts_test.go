@@ -0,0 +1,385 @@
+package protocols
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/juan-carvajal/go-dojo/protocols/memnet"
+)
+
+// timeoutCase is a row of a timeout matrix: a server and a client configured with a timeout of a second, a handler
+// slower than that, what each side sees and when the client gives up, on the fake clock of a synctest bubble.
+type timeoutCase struct {
+	name      string
+	server    func(*http.Server)
+	transport func(*http.Transport, *memnet.Listener)
+	handler   func(w http.ResponseWriter, r *http.Request) string // returns what the server side saw
+	listen    func(*memnet.Listener)                              // replaces the HTTP server, e.g. to never accept
+	call      func(*http.Client, *memnet.Listener) error          // GET http://memnet/ and read the body by default
+
+	client   string        // error of the client
+	deadline bool          // errors.Is(err, context.DeadlineExceeded)
+	timeout  bool          // the error is a net.Error whose Timeout method returns true
+	seen     string        // what the handler saw, empty when it didn't run
+	after    time.Duration // when the client got its error
+}
+
+// run runs c in a synctest bubble, over an in-memory network.
+func (c timeoutCase) run(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ln := memnet.Listen()
+		defer ln.Close()
+		seen := make(chan string, 1)
+		if c.listen != nil {
+			go c.listen(ln)
+		} else {
+			srv := &http.Server{
+				Handler:  http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { seen <- c.handler(w, r) }),
+				ErrorLog: log.New(io.Discard, "", 0),
+			}
+			if c.server != nil {
+				c.server(srv)
+			}
+			go srv.Serve(ln)
+			defer func() {
+				srv.Close()
+				time.Sleep(time.Second) // for connections closed after an error, which linger 500ms
+			}()
+		}
+		tr := &http.Transport{DialContext: ln.DialContext}
+		if c.transport != nil {
+			c.transport(tr, ln)
+		}
+		defer tr.CloseIdleConnections()
+		call := c.call
+		if call == nil {
+			call = getBody
+		}
+
+		start := time.Now()
+		err := call(&http.Client{Transport: tr}, ln)
+		after := time.Since(start)
+
+		var netErr net.Error
+		require.EqualError(t, err, c.client)
+		require.Equal(t, c.deadline, errors.Is(err, context.DeadlineExceeded), "errors.Is(err, context.DeadlineExceeded)")
+		require.Equal(t, c.timeout, errors.As(err, &netErr) && netErr.Timeout(), "net.Error.Timeout()")
+		require.Equal(t, c.after, after)
+		select {
+		case s := <-seen:
+			require.Equal(t, c.seen, s)
+		case <-time.After(time.Minute): // longer than any handler
+			require.Empty(t, c.seen, "the handler did not run")
+		}
+	})
+}
+
+// getBody gets http://memnet/ and reads the response body.
+func getBody(client *http.Client, _ *memnet.Listener) error {
+	resp, err := client.Get("http://memnet/")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	return err
+}
+
+// slowHandler takes 10 seconds to answer, unless the request is canceled before, and returns the error of its
+// context.
+func slowHandler(w http.ResponseWriter, r *http.Request) string {
+	select {
+	case <-time.After(10 * time.Second):
+		return "done"
+	case <-r.Context().Done():
+		return "ctx: " + r.Context().Err().Error()
+	}
+}
+
+// Test_httpClientTimeouts shows the timeouts of net/http clients, and what each side sees when they fire. The
+// handler takes 10 seconds, the timeouts are a second:
+//
+//   - Client.Timeout covers everything, from the dial to the last byte of the body.
+//   - The context of the request does the same, canceled or with a deadline, and lets each call have its own.
+//   - Transport.ResponseHeaderTimeout only waits for the response headers, a slow body is fine.
+//   - Timeouts of the dial, here a dialer with a deadline like net.Dialer.Timeout on a listener that never
+//     accepts, and Transport.TLSHandshakeTimeout guard the connection set-up.
+//
+// Most of them match errors.Is(err, context.DeadlineExceeded) and all of them are a net.Error whose Timeout method
+// returns true, a cancellation is neither. On the other side, the handler doesn't get a timeout: its request context
+// is canceled when the client closes the connection.
+//
+// The test runs in a testing/synctest bubble over the in-memory network of memnet: the clock is fake, the seconds
+// pass instantly and every duration is exact.
+//
+//dojo:meta difficulty=advanced minutes=20 tags=http,testing
+//dojo:meta requires=concurrency.Test_makingBlockingCallsWithCancelledContext,Example_http1_1
+func Test_httpClientTimeouts(t *testing.T) {
+	for _, c := range []timeoutCase{
+		{
+			name:    "Client.Timeout",
+			handler: slowHandler,
+			call: func(client *http.Client, ln *memnet.Listener) error {
+				client.Timeout = time.Second
+				return getBody(client, ln)
+			},
+			client:   `Get "http://memnet/": context deadline exceeded`,
+			deadline: true,
+			timeout:  true,
+			seen:     "ctx: context canceled",
+			after:    time.Second,
+		},
+		{
+			name: "Client.Timeout, slow body",
+			handler: func(w http.ResponseWriter, r *http.Request) string {
+				w.WriteHeader(http.StatusOK)
+				http.NewResponseController(w).Flush()
+				return slowHandler(w, r)
+			},
+			call: func(client *http.Client, ln *memnet.Listener) error {
+				client.Timeout = time.Second
+				return getBody(client, ln)
+			},
+			client:   "context deadline exceeded",
+			deadline: true,
+			timeout:  true,
+			seen:     "ctx: context canceled",
+			after:    time.Second,
+		},
+		{
+			name:    "request context with a deadline",
+			handler: slowHandler,
+			call: func(client *http.Client, ln *memnet.Listener) error {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://memnet/", nil)
+				_, err := client.Do(req)
+				return err
+			},
+			client:   `Get "http://memnet/": context deadline exceeded`,
+			deadline: true,
+			timeout:  true,
+			seen:     "ctx: context canceled",
+			after:    time.Second,
+		},
+		{
+			name:    "request context canceled",
+			handler: slowHandler,
+			call: func(client *http.Client, ln *memnet.Listener) error {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				time.AfterFunc(time.Second, cancel)
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://memnet/", nil)
+				_, err := client.Do(req)
+				return err
+			},
+			client: `Get "http://memnet/": context canceled`,
+			seen:   "ctx: context canceled",
+			after:  time.Second,
+		},
+		{
+			name:      "Transport.ResponseHeaderTimeout",
+			handler:   slowHandler,
+			transport: func(tr *http.Transport, _ *memnet.Listener) { tr.ResponseHeaderTimeout = time.Second },
+			client:    `Get "http://memnet/": net/http: timeout awaiting response headers`,
+			deadline:  true,
+			timeout:   true,
+			seen:      "ctx: context canceled",
+			after:     time.Second,
+		},
+		{
+			name:   "dial timeout",
+			listen: func(*memnet.Listener) {}, // nobody accepts
+			transport: func(tr *http.Transport, ln *memnet.Listener) {
+				tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+					ctx, cancel := context.WithTimeout(ctx, time.Second)
+					defer cancel()
+					return ln.DialContext(ctx, network, addr)
+				}
+			},
+			client:   `Get "http://memnet/": dial memnet: context deadline exceeded`,
+			deadline: true,
+			timeout:  true,
+			after:    time.Second,
+		},
+		{
+			name: "Transport.TLSHandshakeTimeout",
+			listen: func(ln *memnet.Listener) { // accepts, and never answers the ClientHello
+				conn, err := ln.Accept()
+				if err == nil {
+					io.Copy(io.Discard, conn)
+				}
+			},
+			transport: func(tr *http.Transport, _ *memnet.Listener) {
+				tr.TLSHandshakeTimeout = time.Second
+				tr.TLSClientConfig = &tls.Config{}
+			},
+			call: func(client *http.Client, _ *memnet.Listener) error {
+				_, err := client.Get("https://memnet/")
+				return err
+			},
+			client:  `Get "https://memnet/": net/http: TLS handshake timeout`,
+			timeout: true,
+			after:   time.Second,
+		},
+	} {
+		t.Run(c.name, c.run)
+	}
+}
+
+// rawRequest sends the bytes of a request, complete or not, and reads the response.
+func rawRequest(ln *memnet.Listener, request string) error {
+	conn, err := ln.DialContext(context.Background(), "tcp", "memnet")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, request); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return errors.New(resp.Status)
+}
+
+// Test_httpServerTimeouts shows the timeouts of net/http servers, and what each side sees when they fire. They
+// protect the server from slow or malicious clients, e.g. Slowloris sending its headers a byte at a time to hold
+// connections open:
+//
+//   - ReadHeaderTimeout bounds the time to read the request headers. The connection is closed without a response.
+//   - ReadTimeout bounds the time to read the whole request, body included: a large upload over a slow network hits
+//     it too. Reading the body then fails with a timeout, in the handler.
+//   - WriteTimeout bounds the time from the end of the request headers to the end of the response. The pitfall: it
+//     doesn't stop the handler, nor cancel its context. The handler runs to the end, its writes fail, and the
+//     client gets a closed connection once the handler is done, not when the timeout fires.
+//   - http.TimeoutHandler answers 503 when the handler is too slow, and cancels its context. Its late writes fail
+//     with http.ErrHandlerTimeout, "http: Handler timeout".
+//
+// IdleTimeout, for the keep-alive connections between two requests, is in Test_httpIdleTimeout.
+//
+//dojo:meta difficulty=advanced minutes=20 tags=http,security,testing requires=Test_httpClientTimeouts
+func Test_httpServerTimeouts(t *testing.T) {
+	for _, c := range []timeoutCase{
+		{
+			name:    "Server.ReadHeaderTimeout",
+			server:  func(s *http.Server) { s.ReadHeaderTimeout = time.Second },
+			handler: slowHandler,
+			call: func(_ *http.Client, ln *memnet.Listener) error {
+				return rawRequest(ln, "GET / HTTP/1.1\r\nHost: memnet\r\n") // no blank line ending the headers
+			},
+			client: "unexpected EOF",
+			after:  time.Second,
+		},
+		{
+			name:   "Server.ReadTimeout",
+			server: func(s *http.Server) { s.ReadTimeout = time.Second },
+			handler: func(w http.ResponseWriter, r *http.Request) string {
+				_, err := io.ReadAll(r.Body)
+				http.Error(w, "slow body", http.StatusRequestTimeout)
+				return "read: " + err.Error()
+			},
+			call: func(_ *http.Client, ln *memnet.Listener) error {
+				return rawRequest(ln, "POST / HTTP/1.1\r\nHost: memnet\r\nContent-Length: 10\r\n\r\nhalf ")
+			},
+			client: "408 Request Timeout",
+			seen:   "read: read pipe: i/o timeout",
+			after:  time.Second,
+		},
+		{
+			name:   "Server.WriteTimeout",
+			server: func(s *http.Server) { s.WriteTimeout = time.Second },
+			handler: func(w http.ResponseWriter, r *http.Request) string {
+				time.Sleep(3 * time.Second)
+				_, err := io.WriteString(w, "late")
+				return fmt.Sprintf("ctx: %v, write: %v, flush: %v", r.Context().Err(), err, http.NewResponseController(w).Flush())
+			},
+			client: `Get "http://memnet/": EOF`,
+			seen:   "ctx: <nil>, write: <nil>, flush: write pipe: i/o timeout",
+			after:  3 * time.Second,
+		},
+		{
+			name: "http.TimeoutHandler",
+			server: func(s *http.Server) {
+				s.Handler = http.TimeoutHandler(s.Handler, time.Second, "too slow")
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) string {
+				time.Sleep(2 * time.Second)
+				_, err := io.WriteString(w, "late")
+				return fmt.Sprintf("ctx: %v, write: %v", r.Context().Err(), err)
+			},
+			call: func(client *http.Client, _ *memnet.Listener) error {
+				resp, err := client.Get("http://memnet/")
+				if err != nil {
+					return err
+				}
+				defer resp.Body.Close()
+				b, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("%s: %s", resp.Status, b)
+			},
+			client: "503 Service Unavailable: too slow",
+			seen:   "ctx: context deadline exceeded, write: http: Handler timeout",
+			after:  time.Second,
+		},
+	} {
+		t.Run(c.name, c.run)
+	}
+}
+
+// Test_httpIdleTimeout shows Server.IdleTimeout: the server closes keep-alive connections idle for longer, and the
+// next request of the client opens a new one. Without it, ReadTimeout is used, and without both idle connections
+// stay open forever. Clients have their own Transport.IdleConnTimeout, 90 seconds for http.DefaultTransport.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,testing requires=Test_httpServerTimeouts
+func Test_httpIdleTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ln := memnet.Listen()
+		var (
+			mu    sync.Mutex
+			conns []string
+		)
+		srv := &http.Server{
+			Handler:     http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			IdleTimeout: time.Second,
+			ConnState: func(_ net.Conn, state http.ConnState) {
+				mu.Lock()
+				defer mu.Unlock()
+				conns = append(conns, state.String())
+			},
+		}
+		go srv.Serve(ln)
+		defer srv.Close()
+		tr := &http.Transport{DialContext: ln.DialContext}
+		defer tr.CloseIdleConnections()
+		client := &http.Client{Transport: tr}
+
+		for _, wait := range []time.Duration{0, 500 * time.Millisecond, 2 * time.Second} {
+			time.Sleep(wait)
+			require.NoError(t, getBody(client, ln))
+			synctest.Wait()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{
+			"new", "active", "idle", // first request
+			"active", "idle", // after 500ms, the connection is reused
+			"closed",                // after 1s idle
+			"new", "active", "idle", // after 2s, a new connection
+		}, conns)
+	})
+}
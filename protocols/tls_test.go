@@ -0,0 +1,357 @@
+package protocols
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/juan-carvajal/go-dojo/protocols/tlslab"
+)
+
+// tlsLabServer starts an httptest server presenting a certificate of ca for dojo.test and 127.0.0.1, speaking HTTP/2
+// and HTTP/1.1. Handshake errors are not logged, lessons print them from the client side.
+func tlsLabServer(ca *tlslab.CA, h http.Handler, configure func(*tls.Config)) (*httptest.Server, error) {
+	cert, err := ca.Server("dojo.test", "127.0.0.1")
+	if err != nil {
+		return nil, err
+	}
+	s := httptest.NewUnstartedServer(h)
+	s.EnableHTTP2 = true
+	s.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"h2", "http/1.1"}}
+	if configure != nil {
+		configure(s.TLS)
+	}
+	s.Config.ErrorLog = log.New(io.Discard, "", 0)
+	s.StartTLS()
+	return s, nil
+}
+
+// tlsLabClient returns a client trusting ca, configured by configure.
+func tlsLabClient(ca *tlslab.CA, configure func(*tls.Config)) *http.Client {
+	cfg := &tls.Config{RootCAs: ca.Pool()}
+	if configure != nil {
+		configure(cfg)
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, ForceAttemptHTTP2: true}}
+}
+
+// Example_tlsConnectionState shows what the TLS handshake negotiated, from r.TLS on the server side
+// (resp.TLS on the client side). The server certificate comes from a CA of the lesson, the client trusts it through
+// RootCAs: that is all httptest.Server.StartTLS and httptest.Server.Client do, with a certificate for 127.0.0.1 and
+// example.com baked in net/http/internal/testcert.
+//
+// Go negotiates TLS 1.3 by default. Its cipher suites can't be configured: Go picks AES-GCM or ChaCha20-Poly1305
+// depending on hardware AES support. TLS 1.2 suites are configurable, here forced with MaxVersion. The server name
+// indication (SNI) is the host name the client asked for, sent in clear text in the ClientHello.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,security requires=Example_http2_TLS
+func Example_tlsConnectionState() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s, ALPN %q, SNI %q", tls.VersionName(r.TLS.Version), r.TLS.NegotiatedProtocol, r.TLS.ServerName)
+		if r.TLS.Version == tls.VersionTLS12 {
+			fmt.Fprintf(w, ", %s", tls.CipherSuiteName(r.TLS.CipherSuite))
+		}
+		fmt.Fprintln(w)
+	}), nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+
+	for _, configure := range []func(*tls.Config){
+		func(c *tls.Config) { c.ServerName = "dojo.test" },
+		func(c *tls.Config) {
+			c.ServerName = "dojo.test"
+			c.MaxVersion = tls.VersionTLS12
+			c.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}
+		},
+		nil, // no SNI for IP addresses
+	} {
+		client := tlsLabClient(ca, configure)
+		printBody(client, http.MethodGet, testServer.URL)
+		client.CloseIdleConnections()
+	}
+	// Output:
+	// TLS 1.3, ALPN "h2", SNI "dojo.test"
+	// TLS 1.2, ALPN "h2", SNI "dojo.test", TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256
+	// TLS 1.3, ALPN "h2", SNI ""
+}
+
+// Example_tlsALPN shows how HTTP/2 gets chosen: in its ClientHello the client lists the application protocols it
+// speaks (ALPN), and the server picks one it speaks too. That is how a client learns a server speaks HTTP/2 without
+// an extra round trip. Without any protocol in common, the server aborts the handshake with a
+// no_application_protocol alert rather than guess.
+//
+//dojo:meta difficulty=intermediate minutes=5 tags=http,security requires=Example_tlsConnectionState
+func Example_tlsALPN() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(getRequestProtocol), nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+	fmt.Println("server:", testServer.TLS.NextProtos)
+
+	for _, protos := range [][]string{{"h2", "http/1.1"}, {"http/1.1"}, {"h3"}} {
+		conn, err := tls.Dial("tcp", testServer.Listener.Addr().String(), &tls.Config{RootCAs: ca.Pool(), NextProtos: protos})
+		if err != nil {
+			fmt.Printf("client %v: %v\n", protos, err)
+			continue
+		}
+		fmt.Printf("client %v: %q\n", protos, conn.ConnectionState().NegotiatedProtocol)
+		conn.Close()
+	}
+	// Output:
+	// server: [h2 http/1.1]
+	// client [h2 http/1.1]: "h2"
+	// client [http/1.1]: "http/1.1"
+	// client [h3]: remote error: tls: no application protocol
+}
+
+// Example_tlsSessionResumption shows TLS session resumption. After a handshake, the server sends the client a
+// session ticket. A client keeping tickets in a ClientSessionCache presents it on its next connection, and the
+// server skips the certificate exchange and its signature: r.TLS.DidResume tells when that happened.
+//
+// Clients without a cache, the default, never resume. DisableKeepAlives forces a new connection per request here.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http,security requires=Example_tlsConnectionState,Example_connReuseBody
+func Example_tlsSessionResumption() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "resumed: %t\n", r.TLS.DidResume)
+	}), nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+
+	for _, cache := range []tls.ClientSessionCache{nil, tls.NewLRUClientSessionCache(1)} {
+		fmt.Println("session cache:", cache != nil)
+		client := tlsLabClient(ca, func(c *tls.Config) { c.ClientSessionCache = cache })
+		client.Transport.(*http.Transport).DisableKeepAlives = true
+		for range 2 {
+			printBody(client, http.MethodGet, testServer.URL)
+		}
+	}
+	// Output:
+	// session cache: false
+	// resumed: false
+	// resumed: false
+	// session cache: true
+	// resumed: false
+	// resumed: true
+}
+
+// Example_tlsSNI shows a server hosting several names on one address. The client sends the name it wants in the
+// ClientHello (SNI), and GetCertificate picks the certificate to present from it. Without SNI, e.g. when connecting
+// to an IP address, the server falls back on its default certificate.
+//
+// SNI travels in clear text, so the names a client visits leak even over TLS: Encrypted Client Hello
+// (tls.Config.EncryptedClientHelloConfigList) fixes that.
+//
+//dojo:meta difficulty=intermediate minutes=10 tags=http,security requires=Example_tlsConnectionState
+func Example_tlsSNI() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	certs := map[string]*tls.Certificate{}
+	for _, name := range []string{"a.dojo.test", "b.dojo.test"} {
+		cert, err := ca.Server(name)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		certs[name] = &cert
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(getRequestProtocol), func(c *tls.Config) {
+		c.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certs[hello.ServerName], nil // nil falls back on c.Certificates
+		}
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+
+	for _, name := range []string{"a.dojo.test", "b.dojo.test", ""} {
+		conn, err := tls.Dial("tcp", testServer.Listener.Addr().String(), &tls.Config{RootCAs: ca.Pool(), ServerName: name})
+		if err != nil {
+			fmt.Printf("SNI %q: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("SNI %q: certificate for %s\n", name, conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+		conn.Close()
+	}
+	// Output:
+	// SNI "a.dojo.test": certificate for a.dojo.test
+	// SNI "b.dojo.test": certificate for b.dojo.test
+	// SNI "": certificate for dojo.test
+}
+
+// Example_mTLS shows mutual TLS: the server asks for a client certificate too, and verifies it against its own pool
+// of CAs (ClientCAs), with ClientAuth set to RequireAndVerifyClientCert. The handler finds the verified certificate
+// in r.TLS.PeerCertificates, an identity that can't be forged like a header.
+//
+// With TLS 1.3 the client finishes its side of the handshake before the server checked its certificate: the
+// rejection comes as an alert on the first read, not from the handshake. No certificate, a certificate
+// of another CA and an expired certificate each get their own alert.
+//
+// Notice the client with a certificate of another CA in tls.Config.Certificates sends no certificate at all: the
+// server lists the CAs it accepts and Go only sends a certificate issued by one of them. GetClientCertificate
+// decides alone, and sends it anyway.
+//
+//dojo:meta difficulty=advanced minutes=15 tags=http,security requires=Example_tlsConnectionState
+func Example_mTLS() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	other, err := tlslab.NewCA("other CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s\n", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}), func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		c.ClientCAs = ca.Pool()
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+
+	alice, err := ca.Client("alice")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	mallory, err := other.Client("mallory")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	bob, err := ca.Issue(&x509.Certificate{
+		Subject:     alice.Leaf.Subject,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		NotBefore:   time.Now().Add(-48 * time.Hour),
+		NotAfter:    time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	resp, err := tlsLabClient(ca, func(cfg *tls.Config) { cfg.Certificates = []tls.Certificate{alice} }).Get(testServer.URL)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("alice: %s", b)
+
+	// The rejected clients handshake by hand and read without writing a request first: a request racing with the
+	// alert may fail with a broken pipe or a reset connection instead.
+	for _, c := range []struct {
+		label string
+		certs []tls.Certificate
+		force bool // with GetClientCertificate rather than Certificates
+	}{
+		{"no certificate", nil, false},
+		{"other CA", []tls.Certificate{mallory}, false},
+		{"other CA, forced", []tls.Certificate{mallory}, true},
+		{"expired", []tls.Certificate{bob}, false},
+	} {
+		cfg := &tls.Config{RootCAs: ca.Pool(), ServerName: "dojo.test", Certificates: c.certs}
+		if c.force {
+			cfg.Certificates = nil
+			cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &c.certs[0], nil
+			}
+		}
+		conn, err := tls.Dial("tcp", testServer.Listener.Addr().String(), cfg)
+		if err != nil {
+			fmt.Printf("%s: handshake: %v\n", c.label, err)
+			continue
+		}
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+		fmt.Printf("%s: %v\n", c.label, err)
+	}
+	// Output:
+	// alice: hello alice
+	// no certificate: remote error: tls: certificate required
+	// other CA: remote error: tls: certificate required
+	// other CA, forced: remote error: tls: unknown certificate authority
+	// expired: remote error: tls: expired certificate
+}
+
+// Example_tlsKeyLog shows how to decrypt captured TLS traffic, e.g. in Wireshark: tls.Config.KeyLogWriter receives
+// the secrets of every handshake in the NSS key log format, one line per secret, `<label> <client random> <secret>`.
+// TLS 1.3 derives separate secrets for the handshake and the application data of each direction.
+//
+// tlslab.KeyLogWriter opens the file named by $SSLKEYLOGFILE, the variable browsers and curl understand too. Whoever
+// holds the file can decrypt the traffic: this is for debugging only.
+//
+//dojo:meta difficulty=advanced minutes=10 tags=http,security requires=Example_tlsConnectionState
+func Example_tlsKeyLog() {
+	ca, err := tlslab.NewCA("dojo CA")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	testServer, err := tlsLabServer(ca, http.HandlerFunc(getRequestProtocol), nil)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer testServer.Close()
+
+	var keyLog bytes.Buffer // tlslab.KeyLogWriter() to write $SSLKEYLOGFILE
+	client := tlsLabClient(ca, func(c *tls.Config) { c.KeyLogWriter = &keyLog })
+	defer client.CloseIdleConnections()
+	printBody(client, http.MethodGet, testServer.URL)
+
+	sc := bufio.NewScanner(&keyLog)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		fmt.Printf("%s <%d bytes client random> <%d bytes secret>\n", fields[0], len(fields[1])/2, len(fields[2])/2)
+	}
+	// Output:
+	// Request Protocol: HTTP/2.0
+	// CLIENT_HANDSHAKE_TRAFFIC_SECRET <32 bytes client random> <32 bytes secret>
+	// SERVER_HANDSHAKE_TRAFFIC_SECRET <32 bytes client random> <32 bytes secret>
+	// CLIENT_TRAFFIC_SECRET_0 <32 bytes client random> <32 bytes secret>
+	// SERVER_TRAFFIC_SECRET_0 <32 bytes client random> <32 bytes secret>
+}
@@ -0,0 +1,137 @@
+// Package tlslab mints certificates for TLS lessons: an in-process certificate authority issuing server and client
+// certificates, so that lessons can show what httptest.Server.StartTLS and httptest.Server.Client set up behind the
+// scenes, mutual TLS included.
+//
+// Keys are ECDSA P-256 and nothing touches the disk, except the key log of [KeyLogWriter] when asked for.
+package tlslab
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// CA is a certificate authority, trusted by whoever adds its certificate to a pool.
+type CA struct {
+	Cert *x509.Certificate
+
+	key    crypto.Signer
+	mu     sync.Mutex
+	serial int64
+}
+
+// NewCA returns a certificate authority named name, valid for a day.
+func NewCA(name string) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"go-dojo"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Cert: cert, key: key, serial: 1}, nil
+}
+
+// Pool returns a pool trusting ca, for tls.Config.RootCAs on clients or tls.Config.ClientCAs on servers.
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// Server issues a server certificate for hosts, DNS names or IP addresses. The first one is its common name.
+func (ca *CA) Server(hosts ...string) (tls.Certificate, error) {
+	if len(hosts) == 0 {
+		return tls.Certificate{}, errors.New("tlslab: Server needs at least one host")
+	}
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	return ca.Issue(tmpl)
+}
+
+// Client issues a client certificate for mutual TLS, name is its common name.
+func (ca *CA) Client(name string) (tls.Certificate, error) {
+	return ca.Issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
+
+// Issue signs a certificate from tmpl with a new key. The serial number, the key usage, and the validity when
+// NotBefore and NotAfter are zero, a day, are filled in.
+func (ca *CA) Issue(tmpl *x509.Certificate) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	ca.mu.Lock()
+	ca.serial++
+	serial := ca.serial
+	ca.mu.Unlock()
+
+	t := *tmpl
+	t.SerialNumber = big.NewInt(serial)
+	t.KeyUsage = x509.KeyUsageDigitalSignature
+	if t.NotBefore.IsZero() && t.NotAfter.IsZero() {
+		t.NotBefore = time.Now().Add(-time.Hour)
+		t.NotAfter = time.Now().Add(24 * time.Hour)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &t, ca.Cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der, ca.Cert.Raw}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// KeyLogWriter opens the file named by $SSLKEYLOGFILE for appending, for tls.Config.KeyLogWriter. It returns nil
+// when the variable is not set.
+//
+// The file gets the secrets of every handshake in the NSS key log format, which Wireshark reads (Preferences,
+// Protocols, TLS, (Pre)-Master-Secret log filename) to decrypt a capture of the traffic, e.g. from
+// `tcpdump -i lo -w dojo.pcap`. Anyone with the file can decrypt the traffic: never enable it in production.
+func KeyLogWriter() (io.WriteCloser, error) {
+	path := os.Getenv("SSLKEYLOGFILE")
+	if path == "" {
+		return nil, nil
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+}
@@ -0,0 +1,55 @@
+package tlslab
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCA(t *testing.T) {
+	ca, err := NewCA("test CA")
+	require.NoError(t, err)
+
+	server, err := ca.Server("dojo.test", "127.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dojo.test"}, server.Leaf.DNSNames)
+	require.Len(t, server.Leaf.IPAddresses, 1)
+	_, err = server.Leaf.Verify(x509.VerifyOptions{DNSName: "127.0.0.1", Roots: ca.Pool()})
+	require.NoError(t, err)
+	_, err = server.Leaf.Verify(x509.VerifyOptions{DNSName: "other.test", Roots: ca.Pool()})
+	require.Error(t, err)
+
+	_, err = ca.Server()
+	require.EqualError(t, err, "tlslab: Server needs at least one host")
+
+	client, err := ca.Client("alice")
+	require.NoError(t, err)
+	require.NotEqual(t, server.Leaf.SerialNumber, client.Leaf.SerialNumber)
+	_, err = client.Leaf.Verify(x509.VerifyOptions{Roots: ca.Pool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err)
+	_, err = client.Leaf.Verify(x509.VerifyOptions{Roots: ca.Pool()}) // server auth by default
+	require.Error(t, err)
+}
+
+func TestKeyLogWriter(t *testing.T) {
+	t.Setenv("SSLKEYLOGFILE", "")
+	w, err := KeyLogWriter()
+	require.NoError(t, err)
+	require.Nil(t, w)
+
+	path := filepath.Join(t.TempDir(), "keys.log")
+	t.Setenv("SSLKEYLOGFILE", path)
+	for range 2 {
+		w, err = KeyLogWriter()
+		require.NoError(t, err)
+		_, err = w.Write([]byte("CLIENT_RANDOM a b\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "CLIENT_RANDOM a b\nCLIENT_RANDOM a b\n", string(b))
+}
@@ -0,0 +1,171 @@
+package ws
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Opcode is the type of a frame.
+type Opcode byte
+
+// Opcodes of RFC 6455, section 5.2. Control frames, close, ping and pong, can come between the fragments of a
+// message.
+const (
+	OpContinuation Opcode = 0x0
+	OpText         Opcode = 0x1
+	OpBinary       Opcode = 0x2
+	OpClose        Opcode = 0x8
+	OpPing         Opcode = 0x9
+	OpPong         Opcode = 0xa
+)
+
+func (op Opcode) String() string {
+	switch op {
+	case OpContinuation:
+		return "continuation"
+	case OpText:
+		return "text"
+	case OpBinary:
+		return "binary"
+	case OpClose:
+		return "close"
+	case OpPing:
+		return "ping"
+	case OpPong:
+		return "pong"
+	}
+	return fmt.Sprintf("opcode(%#x)", byte(op))
+}
+
+// control reports whether op is a control frame: at most 125 bytes, never fragmented.
+func (op Opcode) control() bool {
+	return op&0x8 != 0
+}
+
+// maxControlPayload is the largest payload of a control frame.
+const maxControlPayload = 125
+
+// Frame is a WebSocket frame.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-------+-+-------------+-------------------------------+
+//	|F|R|R|R| opcode|M| Payload len |    Extended payload length    |
+//	|I|S|S|S|  (4)  |A|     (7)     |             (16/64)           |
+//	|N|V|V|V|       |S|             |   (if payload len==126/127)   |
+//	| |1|2|3|       |K|             |                               |
+//	+-+-+-+-+-------+-+-------------+ - - - - - - - - - - - - - - - +
+//	|     Extended payload length continued, if payload len == 127  |
+//	+ - - - - - - - - - - - - - - - +-------------------------------+
+//	|                               |Masking-key, if MASK set to 1  |
+//	+-------------------------------+-------------------------------+
+//	| Masking-key (continued)       |          Payload Data         |
+//	+-------------------------------- - - - - - - - - - - - - - - - +
+type Frame struct {
+	Fin     bool   // last fragment of the message
+	Opcode  Opcode // OpContinuation for the fragments after the first one
+	Masked  bool   // the payload was or is to be masked with Mask, as clients must
+	Mask    [4]byte
+	Payload []byte // unmasked
+}
+
+// ErrProtocol is returned for frames breaking RFC 6455, the connection is then closed with status 1002.
+var ErrProtocol = errors.New("ws: protocol error")
+
+// ErrTooBig is returned for frames and messages over the read limit, the connection is then closed with status 1009.
+var ErrTooBig = errors.New("ws: message too big")
+
+// WriteFrame writes f to w, masking the payload when f.Masked.
+func WriteFrame(w io.Writer, f Frame) error {
+	if f.Opcode.control() && (len(f.Payload) > maxControlPayload || !f.Fin) {
+		return fmt.Errorf("%w: %s frame fragmented or longer than %d bytes", ErrProtocol, f.Opcode, maxControlPayload)
+	}
+	buf := make([]byte, 0, 14+len(f.Payload))
+	b0 := byte(f.Opcode)
+	if f.Fin {
+		b0 |= 0x80
+	}
+	var b1 byte
+	if f.Masked {
+		b1 = 0x80
+	}
+	switch n := len(f.Payload); {
+	case n <= 125:
+		buf = append(buf, b0, b1|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, b0, b1|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, b0, b1|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if f.Masked {
+		buf = append(buf, f.Mask[:]...)
+		start := len(buf)
+		buf = append(buf, f.Payload...)
+		mask(buf[start:], f.Mask)
+	} else {
+		buf = append(buf, f.Payload...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadFrame reads a frame from r and unmasks its payload. Payloads over limit bytes are refused.
+func ReadFrame(r io.Reader, limit int64) (Frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return Frame{}, err
+	}
+	f := Frame{
+		Fin:    head[0]&0x80 != 0,
+		Opcode: Opcode(head[0] & 0x0f),
+		Masked: head[1]&0x80 != 0,
+	}
+	if head[0]&0x70 != 0 {
+		return Frame{}, fmt.Errorf("%w: reserved bits set without extension", ErrProtocol)
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if f.Opcode.control() && (n > maxControlPayload || !f.Fin) {
+		return Frame{}, fmt.Errorf("%w: %s frame fragmented or longer than %d bytes", ErrProtocol, f.Opcode, maxControlPayload)
+	}
+	if n > uint64(limit) {
+		return Frame{}, fmt.Errorf("%w: frame of %d bytes over the limit of %d", ErrTooBig, n, limit)
+	}
+	if f.Masked {
+		if _, err := io.ReadFull(r, f.Mask[:]); err != nil {
+			return Frame{}, err
+		}
+	}
+	f.Payload = make([]byte, n)
+	if _, err := io.ReadFull(r, f.Payload); err != nil {
+		return Frame{}, err
+	}
+	if f.Masked {
+		mask(f.Payload, f.Mask)
+	}
+	return f, nil
+}
+
+// mask XORs b with key in place, masking and unmasking alike.
+func mask(b []byte, key [4]byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
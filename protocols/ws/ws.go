@@ -0,0 +1,326 @@
+// Package ws is a minimal WebSocket implementation (RFC 6455), to show what libraries like gorilla/websocket or
+// coder/websocket do on the wire:
+//
+//   - The handshake: an HTTP/1.1 GET request with `Upgrade: websocket` and a random Sec-WebSocket-Key, answered with
+//     `101 Switching Protocols` and a Sec-WebSocket-Accept header derived from the key ([AcceptKey]). Then the
+//     connection carries frames in both directions, and HTTP is over.
+//   - The framing: messages are split into frames with a small header, [Frame].
+//   - The masking: clients XOR their payloads with a random key sent in each frame, so that a client can't make
+//     proxies that don't understand WebSocket see bytes of its choice, e.g. a fake HTTP request to poison a cache.
+//   - The control frames: pings are answered with pongs, and a connection ends with an exchange of close frames
+//     carrying a status code.
+//
+// It has no extensions (compression), no subprotocols, no wss:// and no WebSocket over HTTP/2 (RFC 8441).
+package ws
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Close status codes of RFC 6455, section 7.4.1.
+const (
+	CloseNormal         = 1000
+	CloseGoingAway      = 1001
+	CloseProtocolError  = 1002
+	CloseNoStatus       = 1005 // never sent, reported when a close frame has no status
+	CloseInvalidPayload = 1007
+	CloseMessageTooBig  = 1009
+)
+
+// DefaultReadLimit is the size of the largest message a Conn reads, unless its ReadLimit says otherwise.
+const DefaultReadLimit = 1 << 20
+
+// acceptGUID is appended to the key of the client before hashing it, a value only WebSocket servers know.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// AcceptKey returns the Sec-WebSocket-Accept value answering the Sec-WebSocket-Key key: the server proves it
+// understood the handshake, rather than being an HTTP server answering 101 to anything.
+func AcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// CloseError is returned by [Conn.ReadMessage] once the peer closed the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("ws: closed with status %d %q", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. One goroutine can read while another writes.
+type Conn struct {
+	OnPong    func(payload []byte) // called by ReadMessage with the payload of the pongs received
+	ReadLimit int64                // largest message read, DefaultReadLimit when zero
+
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // clients mask their frames, servers don't
+
+	wmu       sync.Mutex
+	closeSent bool
+}
+
+// Upgrade answers a WebSocket handshake and takes over the connection of r. It replies with an error status and
+// returns an error when r is not a valid handshake.
+//
+// The connection is hijacked from the server: it no longer tracks it, http.Server.Shutdown doesn't close it.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet || !headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "not a WebSocket handshake", http.StatusBadRequest)
+		return nil, errors.New("ws: not a WebSocket handshake")
+	}
+	if v := r.Header.Get("Sec-WebSocket-Version"); v != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("ws: unsupported version %q", v)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		http.Error(w, "invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("ws: invalid key %q", key)
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", AcceptKey(key))
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{}) // the server's read and write timeouts are for HTTP
+	return &Conn{conn: conn, br: brw.Reader}, nil
+}
+
+// Dial opens a WebSocket connection to the ws:// URL rawURL. The response to the handshake is returned too, with
+// an error when the server refused it.
+func Dial(ctx context.Context, rawURL string) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, nil, fmt.Errorf("ws: unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, resp, err := handshake(conn, u)
+	if err != nil {
+		conn.Close()
+		return nil, resp, err
+	}
+	conn.SetDeadline(time.Time{})
+	return c, resp, nil
+}
+
+func handshake(conn net.Conn, u *url.URL) (*Conn, *http.Response, error) {
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{Method: http.MethodGet, URL: u, Header: http.Header{}}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n", u.RequestURI(), u.Host, key)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, resp, fmt.Errorf("ws: handshake refused: %s", resp.Status)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != AcceptKey(key) {
+		return nil, resp, fmt.Errorf("ws: invalid Sec-WebSocket-Accept %q", got)
+	}
+	return &Conn{conn: conn, br: br, client: true}, resp, nil
+}
+
+// headerHas reports whether the comma separated values of the header key contain value, case insensitively.
+func headerHas(h http.Header, key, value string) bool {
+	for _, v := range h.Values(key) {
+		for t := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage reads the next text or binary message, reassembling its fragments. On the way, it answers pings with
+// pongs and passes pongs to OnPong. When the peer closes the connection, ReadMessage answers with a close frame of the
+// same status, or of none when the peer sent none, unless it already sent one with WriteClose, and returns a
+// *CloseError: the connection can then be closed.
+//
+// A protocol error closes the connection with status 1002, as does an unmasked frame from a client or a masked one
+// from a server. A message over ReadLimit closes it with status 1009.
+func (c *Conn) ReadMessage() (Opcode, []byte, error) {
+	limit := c.ReadLimit
+	if limit == 0 {
+		limit = DefaultReadLimit
+	}
+	var (
+		op  Opcode
+		msg []byte
+	)
+	for {
+		f, err := ReadFrame(c.br, limit)
+		switch {
+		case errors.Is(err, ErrProtocol):
+			return 0, nil, c.fail(CloseProtocolError, err)
+		case errors.Is(err, ErrTooBig):
+			return 0, nil, c.fail(CloseMessageTooBig, err)
+		case err != nil:
+			return 0, nil, err
+		}
+		if f.Masked == c.client {
+			return 0, nil, c.fail(CloseProtocolError, fmt.Errorf("%w: masking from the wrong side", ErrProtocol))
+		}
+		switch f.Opcode {
+		case OpPing:
+			if err := c.writeFrame(OpPong, f.Payload); err != nil && !errors.Is(err, errCloseSent) {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			if c.OnPong != nil {
+				c.OnPong(f.Payload)
+			}
+			continue
+		case OpClose:
+			if len(f.Payload) == 1 {
+				return 0, nil, c.fail(CloseProtocolError, fmt.Errorf("%w: close frame of 1 byte", ErrProtocol))
+			}
+			ce := &CloseError{Code: CloseNoStatus}
+			var reply []byte // no status is answered with no status, 1005 is never sent
+			if len(f.Payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(f.Payload))
+				ce.Reason = string(f.Payload[2:])
+				reply = f.Payload[:2]
+			}
+			if err := c.writeClose(reply); err != nil && !errors.Is(err, errCloseSent) {
+				return 0, nil, err
+			}
+			return 0, nil, ce
+		case OpText, OpBinary:
+			if op != 0 {
+				return 0, nil, c.fail(CloseProtocolError, fmt.Errorf("%w: new message before the last fragment", ErrProtocol))
+			}
+			op, msg = f.Opcode, f.Payload
+		case OpContinuation:
+			if op == 0 {
+				return 0, nil, c.fail(CloseProtocolError, fmt.Errorf("%w: continuation without a message", ErrProtocol))
+			}
+			if int64(len(msg)+len(f.Payload)) > limit {
+				return 0, nil, c.fail(CloseMessageTooBig, fmt.Errorf("%w: over the limit of %d", ErrTooBig, limit))
+			}
+			msg = append(msg, f.Payload...)
+		default:
+			return 0, nil, c.fail(CloseProtocolError, fmt.Errorf("%w: unknown %s", ErrProtocol, f.Opcode))
+		}
+		if f.Fin {
+			if op == OpText && !utf8.Valid(msg) {
+				return 0, nil, c.fail(CloseInvalidPayload, errors.New("ws: text message is not UTF-8"))
+			}
+			return op, msg, nil
+		}
+	}
+}
+
+// fail closes the connection with code after an error of the peer, it returns err.
+func (c *Conn) fail(code int, err error) error {
+	c.WriteClose(code, "")
+	c.conn.Close()
+	return err
+}
+
+// WriteMessage sends data as a single frame text or binary message.
+func (c *Conn) WriteMessage(op Opcode, data []byte) error {
+	if op != OpText && op != OpBinary {
+		return fmt.Errorf("ws: %s is not a message", op)
+	}
+	return c.writeFrame(op, data)
+}
+
+// Ping sends a ping, the peer answers with a pong carrying the same payload.
+func (c *Conn) Ping(payload []byte) error {
+	return c.writeFrame(OpPing, payload)
+}
+
+var errCloseSent = errors.New("ws: close frame already sent")
+
+// WriteClose starts the closing handshake with code and reason: the peer answers with a close frame, which
+// ReadMessage returns as a *CloseError. Nothing can be sent after it.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.writeClose(append(payload, reason...))
+}
+
+// writeClose sends a close frame with payload, empty or a status code followed by a reason.
+func (c *Conn) writeClose(payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return errCloseSent
+	}
+	c.closeSent = true
+	return c.writeFrameLocked(OpClose, payload)
+}
+
+// Close closes the underlying connection, without the closing handshake of WriteClose.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(op Opcode, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return errCloseSent
+	}
+	return c.writeFrameLocked(op, payload)
+}
+
+func (c *Conn) writeFrameLocked(op Opcode, payload []byte) error {
+	f := Frame{Fin: true, Opcode: op, Payload: payload, Masked: c.client}
+	if c.client {
+		rand.Read(f.Mask[:])
+	}
+	return WriteFrame(c.conn, f)
+}
@@ -0,0 +1,147 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// RFC 6455, section 1.3.
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestFrame(t *testing.T) {
+	// RFC 6455, section 5.7: a masked "Hello" text frame.
+	hello := []byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, Frame{Fin: true, Opcode: OpText, Masked: true,
+		Mask: [4]byte{0x37, 0xfa, 0x21, 0x3d}, Payload: []byte("Hello")}))
+	require.Equal(t, hello, buf.Bytes())
+
+	f, err := ReadFrame(bytes.NewReader(hello), DefaultReadLimit)
+	require.NoError(t, err)
+	require.Equal(t, "Hello", string(f.Payload))
+	require.True(t, f.Fin)
+
+	for _, n := range []int{125, 126, 0xffff, 0x10000} {
+		buf.Reset()
+		require.NoError(t, WriteFrame(&buf, Frame{Fin: true, Opcode: OpBinary, Payload: make([]byte, n)}))
+		f, err := ReadFrame(&buf, DefaultReadLimit)
+		require.NoError(t, err)
+		require.Len(t, f.Payload, n)
+	}
+
+	buf.Reset()
+	require.NoError(t, WriteFrame(&buf, Frame{Fin: true, Opcode: OpBinary, Payload: make([]byte, 11)}))
+	_, err = ReadFrame(&buf, 10)
+	require.ErrorIs(t, err, ErrTooBig)
+	require.ErrorIs(t, WriteFrame(&buf, Frame{Opcode: OpPing}), ErrProtocol)
+}
+
+func TestConn(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// A fragmented message, with a ping in between, then an echo of every message.
+		WriteFrame(conn.conn, Frame{Opcode: OpText, Payload: []byte("hel")})
+		conn.Ping([]byte("server"))
+		WriteFrame(conn.conn, Frame{Fin: true, Opcode: OpContinuation, Payload: []byte("lo")})
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(op, msg)
+		}
+	}))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, resp, err := Dial(ctx, "ws"+strings.TrimPrefix(s.URL, "http"))
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	op, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, OpText, op)
+	require.Equal(t, "hello", string(msg))
+
+	pong := make(chan string, 1)
+	conn.OnPong = func(p []byte) { pong <- string(p) }
+	require.NoError(t, conn.Ping([]byte("client")))
+	require.NoError(t, conn.WriteMessage(OpBinary, []byte{1, 2, 3}))
+	op, msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, OpBinary, op)
+	require.Equal(t, []byte{1, 2, 3}, msg)
+	require.Equal(t, "client", <-pong)
+
+	require.NoError(t, conn.WriteClose(CloseNormal, "bye"))
+	require.Error(t, conn.WriteMessage(OpText, []byte("late")))
+	_, _, err = conn.ReadMessage()
+	var ce *CloseError
+	require.True(t, errors.As(err, &ce))
+	require.Equal(t, CloseNormal, ce.Code)
+}
+
+// TestCloseFrame checks the close frames answering the ones of the peer: with its status, without one when it sent
+// none, since 1005 is never sent, and with 1002 when its payload is too short for a status.
+func TestCloseFrame(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		payload []byte
+		code    int
+		reply   []byte
+	}{
+		{name: "status", payload: []byte{0x03, 0xe8, 'b', 'y', 'e'}, code: CloseNormal, reply: []byte{0x03, 0xe8}},
+		{name: "no status", payload: nil, code: CloseNoStatus, reply: []byte{}},
+		{name: "1 byte", payload: []byte{0x03}, code: CloseProtocolError, reply: []byte{0x03, 0xea}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer client.Close()
+			conn := &Conn{conn: server, br: bufio.NewReader(server)}
+			read := make(chan error, 1)
+			go func() {
+				_, _, err := conn.ReadMessage()
+				read <- err
+			}()
+
+			require.NoError(t, WriteFrame(client, Frame{Fin: true, Opcode: OpClose, Masked: true, Payload: tt.payload}))
+			f, err := ReadFrame(client, DefaultReadLimit)
+			require.NoError(t, err)
+			require.Equal(t, OpClose, f.Opcode)
+			require.Equal(t, tt.reply, f.Payload)
+
+			err = <-read
+			conn.Close()
+			if tt.code == CloseProtocolError {
+				require.ErrorIs(t, err, ErrProtocol)
+				return
+			}
+			var ce *CloseError
+			require.True(t, errors.As(err, &ce))
+			require.Equal(t, tt.code, ce.Code)
+		})
+	}
+}